@@ -0,0 +1,40 @@
+package main
+
+import (
+	"math"
+
+	"github.com/insood/graphics/internal/lighting"
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+var LightSource = mymath.Vector3{X: 200, Y: 200, Z: 350}
+var White = mymath.Color3{R: 1.0, G: 1.0, B: 1.0}
+
+var DefaultMaterial = lighting.Material{
+	Ambient:   White.Multiply(0.35),
+	Diffuse:   White.Multiply(0.45),
+	Specular:  White.Multiply(0.3),
+	Shininess: 30,
+	Metallic:  0,
+	Roughness: 0.4,
+}
+
+// makeLightRigs returns the light setups cycled with the L key
+func makeLightRigs() [][]lighting.Light {
+	return [][]lighting.Light{
+		// A single white light shining from LightSource towards the origin
+		{
+			lighting.NewDirectionalLight(LightSource.Multiply(-1), White),
+		},
+		// Red and blue point lights on either side
+		{
+			lighting.NewPointLight(mymath.Vector3{X: -400, Y: 100, Z: 300}, mymath.Color3{R: 1.0, G: 0.2, B: 0.2}, 0.001, 0.000002),
+			lighting.NewPointLight(mymath.Vector3{X: 400, Y: -100, Z: 300}, mymath.Color3{R: 0.2, G: 0.3, B: 1.0}, 0.001, 0.000002),
+		},
+		// A narrow spot light from above plus a dim fill light
+		{
+			lighting.NewSpotLight(mymath.Vector3{X: 0, Y: 500, Z: 400}, mymath.Vector3{X: 0, Y: -500, Z: -400}, mymath.Color3{R: 1.0, G: 0.95, B: 0.8}, math.Pi/20, math.Pi/12),
+			lighting.NewDirectionalLight(mymath.Vector3{X: 1, Y: 0, Z: -1}, White.Multiply(0.2)),
+		},
+	}
+}
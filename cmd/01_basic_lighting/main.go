@@ -1,27 +1,34 @@
 package main
 
 import (
-	"image/color"
+	"errors"
+	"flag"
 	"log"
 	"math"
+	"path/filepath"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/insood/graphics/internal/animation"
+	"github.com/insood/graphics/internal/clock"
+	"github.com/insood/graphics/internal/headless"
+	"github.com/insood/graphics/internal/lighting"
 	mymath "github.com/insood/graphics/internal/math"
+	"github.com/insood/graphics/internal/raster"
+	"github.com/insood/graphics/internal/scenefile"
 )
 
 const (
-	screenWidth      = 640
-	screenHeight     = 640
-	perspective      = 0.002 // 1/500
-	delta            = 0.01  // Rotation speed
-	ambientMaterial  = 0.35
-	diffuseMaterial  = 0.45
-	specularMaterial = 0.3
-	shininess        = 30
+	screenWidth  = 640
+	screenHeight = 640
+	perspective  = 0.002 // 1/500
+	nearDistance = 1     // Closest distance in front of the eye that is drawn
+	meshRadius   = 250   // Size of the sphere, loaded models are scaled to match
+	spinSpeed    = 0.6   // Radians per second
+	maxLineWidth = 8     // Pixels
+	maxSamples   = 8     // Per pixel, for multisampling
 )
 
-var LightSource = mymath.Vector3{X: 200, Y: 200, Z: 350}
 var EyePosition = mymath.Vector3{X: 0, Y: 0, Z: 600}
 var OutlineColor = mymath.Color3{R: 1.0, G: 0.2, B: 0.5} // Red-ish
 var FillColor = mymath.Color3{R: 1.0, G: 1.0, B: 1.0}
@@ -35,43 +42,74 @@ const (
 	PhongVertex
 	PhongGourand
 	PhongShading
+	Textured
+	Marble
+	Wood
+	Clouds
 )
 
 type Game struct {
-	canvas           *ebiten.Image
-	triangles        []*Triangle // Original geometry
-	rotatedTriangles []*Triangle
-	currentColor     mymath.Color3
-	theta            float64
-	rotate           bool
-	cullBackFaces    bool
-	drawOutline      bool
-	drawNormals      bool
-	drawMode         int
+	canvas        *raster.Framebuffer
+	rasterizer    *raster.Rasterizer
+	depthBuffer   *raster.DepthBuffer
+	meshes        []*Mesh
+	lightRigs     [][]lighting.Light
+	lightRig      int
+	shadingModels []lighting.ShadingModel
+	shadingModel  int
+	currentColor  mymath.Color3
+	theta         float64
+	clock         *clock.Clock
+	timeline      *animation.Timeline
+	cullBackFaces bool
+	drawOutline   bool
+	drawNormals   bool
+	depthTest     bool
+	showDepth     bool
+	drawMode      int
+	smoothLines   bool
+	stroke        raster.Stroke
+	multisample   *raster.Multisample // Nil draws triangles with one sample per pixel
+	texture       *raster.Texture
+	patterns      *Patterns
+	selection     Selection
 }
 
-func NewGame() *Game {
-	// tris := makeSampleTriangle(100)
-	tris := makeSphere(250, 20)
-
-	rotatedTriangles := make([]*Triangle, len(tris))
-
-	for i := range rotatedTriangles {
-		rotatedTriangles[i] = &Triangle{}
+func NewGame(meshes []*Mesh, lightRigs [][]lighting.Light, texture *raster.Texture, gameClock *clock.Clock) *Game {
+	canvas := raster.NewFramebuffer(screenWidth, screenHeight)
+
+	game := &Game{
+		meshes:        meshes,
+		lightRigs:     lightRigs,
+		lightRig:      0,
+		shadingModels: []lighting.ShadingModel{lighting.Phong{}, lighting.BlinnPhong{}, lighting.Lambert{}, lighting.CookTorrance{}},
+		shadingModel:  0,
+		canvas:        canvas,
+		rasterizer:    raster.NewRasterizer(canvas),
+		depthBuffer:   raster.NewDepthBuffer(screenWidth, screenHeight),
+		currentColor:  mymath.Color3{},
+		theta:         0,
+		clock:         gameClock,
+		timeline:      animation.NewTimeline(),
+		cullBackFaces: true,
+		drawOutline:   true,
+		drawNormals:   false,
+		depthTest:     true,
+		showDepth:     false,
+		drawMode:      None,
+		smoothLines:   false,
+		stroke:        raster.NewStroke(1),
+		multisample:   nil,
+		texture:       texture,
+		patterns:      NewPatterns(1),
+		selection:     NoSelection,
 	}
 
-	return &Game{
-		triangles:        tris,
-		rotatedTriangles: rotatedTriangles,
-		canvas:           ebiten.NewImage(screenWidth, screenHeight),
-		currentColor:     mymath.Color3{},
-		theta:            0,
-		rotate:           false,
-		cullBackFaces:    true,
-		drawOutline:      true,
-		drawNormals:      false,
-		drawMode:         None,
-	}
+	spin := animation.NewFloatTrack().Add(0, 0, nil).Add(math.Pi*2/spinSpeed, math.Pi*2, nil)
+	spin.Mode = animation.Loop
+	animation.Bind(game.timeline, spin, func(theta float64) { game.theta = theta })
+
+	return game
 }
 
 func (g *Game) Update() error {
@@ -79,8 +117,20 @@ func (g *Game) Update() error {
 		return ebiten.Termination
 	}
 
+	if g.clock.Replayed() {
+		return ebiten.Termination
+	}
+
 	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		g.rotate = !g.rotate
+		g.clock.Paused = !g.clock.Paused
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+		g.clock.SingleStep()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		g.clock.ToggleSlowMotion()
 	}
 
 	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
@@ -95,30 +145,94 @@ func (g *Game) Update() error {
 		g.drawNormals = !g.drawNormals
 	}
 
+	if inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+		g.depthTest = !g.depthTest
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyX) {
+		g.showDepth = !g.showDepth
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		g.lightRig = (g.lightRig + 1) % len(g.lightRigs)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyB) {
+		g.shadingModel = (g.shadingModel + 1) % len(g.shadingModels)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		g.texture.Filter = (g.texture.Filter + 1) % (raster.Bilinear + 1)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		g.texture.Address = (g.texture.Address + 1) % (raster.Clamp + 1)
+	}
+
 	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
 		g.drawMode++
-		if g.drawMode > PhongShading {
+		if g.drawMode > Clouds {
 			g.drawMode = None
 		}
 	}
 
-	if g.rotate {
-		g.theta += delta
-		for g.theta > math.Pi*2 {
-			g.theta -= math.Pi * 2
+	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		g.smoothLines = !g.smoothLines
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		g.stroke.Width *= 2
+		if g.stroke.Width > maxLineWidth {
+			g.stroke.Width = 1
 		}
 	}
 
+	if inpututil.IsKeyJustPressed(ebiten.KeyJ) {
+		g.stroke.Join = (g.stroke.Join + 1) % (raster.BevelJoin + 1)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyK) {
+		g.stroke.Cap = (g.stroke.Cap + 1) % (raster.SquareCap + 1)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
+		g.cycleSamples()
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := ebiten.CursorPosition()
+		g.Select(g.Pick(mymath.Vector2{X: float64(x), Y: float64(y)}))
+	}
+
+	for range g.clock.Frame() {
+		g.timeline.Advance(g.clock.Step)
+	}
+
 	return nil
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	screen.Clear()
+	screen.WritePixels(g.Render().Pixels())
+}
+
+// Render draws the current frame into the offscreen framebuffer
+func (g *Game) Render() *raster.Framebuffer {
 	g.canvas.Clear()
+	g.depthBuffer.Clear()
+	if g.multisample != nil {
+		g.multisample.Clear()
+	}
 
 	g.RotateTriangles()
 	g.DrawTriangles()
-	screen.DrawImage(g.canvas, nil)
+
+	if g.showDepth {
+		g.depthBuffer.Visualize(g.canvas)
+	}
+
+	g.DrawSelection()
+
+	return g.canvas
 }
 
 func (g *Game) SetColor(pixel_color mymath.Color3) {
@@ -126,13 +240,24 @@ func (g *Game) SetColor(pixel_color mymath.Color3) {
 }
 
 func (g *Game) DrawTriangles() {
-	for _, t := range g.rotatedTriangles {
-		t.project()
-		g.DrawTriangle(t)
+	near := []mymath.Vector4{raster.NearPlane(EyePosition.Z - nearDistance)}
+	frustum := viewFrustum()
+
+	for _, mesh := range g.meshes {
+		if !frustum.Intersects(mesh.rotatedBounds) {
+			continue // Entirely off screen
+		}
+
+		for _, t := range mesh.rotatedTriangles {
+			for _, clipped := range t.clip(near) {
+				clipped.project()
+				g.DrawTriangle(clipped, mesh.material)
+			}
+		}
 	}
 }
 
-func (g *Game) DrawTriangle(t *Triangle) {
+func (g *Game) DrawTriangle(t *Triangle, material lighting.Material) {
 	vecA := t.pp3.Subtract(t.pp1)
 	vecB := t.pp2.Subtract(t.pp1)
 	cross := vecA.Cross(vecB)
@@ -141,7 +266,7 @@ func (g *Game) DrawTriangle(t *Triangle) {
 	}
 
 	if g.drawMode != None {
-		g.FillTriangle(t)
+		g.FillTriangle(t, material)
 	}
 
 	if g.drawOutline {
@@ -153,70 +278,121 @@ func (g *Game) DrawTriangle(t *Triangle) {
 	}
 }
 
-func (g *Game) FillTriangle(t *Triangle) {
-	minx := t.min_px()
-	maxx := t.max_px()
-	miny := t.min_py()
-	maxy := t.max_py()
+func (g *Game) FillTriangle(t *Triangle, material lighting.Material) {
+	centroid := t.centroid()
+	faceMaterial := material.Tint(t.averageVertexColor())
+	faceColor := g.PhongLighting(centroid, t.normal(), faceMaterial)
+	averageVertexColor := g.PhongLighting(centroid, t.averageVertexNormal(), faceMaterial)
+	v1Color := g.PhongLighting(t.v1.position, t.v1.normal, material.Tint(t.v1.color))
+	v2Color := g.PhongLighting(t.v2.position, t.v2.normal, material.Tint(t.v2.color))
+	v3Color := g.PhongLighting(t.v3.position, t.v3.normal, material.Tint(t.v3.color))
+
+	// 1/depth is linear in screen space, depth itself is not
+	depth := func(w raster.Weights) float64 {
+		return 1 / (w.A/t.depth1 + w.B/t.depth2 + w.C/t.depth3)
+	}
+
+	shade := func(w raster.Weights) {
+		switch g.drawMode {
+		case Flat:
+			g.SetColor(w.Color3(t.v1.color, t.v2.color, t.v3.color))
+		case Barycentric:
+			g.SetColor(mymath.Color3{R: w.C, G: w.B, B: w.A})
+		case PhongFace:
+			g.SetColor(faceColor)
+		case PhongVertex:
+			g.SetColor(averageVertexColor)
+		case PhongGourand:
+			g.SetColor(w.Color3(v1Color, v2Color, v3Color))
+		case PhongShading:
+			v := t.interpolate(w)
+			g.SetColor(g.PhongLighting(v.position, v.normal.Normalize(), material.Tint(v.color)))
+		case Textured:
+			// Screen space weights would make the texture swim across each triangle
+			v := t.interpolate(w.PerspectiveCorrect(t.depth1, t.depth2, t.depth3))
+			texel := g.texture.Sample(v.uv)
+			g.SetColor(g.PhongLighting(v.position, v.normal.Normalize(), material.Tint(v.color.Modulate(texel))))
+		case Marble, Wood, Clouds:
+			v := t.interpolate(w.PerspectiveCorrect(t.depth1, t.depth2, t.depth3))
+			surface := g.patterns.Color(g.drawMode, v.object)
+			g.SetColor(g.PhongLighting(v.position, v.normal.Normalize(), material.Tint(v.color.Modulate(surface))))
+		}
+	}
 
-	faceColor := g.PhongLighting(t.normal())
-	averageVertexColor := g.PhongLighting(t.sphericalFaceNormal())
-	v1Color := g.PhongLighting(t.p1.Normalize())
-	v2Color := g.PhongLighting(t.p2.Normalize())
-	v3Color := g.PhongLighting(t.p3.Normalize())
+	a, b, c := toScreen(t.pp1), toScreen(t.pp2), toScreen(t.pp3)
 
-	for y := maxy; y >= miny; y-- {
-		for x := minx; x <= maxx; x++ {
-			in_triangle, uv := t.baryCentricCoordinates(mymath.Vector2Int{X: x, Y: y})
+	if g.multisample != nil {
+		// Shaded once at the pixel centre, depth tested at every sample
+		g.rasterizer.FillTriangleSamples(a, b, c, g.multisample.Pattern, func(x, y int, w raster.Weights, samples []raster.Sample) {
+			shade(w)
+			pixelColor := raster.ToRGBA(g.currentColor)
 
-			if !in_triangle {
-				continue
+			written := false
+			for _, s := range samples {
+				if g.multisample.Write(x, y, s.Index, depth(s.Weights), pixelColor, g.depthTest) {
+					written = true
+				}
 			}
-
-			switch g.drawMode {
-			case Flat:
-				g.SetColor(FillColor)
-			case Barycentric:
-				g.SetColor(mymath.Color3{R: uv.X, G: uv.Y, B: 1 - uv.X - 1.*uv.Y})
-			case PhongFace:
-				g.SetColor(faceColor)
-			case PhongVertex:
-				g.SetColor(averageVertexColor)
-			case PhongGourand:
-				a := v3Color.Multiply(uv.X)
-				b := v2Color.Multiply(uv.Y)
-				c := v1Color.Multiply(1 - uv.X - uv.Y)
-
-				g.SetColor(a.Add(b).Add(c))
-			case PhongShading:
-				a := t.p1.Multiply(1 - uv.X - uv.Y)
-				b := t.p2.Multiply(uv.Y)
-				c := t.p3.Multiply(uv.X)
-				normal := a.Add(b).Add(c).Normalize()
-				g.SetColor(g.PhongLighting(normal))
+			if !written {
+				return
 			}
 
-			g.DrawPixel(x, y)
+			g.depthBuffer.Set(x, y, math.Min(g.depthBuffer.At(x, y), depth(w)))
+			g.canvas.Set(x, y, g.multisample.Resolve(x, y))
+		})
+		return
+	}
+
+	g.rasterizer.FillTriangle(a, b, c, func(x, y int, w raster.Weights) {
+		z := depth(w)
+
+		if g.depthTest {
+			if !g.depthBuffer.Test(x, y, z) {
+				return
+			}
+		} else {
+			g.depthBuffer.Set(x, y, z)
 		}
+
+		shade(w)
+		g.DrawPixel(x, y)
+	})
+}
+
+// cycleSamples steps through one sample per pixel, then 2, 4 and up to maxSamples
+func (g *Game) cycleSamples() {
+	samples := 2
+	if g.multisample != nil {
+		samples = len(g.multisample.Pattern) * 2
+	}
+
+	if samples > maxSamples {
+		g.multisample = nil
+		return
 	}
+
+	g.multisample = raster.NewMultisample(screenWidth, screenHeight, raster.SamplePattern(samples))
 }
 
 func (g *Game) DrawOutline(t *Triangle) {
 	g.SetColor(OutlineColor)
-	g.DrawLine(t.pp1, t.pp2)
-	g.DrawLine(t.pp2, t.pp3)
-	g.DrawLine(t.pp3, t.pp1)
+	g.DrawPolyline([]mymath.Vector2{t.pp1, t.pp2, t.pp3}, true)
 }
 
 func (g *Game) DrawNormal(t *Triangle) {
-	start := mymath.Vector3{
-		X: (t.p1.X + t.p2.X + t.p3.X) / 3,
-		Y: (t.p1.Y + t.p2.Y + t.p3.Y) / 3,
-		Z: (t.p1.Z + t.p2.Z + t.p3.Z) / 3,
-	}
+	start := t.centroid()
 
 	end := start.Add(t.normal().Multiply(20))
 
+	// The triangle is in front of the near plane, but its normal can still poke through it
+	near := []mymath.Vector4{raster.NearPlane(EyePosition.Z - nearDistance)}
+	t0, t1, visible := raster.ClipLineHomogeneous(homogeneous(start), homogeneous(end), near)
+	if !visible {
+		return
+	}
+	direction := end.Subtract(start)
+	start, end = start.Add(direction.Multiply(t0)), start.Add(direction.Multiply(t1))
+
 	screenStart, _ := Project(start)
 	screenEnd, _ := Project(end)
 
@@ -224,118 +400,156 @@ func (g *Game) DrawNormal(t *Triangle) {
 	g.DrawLine(screenStart, screenEnd)
 }
 
-// set the pixel using current color. 0,0 is the middle, x axis right, y going up
+// set the pixel at screen coordinates (0,0 top left) using current color
 func (g *Game) DrawPixel(x, y int) {
-	x += screenWidth / 2                    // offset by half screen
-	y = screenHeight - (y + screenHeight/2) // offset by half screen and reverse Y direction
-
-	if x < 0 || x >= screenWidth || y < 0 || y >= screenHeight {
-		return
-	}
-
-	pixelColor := color.RGBA{
-		uint8(math.Min(float64(255), math.Max(float64(0), g.currentColor.R*255))),
-		uint8(math.Min(float64(255), math.Max(float64(0), g.currentColor.G*255))),
-		uint8(math.Min(float64(255), math.Max(float64(0), g.currentColor.B*255))),
-		255,
-	}
-
-	g.canvas.Set(x, y, pixelColor)
+	g.canvas.Set(x, y, raster.ToRGBA(g.currentColor))
 }
 
+// draw a line between two projected points using the current color
 func (g *Game) DrawLine(start, end mymath.Vector2) {
-	dx := end.X - start.X
-	dy := end.Y - start.Y
-	absdx := math.Abs(dx)
-	absdy := math.Abs(dy)
+	g.DrawPolyline([]mymath.Vector2{start, end}, false)
+}
 
-	stepx := 1
-	if start.X > end.X {
-		stepx = -1
+// DrawPolyline draws lines through projected points, and back to the start if closed, with the
+// current color and stroke. Wider lines are filled outlines so their corners can be joined.
+func (g *Game) DrawPolyline(points []mymath.Vector2, closed bool) {
+	lineColor := raster.ToRGBA(g.currentColor)
+	screen := make([]mymath.Vector2, len(points))
+	for i, p := range points {
+		screen[i] = toScreen(p)
 	}
 
-	stepy := 1
-	if start.Y > end.Y {
-		stepy = -1
+	if g.stroke.Width > 1 {
+		outline := g.stroke.Outline(screen, closed)
+		if g.smoothLines {
+			g.canvas.FillPolygonSmooth(outline, raster.NonZero, lineColor)
+		} else {
+			g.canvas.FillPolygon(outline, raster.NonZero, lineColor)
+		}
+		return
 	}
 
-	errY := 0.0
-	errX := 0.0
-	x := int(start.X)
-	y := int(start.Y)
+	segments := len(screen) - 1
+	if closed {
+		segments = len(screen)
+	}
+	for i := range segments {
+		start, end := screen[i], screen[(i+1)%len(screen)]
+		if g.smoothLines {
+			g.canvas.DrawLineSmooth(start, end, lineColor)
+		} else {
+			g.canvas.DrawLine(start, end, lineColor)
+		}
+	}
+}
 
-	// Line has more rise than run
-	if max(-dy, dy) > max(-dx, dx) {
-		slope := math.Abs(float64(dx) / float64(dy))
+// PhongLighting lights a point with the active shading model and light rig, as seen from the eye
+func (g *Game) PhongLighting(position, normal mymath.Vector3, material lighting.Material) mymath.Color3 {
+	return g.shadingModels[g.shadingModel].Shade(position, normal, EyePosition, material, g.lightRigs[g.lightRig])
+}
 
-		for ystep := 0; ystep < int(absdy); ystep++ {
-			g.DrawPixel(x, y)
-			y += stepy
-			errX += slope
-			if errX > 0.5 {
-				errX -= 1
-				x += stepx
-			}
-		}
-	} else {
-		slope := math.Abs(float64(dy) / float64(dx))
-		for xstep := 0; xstep < int(absdx); xstep++ {
-			g.DrawPixel(x, y)
-			x += stepx
-			errY += slope
-			if errY > 0.5 {
-				errY -= 1
-				y += stepy
-			}
-		}
+func (g *Game) RotateTriangles() {
+	for _, mesh := range g.meshes {
+		mesh.rotate(g.theta)
 	}
 }
 
-func (g *Game) PhongLighting(normal mymath.Vector3) mymath.Color3 {
-	face_color := mymath.Color3{R: 0.0, G: 0.0, B: 0.0}
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
+}
 
-	ambient := FillColor.Multiply(ambientMaterial)
-	face_color = face_color.Add(ambient)
+func main() {
+	headlessMode := flag.Bool("headless", false, "render frames offscreen without opening a window")
+	frames := flag.Int("frames", 1, "number of frames to render in headless mode")
+	outDir := flag.String("out", "frames", "directory to write headless frames to")
+	model := flag.String("model", "", "Wavefront OBJ file to render instead of the scene's meshes")
+	texturePath := flag.String("texture", "", "PNG or JPEG image for the textured draw mode")
+	scenePath := flag.String("scene", "", "JSON scene file with meshes, materials, lights and the eye position")
+	savePath := flag.String("save-scene", "", "write the scene to a JSON file and exit")
+	recordPath := flag.String("record", "", "write how many ticks each frame ran to a file, to play back with -replay")
+	replayPath := flag.String("replay", "", "run the ticks recorded with -record rather than following the clock")
+	flag.Parse()
+
+	texture, err := makeGlobeTexture(512, 256)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	light_normal := LightSource.Normalize()
-	diffuse_component := normal.Dot(light_normal)
+	if *texturePath != "" {
+		if texture, err = raster.LoadTexture(*texturePath); err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	diffuse := FillColor.Multiply(diffuse_component * diffuseMaterial)
-	face_color = face_color.Add(diffuse)
+	desc := defaultScene()
 
-	reflection := normal.Multiply(2).Multiply(LightSource.Dot(normal)).Subtract(LightSource)
-	specular_component := reflection.Normalize().Dot(EyePosition.Normalize())
+	if *scenePath != "" {
+		if desc, err = scenefile.Load(*scenePath); err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	specular := FillColor.Multiply(specularMaterial * math.Pow(specular_component, shininess))
-	face_color = face_color.Add(specular)
+	if *model != "" {
+		// Relative to the working directory rather than the scene file
+		path, err := filepath.Abs(*model)
+		if err != nil {
+			log.Fatal(err)
+		}
+		desc.Meshes = []scenefile.Mesh{{Model: path, Radius: meshRadius}}
+	}
 
-	return face_color
-}
+	if *savePath != "" {
+		if err := scenefile.Save(*savePath, desc); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-func (g *Game) RotateTriangles() {
-	for i, original_tri := range g.triangles {
-		rotated_tri := g.rotatedTriangles[i]
-		rotated_tri.p1 = original_tri.p1 // Copy by value
-		rotated_tri.p2 = original_tri.p2
-		rotated_tri.p3 = original_tri.p3
+	if c := desc.Camera; c != nil && c.Position != nil {
+		EyePosition = c.Position.Vec()
+	}
+
+	meshes, lightRigs, err := loadScene(desc)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		Rotate(&rotated_tri.p1, g.theta) // Rotate in place
-		Rotate(&rotated_tri.p2, g.theta)
-		Rotate(&rotated_tri.p3, g.theta)
+	source := clock.RealTime()
+	if *headlessMode {
+		source = clock.FixedTime(clock.DefaultStep)
 	}
-}
+	gameClock := clock.NewClock(clock.DefaultStep, source)
+	gameClock.Paused = true // The meshes start still until Space is pressed
 
-func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return screenWidth, screenHeight
-}
+	if *replayPath != "" {
+		recording, err := clock.LoadRecording(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		gameClock.Replay(recording)
+	}
 
-func main() {
-	ebiten.SetWindowSize(screenWidth, screenHeight)
-	ebiten.SetWindowTitle("Basic Lighting")
+	var recording *clock.Recording
+	if *recordPath != "" {
+		recording = gameClock.Record()
+	}
 
-	game := NewGame()
+	game := NewGame(meshes, lightRigs, texture, gameClock)
 
-	if err := ebiten.RunGame(game); err != nil {
+	if *headlessMode {
+		err = headless.Run(game, *frames, *outDir)
+	} else {
+		ebiten.SetWindowSize(screenWidth, screenHeight)
+		ebiten.SetWindowTitle("Basic Lighting")
+		err = ebiten.RunGame(game)
+	}
+	if err != nil && !errors.Is(err, ebiten.Termination) { // A replay ending stops headless runs early
 		log.Fatal(err)
 	}
+
+	if recording != nil {
+		if err := recording.Save(*recordPath); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
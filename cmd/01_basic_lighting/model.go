@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/insood/graphics/internal/mesh"
+)
+
+// loadModel reads an OBJ file, centers it on the origin and scales it so that it fits in a
+// sphere of the given radius
+func loadModel(path string, radius float64) ([]*Triangle, error) {
+	m, err := mesh.LoadOBJ(path)
+	if err != nil {
+		return nil, err
+	}
+
+	faces := m.Faces()
+	if len(faces) == 0 {
+		return nil, fmt.Errorf("%s: no faces", path)
+	}
+
+	lo, hi := m.Bounds()
+	center := lo.Add(hi).Multiply(0.5)
+
+	extent := 0.0
+	for _, face := range faces {
+		for _, v := range face {
+			extent = max(extent, v.Position.Subtract(center).Magnitude())
+		}
+	}
+
+	scale := 1.0
+	if extent > 0 {
+		scale = radius / extent
+	}
+
+	vertex := func(v mesh.Vertex) Vertex {
+		vertexColor := FillColor
+		if v.HasColor {
+			vertexColor = v.Color
+		}
+
+		position := v.Position.Subtract(center).Multiply(scale)
+
+		return Vertex{
+			position: position,
+			object:   position,
+			normal:   v.Normal,
+			color:    vertexColor,
+			uv:       v.UV,
+		}
+	}
+
+	// OBJ faces wind counter-clockwise, triangles here wind clockwise when seen from the front
+	tris := make([]*Triangle, len(faces))
+	for i, face := range faces {
+		tris[i] = &Triangle{
+			v1: vertex(face[0]),
+			v2: vertex(face[2]),
+			v3: vertex(face[1]),
+		}
+	}
+
+	// Normals missing from the file are zero and get generated
+	smoothNormals(tris)
+
+	return tris, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"math"
+
+	mymath "github.com/insood/graphics/internal/math"
+	"github.com/insood/graphics/internal/noise"
+)
+
+const patternScale = 0.01 // Object space units to noise lattice units
+
+var MarbleColor = mymath.Color3{R: 0.95, G: 0.93, B: 0.88}
+var VeinColor = mymath.Color3{R: 0.25, G: 0.22, B: 0.3}
+var LightWoodColor = mymath.Color3{R: 0.85, G: 0.62, B: 0.35}
+var DarkWoodColor = mymath.Color3{R: 0.45, G: 0.25, B: 0.1}
+var SkyColor = mymath.Color3{R: 0.25, G: 0.5, B: 0.9}
+var CloudColor = mymath.Color3{R: 1.0, G: 1.0, B: 1.0}
+
+// Patterns are solid textures: colors computed from a 3D object space position, so they
+// wrap around any mesh without texture coordinates
+type Patterns struct {
+	perlin  *noise.Perlin
+	simplex *noise.Simplex
+}
+
+func NewPatterns(seed int64) *Patterns {
+	return &Patterns{
+		perlin:  noise.NewPerlin(seed),
+		simplex: noise.NewSimplex(seed),
+	}
+}
+
+func (p *Patterns) Color(drawMode int, position mymath.Vector3) mymath.Color3 {
+	position = position.Multiply(patternScale)
+
+	switch drawMode {
+	case Marble:
+		return p.Marble(position)
+	case Wood:
+		return p.Wood(position)
+	case Clouds:
+		return p.Clouds(position)
+	}
+
+	return FillColor
+}
+
+// Marble bends stripes along x with turbulence and sharpens them into thin veins
+func (p *Patterns) Marble(position mymath.Vector3) mymath.Color3 {
+	turbulence := noise.Turbulence3(p.perlin, position.X, position.Y, position.Z, 6, 2, 0.5)
+	stripe := math.Sin((position.X + 2*turbulence) * math.Pi)
+	vein := math.Pow(1-math.Abs(stripe), 4)
+
+	return mix(MarbleColor, VeinColor, vein)
+}
+
+// Wood is concentric rings around the y axis, wobbled by low frequency noise
+func (p *Patterns) Wood(position mymath.Vector3) mymath.Color3 {
+	wobble := p.perlin.Noise3(position.X*0.5, position.Y*2, position.Z*0.5)
+	distance := math.Sqrt(position.X*position.X+position.Z*position.Z) * 4
+	ring := distance + wobble*2
+	ring -= math.Floor(ring)
+
+	return mix(LightWoodColor, DarkWoodColor, math.Pow(ring, 3))
+}
+
+// Clouds is fractal noise thresholded so that patches of sky show through
+func (p *Patterns) Clouds(position mymath.Vector3) mymath.Color3 {
+	density := noise.FBM3(p.simplex, position.X, position.Y, position.Z, 6, 2, 0.5)
+	coverage := math.Min(1, math.Max(0, (density+0.1)*2.5))
+
+	return mix(SkyColor, CloudColor, coverage)
+}
+
+func mix(a, b mymath.Color3, t float64) mymath.Color3 {
+	return a.Multiply(1 - t).Add(b.Multiply(t))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math"
+
+	mymath "github.com/insood/graphics/internal/math"
+	"github.com/insood/graphics/internal/raster"
+)
+
+var HighlightColor = mymath.Color3{R: 1.0, G: 1.0, B: 0.0}
+
+// Selection is a triangle picked with the mouse, as an index into its mesh
+type Selection struct {
+	mesh     *Mesh
+	triangle int
+}
+
+var NoSelection = Selection{triangle: -1}
+
+func (s Selection) Empty() bool {
+	return s.mesh == nil
+}
+
+// Triangle returns the selected triangle as it was last drawn
+func (s Selection) Triangle() *Triangle {
+	if s.Empty() {
+		return nil
+	}
+	return s.mesh.rotatedTriangles[s.triangle]
+}
+
+// Pick finds the nearest visible triangle under a screen position, as it was last drawn
+func (g *Game) Pick(screen mymath.Vector2) Selection {
+	ray := Unproject(screen)
+	nearest := NoSelection
+	nearestDepth := math.Inf(1)
+
+	for _, mesh := range g.meshes {
+		if _, hit := ray.IntersectSphere(mesh.rotatedBounds); !hit {
+			continue
+		}
+
+		for i, t := range mesh.rotatedTriangles {
+			if g.cullBackFaces && t.normal().Dot(ray.Direction) > 0 {
+				continue // Back faces are not drawn, so they can't be clicked
+			}
+
+			depth, hit := ray.IntersectTriangle(t.v1.position, t.v2.position, t.v3.position)
+			if hit && depth >= nearDistance && depth < nearestDepth {
+				nearest = Selection{mesh: mesh, triangle: i}
+				nearestDepth = depth
+			}
+		}
+	}
+
+	return nearest
+}
+
+func (g *Game) Select(s Selection) {
+	g.selection = s
+}
+
+// Selected is the triangle last picked, for tools that act on it
+func (g *Game) Selected() Selection {
+	return g.selection
+}
+
+// DrawSelection outlines the selected triangle on top of everything else
+func (g *Game) DrawSelection() {
+	t := g.selection.Triangle()
+	if t == nil {
+		return
+	}
+
+	near := []mymath.Vector4{raster.NearPlane(EyePosition.Z - nearDistance)}
+
+	for _, clipped := range t.clip(near) {
+		clipped.project()
+		g.SetColor(HighlightColor)
+		g.DrawPolyline([]mymath.Vector2{clipped.pp1, clipped.pp2, clipped.pp3}, true)
+	}
+}
@@ -2,34 +2,60 @@ package main
 
 import (
 	"errors"
-	"math"
 
 	mymath "github.com/insood/graphics/internal/math"
 )
 
+// Project puts p on the projection plane of an eye at EyePosition looking down -z
 func Project(p mymath.Vector3) (mymath.Vector2, error) {
-	adjZ := p.Z - EyePosition.Z // RHS, z negative into screen; relative to eye.Z
-	if adjZ > 0 {
+	view := p.Subtract(EyePosition) // RHS, z negative into screen
+	if view.Z > 0 {
 		return mymath.Vector2{}, errors.New("point is behind the camera")
 	}
-	adjZ *= -1 // Absolute z value for division
+	adjZ := -view.Z // Absolute z value for division
 
-	return mymath.Vector2{X: p.X / (adjZ * perspective), Y: p.Y / (adjZ * perspective)}, nil
+	return mymath.Vector2{X: view.X / (adjZ * perspective), Y: view.Y / (adjZ * perspective)}, nil
 }
 
-func Rotate(v *mymath.Vector3, theta float64) {
-	rx := v.X*math.Cos(theta) - v.Z*math.Sin(theta)
-	rz := v.X*math.Sin(theta) + v.Z*math.Cos(theta)
-	v.X = rx
-	v.Z = rz
-
-	rx = v.X*math.Cos(theta) - v.Y*math.Sin(theta)
-	ry := v.X*math.Sin(theta) + v.Y*math.Cos(theta)
-	v.X = rx
-	v.Y = ry
-
-	ry = v.Y*math.Cos(theta) - v.Z*math.Sin(theta)
-	rz = v.Y*math.Sin(theta) + v.Z*math.Cos(theta)
-	v.Y = ry
-	v.Z = rz
+// Depth is the distance of p in front of the eye along the viewing axis
+func Depth(p mymath.Vector3) float64 {
+	return EyePosition.Z - p.Z
+}
+
+// Unproject turns a screen position into the ray from the eye through it. Distances along the
+// ray are depths, as returned by Depth.
+func Unproject(screen mymath.Vector2) mymath.Ray {
+	x := screen.X - screenWidth/2
+	y := screenHeight/2 - screen.Y
+
+	return mymath.Ray{
+		Origin:    EyePosition,
+		Direction: mymath.Vector3{X: x * perspective, Y: y * perspective, Z: -1},
+	}
+}
+
+// viewFrustum bounds everything Project can put on the screen, in world space
+func viewFrustum() mymath.ViewFrustum {
+	eye := EyePosition
+	halfWidth := screenWidth / 2 * perspective // Visible x per unit of depth
+	halfHeight := screenHeight / 2 * perspective
+
+	return mymath.ViewFrustum{
+		mymath.NewPlane(mymath.Vector3{X: 1, Z: -halfWidth}, eye),   // left
+		mymath.NewPlane(mymath.Vector3{X: -1, Z: -halfWidth}, eye),  // right
+		mymath.NewPlane(mymath.Vector3{Y: 1, Z: -halfHeight}, eye),  // bottom
+		mymath.NewPlane(mymath.Vector3{Y: -1, Z: -halfHeight}, eye), // top
+		mymath.NewPlane(mymath.Vector3{Z: -1}, mymath.Vector3{Z: EyePosition.Z - nearDistance}),
+	}
+}
+
+// toScreen converts projected coordinates (0,0 is the middle, x axis right, y going up)
+// to screen coordinates (0,0 top left, y going down)
+func toScreen(p mymath.Vector2) mymath.Vector2 {
+	return mymath.Vector2{X: p.X + screenWidth/2, Y: screenHeight/2 - p.Y}
+}
+
+// rotation turns by -theta about y, then theta about z, then theta about x
+func rotation(theta float64) mymath.Mat4 {
+	return mymath.RotateX(theta).Mul(mymath.RotateZ(theta)).Mul(mymath.RotateY(-theta))
 }
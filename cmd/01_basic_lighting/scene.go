@@ -0,0 +1,70 @@
+package main
+
+import (
+	"github.com/insood/graphics/internal/lighting"
+	"github.com/insood/graphics/internal/scenefile"
+)
+
+// defaultScene is the sphere and light rigs drawn when no scene file is given
+func defaultScene() *scenefile.Scene {
+	eye := scenefile.FromVector3(EyePosition)
+
+	desc := &scenefile.Scene{
+		Camera:    &scenefile.Camera{Position: &eye},
+		Materials: map[string]scenefile.Material{"default": scenefile.FromMaterial(DefaultMaterial)},
+		Meshes:    []scenefile.Mesh{{Name: "sphere", Shape: "sphere", Radius: meshRadius, Divisions: 20, Material: "default"}},
+	}
+
+	for _, rig := range makeLightRigs() {
+		lights := []scenefile.Light{}
+		for _, l := range rig {
+			lights = append(lights, scenefile.FromLight(l))
+		}
+		desc.LightRigs = append(desc.LightRigs, lights)
+	}
+
+	return desc
+}
+
+// loadScene builds the meshes and light rigs of a scene description. Meshes without a material
+// use DefaultMaterial, and a scene without lights gets the built in rigs.
+func loadScene(desc *scenefile.Scene) ([]*Mesh, [][]lighting.Light, error) {
+	meshes := []*Mesh{}
+
+	for _, m := range desc.Meshes {
+		material := DefaultMaterial
+		if m.Material != "" {
+			material = desc.Materials[m.Material].Lighting()
+		}
+
+		var tris []*Triangle
+		switch {
+		case m.Model != "":
+			var err error
+			if tris, err = loadModel(desc.Resolve(m.Model), m.Radius); err != nil {
+				return nil, nil, err
+			}
+		case m.Shape == "sphere":
+			tris = makeSphere(int(m.Radius), m.Divisions)
+		case m.Shape == "triangle":
+			tris = makeSampleTriangle(int(m.Radius))
+		}
+
+		meshes = append(meshes, newMesh(tris, material))
+	}
+
+	rigs := [][]lighting.Light{}
+	for _, rig := range desc.LightRigs {
+		lights := []lighting.Light{}
+		for _, l := range rig {
+			lights = append(lights, l.Lighting())
+		}
+		rigs = append(rigs, lights)
+	}
+
+	if len(rigs) == 0 {
+		rigs = makeLightRigs()
+	}
+
+	return meshes, rigs, nil
+}
@@ -3,81 +3,207 @@ package main
 import (
 	"math"
 
+	"github.com/insood/graphics/internal/lighting"
 	mymath "github.com/insood/graphics/internal/math"
+	"github.com/insood/graphics/internal/raster"
 )
 
+// Mesh is a set of triangles drawn with one material
+type Mesh struct {
+	triangles        []*Triangle // Original geometry
+	rotatedTriangles []*Triangle
+	material         lighting.Material
+	bounds           mymath.Sphere // Around the original geometry
+	rotatedBounds    mymath.Sphere
+}
+
+func newMesh(tris []*Triangle, material lighting.Material) *Mesh {
+	rotatedTriangles := make([]*Triangle, len(tris))
+
+	for i := range rotatedTriangles {
+		rotatedTriangles[i] = &Triangle{}
+	}
+
+	return &Mesh{
+		triangles:        tris,
+		rotatedTriangles: rotatedTriangles,
+		material:         material,
+		bounds:           mymath.SphereFromPoints(trianglePoints(tris)),
+	}
+}
+
+func (m *Mesh) rotate(theta float64) {
+	rotate := rotation(theta)
+
+	for i, original_tri := range m.triangles {
+		rotated_tri := m.rotatedTriangles[i]
+		rotated_tri.v1 = original_tri.v1 // Copy by value
+		rotated_tri.v2 = original_tri.v2
+		rotated_tri.v3 = original_tri.v3
+
+		for _, v := range []*Vertex{&rotated_tri.v1, &rotated_tri.v2, &rotated_tri.v3} {
+			v.position = rotate.TransformPoint(v.position)
+			v.normal = rotate.TransformDirection(v.normal)
+		}
+	}
+
+	m.rotatedBounds = m.bounds.Transform(rotate)
+}
+
+// trianglePoints lists every vertex position, for fitting bounding volumes
+func trianglePoints(tris []*Triangle) []mymath.Vector3 {
+	points := make([]mymath.Vector3, 0, len(tris)*3)
+	for _, t := range tris {
+		points = append(points, t.v1.position, t.v2.position, t.v3.position)
+	}
+	return points
+}
+
+// Vertex holds a position and every attribute that is interpolated across a triangle
+type Vertex struct {
+	position mymath.Vector3
+	object   mymath.Vector3 // position before any rotation, for procedural patterns
+	normal   mymath.Vector3
+	color    mymath.Color3
+	uv       mymath.Vector2
+}
+
 type Triangle struct {
-	p1 mymath.Vector3
-	p2 mymath.Vector3
-	p3 mymath.Vector3
+	v1 Vertex
+	v2 Vertex
+	v3 Vertex
 
 	// projected data. On the screen raster
 	pp1 mymath.Vector2
 	pp2 mymath.Vector2
 	pp3 mymath.Vector2
+
+	// distance from the eye of each vertex, for depth testing
+	depth1 float64
+	depth2 float64
+	depth3 float64
 }
 
+// newTriangle makes a flat shaded triangle: every vertex normal is the face normal
 func newTriangle(p1, p2, p3 mymath.Vector3) *Triangle {
-	return &Triangle{p1, p2, p3, mymath.Vector2{}, mymath.Vector2{}, mymath.Vector2{}}
+	t := &Triangle{
+		v1: Vertex{position: p1, object: p1, color: FillColor},
+		v2: Vertex{position: p2, object: p2, color: FillColor},
+		v3: Vertex{position: p3, object: p3, color: FillColor},
+	}
+
+	normal := t.normal()
+	t.v1.normal = normal
+	t.v2.normal = normal
+	t.v3.normal = normal
+
+	return t
+}
+
+func newTexturedTriangle(p1, p2, p3 mymath.Vector3, uv1, uv2, uv3 mymath.Vector2) *Triangle {
+	t := newTriangle(p1, p2, p3)
+	t.v1.uv = uv1
+	t.v2.uv = uv2
+	t.v3.uv = uv3
+	return t
 }
 
 func (t *Triangle) project() {
-	t.pp1, _ = Project(t.p1)
-	t.pp2, _ = Project(t.p2)
-	t.pp3, _ = Project(t.p3)
+	t.pp1, _ = Project(t.v1.position)
+	t.pp2, _ = Project(t.v2.position)
+	t.pp3, _ = Project(t.v3.position)
+
+	t.depth1 = Depth(t.v1.position)
+	t.depth2 = Depth(t.v2.position)
+	t.depth3 = Depth(t.v3.position)
+}
+
+// interpolate blends all vertex attributes. Normals are not renormalized.
+func (t *Triangle) interpolate(w raster.Weights) Vertex {
+	return Vertex{
+		position: w.Vector3(t.v1.position, t.v2.position, t.v3.position),
+		object:   w.Vector3(t.v1.object, t.v2.object, t.v3.object),
+		normal:   w.Vector3(t.v1.normal, t.v2.normal, t.v3.normal),
+		color:    w.Color3(t.v1.color, t.v2.color, t.v3.color),
+		uv:       w.Vector2(t.v1.uv, t.v2.uv, t.v3.uv),
+	}
 }
 
-func (t *Triangle) min_px() int {
-	return int(math.Min(t.pp1.X, math.Min(t.pp2.X, t.pp3.X)))
+// clip the triangle against homogeneous planes, returning the pieces that remain. Triangles
+// that are entirely inside are returned as is.
+func (t *Triangle) clip(planes []mymath.Vector4) []*Triangle {
+	pieces, clipped := raster.ClipTriangle(
+		homogeneous(t.v1.position),
+		homogeneous(t.v2.position),
+		homogeneous(t.v3.position),
+		planes,
+	)
+	if !clipped {
+		return []*Triangle{t}
+	}
+
+	tris := make([]*Triangle, len(pieces))
+	for i, piece := range pieces {
+		tris[i] = &Triangle{
+			v1: t.interpolate(piece[0]),
+			v2: t.interpolate(piece[1]),
+			v3: t.interpolate(piece[2]),
+		}
+	}
+
+	return tris
 }
 
-func (t *Triangle) max_px() int {
-	return int(math.Max(t.pp1.X, math.Max(t.pp2.X, t.pp3.X)))
+func homogeneous(p mymath.Vector3) mymath.Vector4 {
+	return mymath.Vector4{X: p.X, Y: p.Y, Z: p.Z, W: 1}
 }
 
-func (t *Triangle) min_py() int {
-	return int(math.Min(t.pp1.Y, math.Min(t.pp2.Y, t.pp3.Y)))
+func (t *Triangle) centroid() mymath.Vector3 {
+	return t.v1.position.Add(t.v2.position).Add(t.v3.position).Multiply(1.0 / 3)
 }
 
-func (t *Triangle) max_py() int {
-	return int(math.Max(t.pp1.Y, math.Max(t.pp2.Y, t.pp3.Y)))
+// unnormalized face normal, its length is twice the triangle area
+func (t *Triangle) crossProduct() mymath.Vector3 {
+	v1 := t.v2.position.Subtract(t.v1.position)
+	v2 := t.v3.position.Subtract(t.v1.position)
+	return v2.Cross(v1)
 }
 
 func (t *Triangle) normal() mymath.Vector3 {
-	v1 := t.p2.Subtract(t.p1)
-	v2 := t.p3.Subtract(t.p1)
-	return v2.Cross(v1).Normalize()
-}
-
-// [!] This assumes that the triangle belongs to a sphere and that the points p1,p2,p3
-//
-//	are points on the sphere and are effectively the normal
-func (t *Triangle) sphericalFaceNormal() mymath.Vector3 {
-	average_face_normal := mymath.Vector3{
-		X: (t.p1.X + t.p2.X + t.p3.X) / 3,
-		Y: (t.p1.Y + t.p2.Y + t.p3.Y) / 3,
-		Z: (t.p1.Z + t.p2.Z + t.p3.Z) / 3,
-	}
+	return t.crossProduct().Normalize()
+}
+
+func (t *Triangle) averageVertexNormal() mymath.Vector3 {
+	return t.v1.normal.Add(t.v2.normal).Add(t.v3.normal).Normalize()
+}
 
-	return average_face_normal.Normalize()
+func (t *Triangle) averageVertexColor() mymath.Color3 {
+	return t.v1.color.Add(t.v2.color).Add(t.v3.color).Multiply(1.0 / 3)
 }
 
-func (t *Triangle) baryCentricCoordinates(p mymath.Vector2Int) (bool, mymath.Vector2) {
-	v0 := t.pp3.Round().Subtract(t.pp1.Round())
-	v1 := t.pp2.Round().Subtract(t.pp1.Round())
-	v2 := p.Subtract(t.pp1.Round())
+// smoothNormals fills in missing (zero) vertex normals by averaging the normals of every face
+// that shares the vertex position. Faces are weighted by their area.
+func smoothNormals(tris []*Triangle) {
+	sums := map[mymath.Vector3]mymath.Vector3{}
 
-	dot00 := v0.Dot(v0)
-	dot01 := v0.Dot(v1)
-	dot02 := v0.Dot(v2)
-	dot11 := v1.Dot(v1)
-	dot12 := v1.Dot(v2)
+	for _, t := range tris {
+		face := t.crossProduct()
+		for _, v := range []*Vertex{&t.v1, &t.v2, &t.v3} {
+			sums[v.position] = sums[v.position].Add(face)
+		}
+	}
 
-	invDenom := float64(1) / float64(dot00*dot11-dot01*dot01)
-	u := float64(dot11*dot02-dot01*dot12) * invDenom
-	v := float64(dot00*dot12-dot01*dot02) * invDenom
+	for _, t := range tris {
+		for _, v := range []*Vertex{&t.v1, &t.v2, &t.v3} {
+			if v.normal != (mymath.Vector3{}) {
+				continue
+			}
 
-	return u >= 0 && v >= 0 && u+v <= 1, mymath.Vector2{X: u, Y: v}
+			if sum := sums[v.position]; sum != (mymath.Vector3{}) {
+				v.normal = sum.Normalize()
+			}
+		}
+	}
 }
 
 func makeSampleTriangle(size int) []*Triangle {
@@ -118,17 +244,30 @@ func makeSphere(radius int, divisions int) []*Triangle {
 			pt3 := mymath.Vector3{X: x3, Y: y34, Z: z3}
 			pt4 := mymath.Vector3{X: x4, Y: y34, Z: z4}
 
+			// Texture coordinates: u goes around the equator, v from the south to the north pole
+			uv1 := mymath.Vector2{X: theta1 / (2 * math.Pi), Y: 1 - phi1/math.Pi}
+			uv2 := mymath.Vector2{X: theta2 / (2 * math.Pi), Y: 1 - phi1/math.Pi}
+			uv3 := mymath.Vector2{X: theta2 / (2 * math.Pi), Y: 1 - phi2/math.Pi}
+			uv4 := mymath.Vector2{X: theta1 / (2 * math.Pi), Y: 1 - phi2/math.Pi}
+
 			switch phi_step {
 			case 0: // Top
-				tris = append(tris, newTriangle(pt1, pt3, pt4))
+				tris = append(tris, newTexturedTriangle(pt1, pt3, pt4, uv1, uv3, uv4))
 			case divisions - 1: // Bottom
-				tris = append(tris, newTriangle(pt1, pt2, pt4))
+				tris = append(tris, newTexturedTriangle(pt1, pt2, pt4, uv1, uv2, uv4))
 			default:
-				tris = append(tris, newTriangle(pt1, pt2, pt3))
-				tris = append(tris, newTriangle(pt1, pt3, pt4))
+				tris = append(tris, newTexturedTriangle(pt1, pt2, pt3, uv1, uv2, uv3))
+				tris = append(tris, newTexturedTriangle(pt1, pt3, pt4, uv1, uv3, uv4))
 			}
 		}
 	}
 
+	// Every point on a sphere centered at the origin is its own normal
+	for _, t := range tris {
+		t.v1.normal = t.v1.position.Normalize()
+		t.v2.normal = t.v2.position.Normalize()
+		t.v3.normal = t.v3.position.Normalize()
+	}
+
 	return tris
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"math"
+
+	mymath "github.com/insood/graphics/internal/math"
+	"github.com/insood/graphics/internal/raster"
+)
+
+var OceanColor = mymath.Color3{R: 0.1, G: 0.3, B: 0.7}
+var GridColor = mymath.Color3{R: 1.0, G: 1.0, B: 1.0}
+var PoleColor = mymath.Color3{R: 0.9, G: 0.2, B: 0.2}
+
+// makeGlobeTexture draws an equirectangular map with a line every 15 degrees of latitude and
+// longitude, alternating shades between lines and red polar caps, so that distortion and
+// filtering are easy to see without an image file
+func makeGlobeTexture(width, height int) (*raster.Texture, error) {
+	texture, err := raster.NewTexture(width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	for y := range height {
+		latitude := 90 - 180*(float64(y)+0.5)/float64(height)
+
+		for x := range width {
+			longitude := 360 * (float64(x) + 0.5) / float64(width)
+
+			c := OceanColor
+			if (int(longitude/15)+int(math.Floor(latitude/15)))%2 == 0 {
+				c = c.Multiply(0.8)
+			}
+
+			if math.Abs(latitude) > 75 {
+				c = PoleColor
+			}
+
+			onLongitude := math.Mod(longitude, 15) < 360.0/float64(width)
+			onLatitude := math.Abs(math.Mod(latitude+90, 15)) < 180.0/float64(height)
+			if onLongitude || onLatitude {
+				c = GridColor
+			}
+
+			texture.Set(x, y, c)
+		}
+	}
+
+	return texture, nil
+}
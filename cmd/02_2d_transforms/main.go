@@ -1,29 +1,48 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"image/color"
 	"log"
 	"math"
 
-	matrix "github.com/go-gl/mathgl/mgl64"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/insood/graphics/internal/clock"
+	"github.com/insood/graphics/internal/headless"
+	mymath "github.com/insood/graphics/internal/math"
+	"github.com/insood/graphics/internal/path"
+	"github.com/insood/graphics/internal/raster"
+	"github.com/insood/graphics/internal/scenefile"
+	"github.com/insood/graphics/internal/transform"
 )
 
 const (
 	screenWidth  = 640
 	screenHeight = 640
 	testGridSize = 10
+
+	flatness        = 0.25 // Most a curve may stray from its flattened lines, in pixels
+	annotationWidth = 2    // Pixels
 )
 
+var HighlightColor = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
 // Draw Mode (Test Pattern or Scene)
 const (
 	TestPattern = iota
 	SceneLayout
 )
 
+// Gear tooth style
+const (
+	InvoluteTeeth     = iota // Outline of involute teeth
+	InvoluteTriangles        // The triangles that fill the involute teeth
+	TriangleTeeth            // One triangle per tooth
+)
+
 // Projection & View Matrix Mode
 const (
 	Identity      = iota // Use identity matrix
@@ -34,19 +53,18 @@ const (
 )
 
 type Game struct {
-	drawMode  int
-	debugMode bool
+	drawMode   int
+	debugMode  bool
+	toothStyle int
+	solid      bool // Fill shapes rather than outlining them
 
-	canvas       *ebiten.Image
+	canvas       *raster.Framebuffer
 	currentColor color.RGBA
 
-	projectionMode   int
-	projectionMatrix matrix.Mat4
-	viewMatrix       matrix.Mat4
-	viewportMatrix   matrix.Mat4
-	modelMatrixStack []matrix.Mat4
+	projectionMode int
+	pipeline       *transform.Pipeline
 
-	cameraTarget matrix.Vec2
+	cameraTarget mymath.Vector2
 	cameraZoom   float64
 	cameraRotate float64
 
@@ -54,24 +72,24 @@ type Game struct {
 	mouseLastX    int
 	mouseLastY    int
 
-	scene *Scene
+	clock     *clock.Clock
+	scene     *Scene
+	selection Selection
 }
 
-func NewGame() *Game {
-	return &Game{
-		drawMode:  SceneLayout,
-		debugMode: false,
+func NewGame(desc *scenefile.Scene, gameClock *clock.Clock) (*Game, error) {
+	game := &Game{
+		drawMode:   SceneLayout,
+		debugMode:  false,
+		toothStyle: InvoluteTeeth,
 
-		canvas:       ebiten.NewImage(screenWidth, screenHeight),
+		canvas:       raster.NewFramebuffer(screenWidth, screenHeight),
 		currentColor: color.RGBA{},
 
-		projectionMode:   Identity,
-		projectionMatrix: matrix.Ident4(),
-		viewMatrix:       matrix.Ident4(),
-		viewportMatrix:   getViewport(screenWidth, screenHeight),
-		modelMatrixStack: []matrix.Mat4{matrix.Ident4()},
+		projectionMode: Identity,
+		pipeline:       transform.NewPipeline(screenWidth, screenHeight),
 
-		cameraTarget: matrix.Vec2{},
+		cameraTarget: mymath.Vector2{},
 		cameraZoom:   1.0,
 		cameraRotate: 0,
 
@@ -79,8 +97,25 @@ func NewGame() *Game {
 		mouseLastX:    0,
 		mouseLastY:    0,
 
-		scene: makeScene(),
+		clock: gameClock,
+	}
+
+	if c := desc.Camera; c != nil {
+		if c.Target != nil {
+			game.cameraTarget = c.Target.Vec().Vec2()
+		}
+		if c.Zoom != 0 {
+			game.cameraZoom = c.Zoom
+		}
+		game.cameraRotate = c.Rotation
+	}
+
+	var err error
+	if game.scene, err = makeScene(game, desc); err != nil {
+		return nil, err
 	}
+
+	return game, nil
 }
 
 func (g *Game) Update() error {
@@ -88,6 +123,14 @@ func (g *Game) Update() error {
 		return ebiten.Termination
 	}
 
+	if g.clock.Replayed() {
+		return ebiten.Termination
+	}
+
+	if err := g.pipeline.Err(); err != nil {
+		return err
+	}
+
 	if inpututil.IsKeyJustPressed(ebiten.KeyO) {
 		g.projectionMode++
 		if g.projectionMode > Aspect {
@@ -126,6 +169,8 @@ func (g *Game) Update() error {
 
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
 		g.mouseDragging = true
+
+		g.Select(g.Pick(float64(mouseX), float64(mouseY)))
 	}
 
 	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
@@ -146,7 +191,31 @@ func (g *Game) Update() error {
 	g.mouseLastY = mouseY
 
 	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		g.scene.active = !g.scene.active
+		g.clock.Paused = !g.clock.Paused
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+		g.clock.SingleStep()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		g.clock.ToggleSlowMotion()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		g.scene.describe()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		g.toothStyle = (g.toothStyle + 1) % (TriangleTeeth + 1)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		g.solid = !g.solid
+	}
+
+	for range g.clock.Frame() {
+		g.scene.update(g.clock.Step)
 	}
 
 	return nil
@@ -160,42 +229,42 @@ func (g *Game) mouseDragged(dx, dy int) {
 	// the mouse has moved. By multiplying by 2, we can convert that to a delta NDC
 	// PVector deltaNDC = new PVector(-xMove*2, yMove*2);
 
-	deltaNDC := matrix.Vec4{-xmove * 2, ymove * 2, 0, 0}
+	deltaNDC := mymath.Vector4{X: -xmove * 2, Y: ymove * 2, Z: 0, W: 0}
 
-	inverseProjection := matrix.Mat4(g.projectionMatrix)
-	inverseProjection.Set(0, 3, 0)
-	inverseProjection.Set(1, 3, 0)
-	inverseProjection.Set(2, 3, 0)
-	inverseProjection = inverseProjection.Inv()
+	inverseProjection := g.pipeline.Projection
+	inverseProjection[0][3] = 0
+	inverseProjection[1][3] = 0
+	inverseProjection[2][3] = 0
+	inverseProjection, _ = inverseProjection.Inverse()
 
-	inverseViewMatrix := matrix.Mat4(g.viewMatrix)
-	inverseViewMatrix.Set(0, 3, 0)
-	inverseViewMatrix.Set(1, 3, 0)
-	inverseViewMatrix.Set(2, 3, 0)
-	inverseViewMatrix = inverseViewMatrix.Inv()
+	inverseViewMatrix := g.pipeline.View
+	inverseViewMatrix[0][3] = 0
+	inverseViewMatrix[1][3] = 0
+	inverseViewMatrix[2][3] = 0
+	inverseViewMatrix, _ = inverseViewMatrix.Inverse()
 
-	deltaView := inverseProjection.Mul4x1(deltaNDC)
-	deltaWorld := inverseViewMatrix.Mul4x1(deltaView)
+	deltaView := inverseProjection.MulVector4(deltaNDC)
+	deltaWorld := inverseViewMatrix.MulVector4(deltaView)
 
-	g.cameraTarget[0] += deltaWorld.X()
-	g.cameraTarget[1] += deltaWorld.Y()
+	g.cameraTarget.X += deltaWorld.X
+	g.cameraTarget.Y += deltaWorld.Y
 }
 
 func (g *Game) wheelScrolled(wheelY float64) {
 	mouseX, mouseY := ebiten.CursorPosition()
 
-	mouseNDC := matrix.Vec4{
-		float64(mouseX)*2.0/float64(screenWidth) - 1.0,
-		-(float64(mouseY)*2.0/float64(screenHeight) - 1.0),
-		0,
-		0,
+	mouseNDC := mymath.Vector4{
+		X: float64(mouseX)*2.0/float64(screenWidth) - 1.0,
+		Y: -(float64(mouseY)*2.0/float64(screenHeight) - 1.0),
+		Z: 0,
+		W: 0,
 	}
 
-	invertedProjectedMatrix := matrix.Mat4(g.projectionMatrix).Inv()
-	invertedViewMatrix := matrix.Mat4(g.viewMatrix).Inv()
+	invertedProjectedMatrix, _ := g.pipeline.Projection.Inverse()
+	invertedViewMatrix, _ := g.pipeline.View.Inverse()
 
-	mouseView := invertedProjectedMatrix.Mul4x1(mouseNDC)
-	mouseWorld := invertedViewMatrix.Mul4x1(mouseView)
+	mouseView := invertedProjectedMatrix.MulVector4(mouseNDC)
+	mouseWorld := invertedViewMatrix.MulVector4(mouseView)
 
 	g.cameraTarget = mouseWorld.Vec2()
 
@@ -207,8 +276,13 @@ func (g *Game) wheelScrolled(wheelY float64) {
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	screen.Clear()
+	screen.WritePixels(g.Render().Pixels())
+}
+
+// Render draws the current frame into the offscreen framebuffer
+func (g *Game) Render() *raster.Framebuffer {
 	g.canvas.Clear()
+	g.pipeline.Reset()
 	g.SetProjection()
 
 	switch g.drawMode {
@@ -221,7 +295,7 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		g.DrawScene()
 	}
 
-	screen.DrawImage(g.canvas, nil)
+	return g.canvas
 }
 
 func (g *Game) SetColor(color color.RGBA) {
@@ -229,101 +303,146 @@ func (g *Game) SetColor(color color.RGBA) {
 }
 
 func (g *Game) SetProjection() {
-	upVector := matrix.Vec2{0, 1}
-	rotate := matrix.Mat2FromRows(
-		matrix.Vec2{math.Cos(g.cameraRotate), math.Sin(g.cameraRotate)},
-		matrix.Vec2{-math.Sin(g.cameraRotate), math.Cos(g.cameraRotate)},
-	)
-
-	upVector = rotate.Mul2x1(upVector)
+	// (0, 1) rotated clockwise by cameraRotate
+	upVector := mymath.Vector2{X: math.Sin(g.cameraRotate), Y: math.Cos(g.cameraRotate)}
 
 	switch g.projectionMode {
 	case Identity:
-		g.projectionMatrix = matrix.Ident4()
+		g.pipeline.Projection = mymath.Ident4()
 	case Center640:
-		g.projectionMatrix = getOrtho(-320, 320, -320, 320)
+		g.pipeline.Projection = getOrtho(-320, 320, -320, 320)
 	case BottomLeft640:
-		g.projectionMatrix = getOrtho(0, 640, 0, 640)
+		g.pipeline.Projection = getOrtho(0, 640, 0, 640)
 	case FlipX:
-		g.projectionMatrix = getOrtho(320, -320, -320, 320)
+		g.pipeline.Projection = getOrtho(320, -320, -320, 320)
 	case Aspect:
-		g.projectionMatrix = getOrtho(-320, 320, -100, 100)
+		g.pipeline.Projection = getOrtho(-320, 320, -100, 100)
 	}
 
-	g.viewMatrix = getCamera(upVector, g.cameraTarget, g.cameraZoom)
+	g.pipeline.View = getCamera(upVector, g.cameraTarget, g.cameraZoom)
 }
 
-func (g *Game) PushMatrix() {
-	g.modelMatrixStack = append(g.modelMatrixStack, matrix.Mat4(g.modelMatrixStack[len(g.modelMatrixStack)-1]))
+func (g *Game) Project(worldx, worldy float64) mymath.Vector2 {
+	projected := g.pipeline.Project(mymath.Vector3{X: worldx, Y: worldy, Z: 0})
+
+	if g.debugMode {
+		fmt.Println("clip: ", projected.Clip)
+		fmt.Println("ndc: ", projected.NDC)
+		fmt.Println("screen: ", projected.Screen)
+	}
+
+	return projected.Screen
 }
 
-func (g *Game) PopMatrix() {
-	g.modelMatrixStack = g.modelMatrixStack[:len(g.modelMatrixStack)-1]
+// Unproject maps screen coordinates back to world space, undoing the view, projection and
+// viewport transforms
+func (g *Game) Unproject(screenX, screenY float64) mymath.Vector2 {
+	world, _ := g.pipeline.Unproject(mymath.Vector2{X: screenX, Y: screenY}, 0)
+	return world.Vec2()
 }
 
-func (g *Game) ScaleModel(s float64) {
-	scale := matrix.Mat4FromRows(
-		matrix.Vec4{s, 0, 0, 0},
-		matrix.Vec4{0, s, 0, 0},
-		matrix.Vec4{0, 0, 1, 0},
-		matrix.Vec4{0, 0, 0, 1},
-	)
+// Pick finds the gear under a screen position
+func (g *Game) Pick(screenX, screenY float64) Selection {
+	return g.scene.pick(g.Unproject(screenX, screenY))
+}
 
-	g.modelMatrixStack[len(g.modelMatrixStack)-1] = g.modelMatrixStack[len(g.modelMatrixStack)-1].Mul4(scale)
+func (g *Game) Select(s Selection) {
+	if s != g.selection && !s.Empty() {
+		log.Println("selected", s.Name())
+	}
+	g.selection = s
 }
 
-func (g *Game) TranslateModel(x, y, z float64) {
-	translate := matrix.Mat4FromRows(
-		matrix.Vec4{1, 0, 0, x},
-		matrix.Vec4{0, 1, 0, y},
-		matrix.Vec4{0, 0, 1, z},
-		matrix.Vec4{0, 0, 0, 1},
-	)
+// Selected is the gear last picked, for tools that act on it
+func (g *Game) Selected() Selection {
+	return g.selection
+}
 
-	g.modelMatrixStack[len(g.modelMatrixStack)-1] = g.modelMatrixStack[len(g.modelMatrixStack)-1].Mul4(translate)
+func (g *Game) DrawLine(start, end mymath.Vector2) {
+	g.canvas.DrawLine(start, end, g.currentColor)
 }
 
-func (g *Game) RotateModel(angle float64) {
-	rotate := matrix.Mat4FromRows(
-		matrix.Vec4{math.Cos(angle), -math.Sin(angle), 0, 0},
-		matrix.Vec4{math.Sin(angle), math.Cos(angle), 0, 0},
-		matrix.Vec4{0, 0, 1, 0},
-		matrix.Vec4{0, 0, 0, 1},
-	)
+func (g *Game) DrawTriangle(modelA, modelB, modelC mymath.Vector2) {
+	screenA := g.Project(modelA.X, modelA.Y)
+	screenB := g.Project(modelB.X, modelB.Y)
+	screenC := g.Project(modelC.X, modelC.Y)
 
-	g.modelMatrixStack[len(g.modelMatrixStack)-1] = g.modelMatrixStack[len(g.modelMatrixStack)-1].Mul4(rotate)
-}
+	if g.solid {
+		g.canvas.FillPolygon([][]mymath.Vector2{{screenA, screenB, screenC}}, raster.NonZero, g.currentColor)
+		return
+	}
 
-func (g *Game) Project(worldx, worldy float64) matrix.Vec2 {
-	world := matrix.Vec4{worldx, worldy, 0, 1}
+	g.DrawLine(screenA, screenB)
+	g.DrawLine(screenB, screenC)
+	g.DrawLine(screenC, screenA)
+}
 
-	model := g.modelMatrixStack[len(g.modelMatrixStack)-1].Mul4x1(world)
-	camera := g.viewMatrix.Mul4x1(model)
-	ndc := g.projectionMatrix.Mul4x1(camera)
+// DrawPolyline draws lines between model space points, and back to the start if closed. Points
+// closer than a couple of pixels are merged, since lines shorter than a pixel aren't drawn.
+func (g *Game) DrawPolyline(points []mymath.Vector2, closed bool) {
+	if len(points) < 2 {
+		return
+	}
 
-	screen := g.viewportMatrix.Mul4x1(ndc)
+	first := g.Project(points[0].X, points[0].Y)
+	last := first
+	for i, p := range points[1:] {
+		screen := g.Project(p.X, p.Y)
+		if screen.Subtract(last).Magnitude() < 2 && i < len(points)-2 {
+			continue
+		}
+		g.DrawLine(last, screen)
+		last = screen
+	}
+	if closed {
+		g.DrawLine(last, first)
+	}
+}
 
-	if g.debugMode {
-		fmt.Println("camera: ", camera)
-		fmt.Println("ndc: ", ndc)
-		fmt.Println("screen: ", screen)
+// FillPolygon fills the inside of model space contours, such as an outline and the holes in it,
+// by the raster.EvenOdd or raster.NonZero rule
+func (g *Game) FillPolygon(contours [][]mymath.Vector2, rule int) {
+	screen := make([][]mymath.Vector2, len(contours))
+	for i, contour := range contours {
+		for _, p := range contour {
+			screen[i] = append(screen[i], g.Project(p.X, p.Y))
+		}
 	}
+	g.canvas.FillPolygon(screen, rule, g.currentColor)
+}
 
-	return screen.Vec2()
+// screenPath carries a model space path to the screen and flattens it there, so curves get
+// more points the larger they are drawn
+func (g *Game) screenPath(p *path.Path) []path.Contour {
+	return p.Transform(func(v mymath.Vector2) mymath.Vector2 {
+		return g.Project(v.X, v.Y)
+	}).Flatten(flatness)
 }
 
-func (g *Game) DrawLine(start, end matrix.Vec2) {
-	vector.StrokeLine(g.canvas, float32(start[0]), float32(start[1]), float32(end[0]), float32(end[1]), 1, g.currentColor, false)
+// FillPath fills a model space path. Open subpaths are treated as closed.
+func (g *Game) FillPath(p *path.Path, rule int) {
+	var contours [][]mymath.Vector2
+	for _, c := range g.screenPath(p) {
+		contours = append(contours, c.Points)
+	}
+	g.canvas.FillPolygon(contours, rule, g.currentColor)
 }
 
-func (g *Game) DrawTriangle(modelA, modelB, modelC matrix.Vec2) {
-	screenA := g.Project(modelA[0], modelA[1])
-	screenB := g.Project(modelB[0], modelB[1])
-	screenC := g.Project(modelC[0], modelC[1])
+// StrokePath draws the lines of a model space path. The stroke width is in pixels.
+func (g *Game) StrokePath(p *path.Path, stroke raster.Stroke) {
+	for _, c := range g.screenPath(p) {
+		if stroke.Width > 1 {
+			g.canvas.FillPolygon(stroke.Outline(c.Points, c.Closed), raster.NonZero, g.currentColor)
+			continue
+		}
 
-	g.DrawLine(screenA, screenB)
-	g.DrawLine(screenB, screenC)
-	g.DrawLine(screenC, screenA)
+		for i := 1; i < len(c.Points); i++ {
+			g.DrawLine(c.Points[i-1], c.Points[i])
+		}
+		if c.Closed {
+			g.DrawLine(c.Points[len(c.Points)-1], c.Points[0])
+		}
+	}
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
@@ -331,12 +450,69 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 }
 
 func main() {
-	ebiten.SetWindowSize(screenWidth, screenHeight)
-	ebiten.SetWindowTitle("2D Transforms")
+	headlessMode := flag.Bool("headless", false, "render frames offscreen without opening a window")
+	frames := flag.Int("frames", 1, "number of frames to render in headless mode")
+	outDir := flag.String("out", "frames", "directory to write headless frames to")
+	scenePath := flag.String("scene", "", "JSON scene file to draw instead of the built in gears")
+	savePath := flag.String("save-scene", "", "write the scene to a JSON file and exit")
+	recordPath := flag.String("record", "", "write how many ticks each frame ran to a file, to play back with -replay")
+	replayPath := flag.String("replay", "", "run the ticks recorded with -record rather than following the clock")
+	flag.Parse()
+
+	desc := defaultScene()
+
+	if *scenePath != "" {
+		var err error
+		if desc, err = scenefile.Load(*scenePath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *savePath != "" {
+		if err := scenefile.Save(*savePath, desc); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	source := clock.RealTime()
+	if *headlessMode {
+		source = clock.FixedTime(clock.DefaultStep)
+	}
+	gameClock := clock.NewClock(clock.DefaultStep, source)
+
+	if *replayPath != "" {
+		recording, err := clock.LoadRecording(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		gameClock.Replay(recording)
+	}
 
-	game := NewGame()
+	var recording *clock.Recording
+	if *recordPath != "" {
+		recording = gameClock.Record()
+	}
+
+	game, err := NewGame(desc, gameClock)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	if err := ebiten.RunGame(game); err != nil {
+	if *headlessMode {
+		err = headless.Run(game, *frames, *outDir)
+	} else {
+		ebiten.SetWindowSize(screenWidth, screenHeight)
+		ebiten.SetWindowTitle("2D Transforms")
+		err = ebiten.RunGame(game)
+	}
+	if err != nil && !errors.Is(err, ebiten.Termination) { // A replay ending stops headless runs early
 		log.Fatal(err)
 	}
+
+	if recording != nil {
+		if err := recording.Save(*recordPath); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
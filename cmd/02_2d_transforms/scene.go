@@ -1,169 +1,552 @@
 package main
 
 import (
+	"fmt"
 	"image/color"
+	"log"
 	"math"
 
-	matrix "github.com/go-gl/mathgl/mgl64"
+	"github.com/insood/graphics/internal/animation"
+	"github.com/insood/graphics/internal/gearprofile"
+	"github.com/insood/graphics/internal/geartrain"
+	mymath "github.com/insood/graphics/internal/math"
+	"github.com/insood/graphics/internal/path"
+	"github.com/insood/graphics/internal/raster"
+	"github.com/insood/graphics/internal/scenefile"
+	"github.com/insood/graphics/internal/scenegraph"
 )
 
+// Speeds in scene files are per frame at this many frames a second
+const framesPerSecond = 60
+
 type Gear struct {
-	teeth         int
-	x             float64
-	y             float64
-	radius        float64
-	rotationSpeed float64
-	rotation      float64
-	color         color.RGBA
+	name     string
+	teeth    int
+	x        float64
+	y        float64
+	radius   float64 // Pitch radius
+	rotation float64
+	speed    float64 // Radians per frame relative to its carrier
+	color    color.RGBA
+	node     *scenegraph.Node
+	profile  *gearprofile.Profile // Involute teeth for a gear with a pitch radius of 1
 }
 
 type RingGear struct {
+	name      string
 	teeth     int
 	x         float64
 	y         float64
 	radius    float64
-	thickness float64
+	thickness float64 // Also the pitch radius
 	rotation  float64
+	speed     float64 // Radians per frame
 	color     color.RGBA
+	node      *scenegraph.Node
+	profile   *gearprofile.Profile // Involute teeth for a ring with a rim radius of 1
 }
 
-type Scene struct {
-	active                     bool
-	sunGear                    Gear
-	ringGear                   RingGear
-	planetaryGears             []Gear
-	planetaryRadius            float64
-	planetaryGearRotationSpeed float64
-	planetaryGearRotation      float64
+// Carrier turns the gears riding on it about its origin
+type Carrier struct {
+	name     string
+	rotation float64
+	node     *scenegraph.Node
 }
 
-func makeScene() *Scene {
-	scene := Scene{active: true, planetaryGearRotationSpeed: 0.008}
+type Scene struct {
+	root      *scenegraph.Node
+	hub       *path.Path // Drawn in the middle of every gear
+	timeline  *animation.Timeline
+	train     *geartrain.Train
+	input     *geartrain.Member // Driven member of the train
+	gears     []*Gear
+	ringGears []*RingGear
+	carriers  []*Carrier
+}
 
-	scene.sunGear = Gear{teeth: 20, x: 0, y: 0, radius: 0.1, rotationSpeed: 0.042, rotation: 0.1, color: color.RGBA{R: 255, G: 255, B: 0, A: 255}}
-	scene.ringGear = RingGear{teeth: 100, x: 0, y: 0, thickness: 0.9, radius: 0.64, rotation: 0.02, color: color.RGBA{R: 255, G: 0, B: 0, A: 255}}
+// defaultScene is a planetary gear set: the sun and the ring turn about the middle, and the
+// planets ride on a carrier that turns between them
+func defaultScene() *scenefile.Scene {
+	desc := &scenefile.Scene{
+		Carriers: []scenefile.Carrier{{Name: "carrier"}},
+		Gears: []scenefile.Gear{
+			{Name: "sun gear", Teeth: 20, Radius: 0.1, Rotation: 0.1, Color: scenefile.Color{1, 1, 0}},
+		},
+		RingGears: []scenefile.RingGear{
+			{Name: "ring gear", Teeth: 100, Thickness: 5.0 / 6, Radius: 0.6, Color: scenefile.Color{1, 0, 0}},
+		},
+		Train: &scenefile.Train{Input: "sun gear", Speed: 0.042, Fixed: "ring gear"},
+	}
 
 	for i := range 3 {
-		y := math.Cos(float64(i)*(2*math.Pi)/3) * 0.34
-		x := math.Sin(float64(i)*(2*math.Pi)/3) * 0.34
-		scene.planetaryGears = append(scene.planetaryGears, Gear{teeth: 40, x: x, y: y, radius: 0.2, rotationSpeed: -0.02, color: color.RGBA{R: 0, G: 255, B: 0, A: 255}})
+		y := math.Cos(float64(i)*(2*math.Pi)/3) * 0.3
+		x := math.Sin(float64(i)*(2*math.Pi)/3) * 0.3
+		name := fmt.Sprintf("planet gear %d", i+1)
+		desc.Gears = append(desc.Gears, scenefile.Gear{Name: name, Teeth: 40, X: x, Y: y, Radius: 0.2, Color: scenefile.Color{0, 1, 0}, Carrier: "carrier"})
+		desc.Train.Meshes = append(desc.Train.Meshes, [2]string{"sun gear", name}, [2]string{name, "ring gear"})
 	}
 
-	return &scene
+	return desc
 }
 
-func (s *Scene) update() {
-	if !s.active {
-		return
+// toothShape is the involute tooth for a gear of the given module, as described by the scene
+func toothShape(desc *scenefile.Scene, module float64, teeth int, internal bool) (*gearprofile.Profile, error) {
+	p := gearprofile.Standard(module, teeth)
+	if t := desc.Teeth; t != nil {
+		if t.PressureAngle != 0 {
+			p.PressureAngle = t.PressureAngle
+		}
+		if t.Addendum != 0 {
+			p.Addendum = t.Addendum
+		}
+		if t.Dedendum != 0 {
+			p.Dedendum = t.Dedendum
+		}
+		p.Backlash = t.Backlash * module
+	}
+	p.Internal, p.RimRadius = internal, 1
+	return gearprofile.Generate(p)
+}
+
+// makeScene builds the scene graph for a scene description. Nodes are drawn in the order they
+// are added: loose gears, then carriers with their gears, then ring gears on top.
+func makeScene(game *Game, desc *scenefile.Scene) (*Scene, error) {
+	scene := Scene{root: scenegraph.NewNode("gears"), hub: makeHub(), timeline: animation.NewTimeline()}
+	carriers := map[string]*Carrier{}
+
+	if err := scene.solveTrain(desc); err != nil {
+		return nil, err
+	}
+
+	for _, c := range desc.Carriers {
+		speed, rotation := scene.motion(c.Name, c.Speed, c.Rotation)
+		carrier := &Carrier{name: c.Name, rotation: rotation, node: scenegraph.NewNode(c.Name)}
+		scene.spin(&carrier.rotation, speed)
+		scene.carriers = append(scene.carriers, carrier)
+		carriers[c.Name] = carrier
+	}
+
+	for _, g := range desc.Gears {
+		speed, rotation := scene.motion(g.Name, g.Speed, g.Rotation)
+		gear := &Gear{name: g.Name, teeth: g.Teeth, x: g.X, y: g.Y, radius: g.Radius, rotation: rotation, speed: speed, color: g.Color.RGBA()}
+		scene.spin(&gear.rotation, speed)
+		var err error
+		if gear.profile, err = toothShape(desc, 2/float64(g.Teeth), g.Teeth, false); err != nil {
+			return nil, fmt.Errorf("%s: %w", g.Name, err)
+		}
+		gear.node = scenegraph.NewNode(g.Name)
+		gear.node.Drawable = gearDrawable{game, gear}
+		scene.gears = append(scene.gears, gear)
+
+		if c, ok := carriers[g.Carrier]; ok {
+			c.node.AddChild(gear.node)
+		} else {
+			scene.root.AddChild(gear.node)
+		}
+	}
+
+	for _, c := range scene.carriers {
+		scene.root.AddChild(c.node)
+	}
+
+	for _, r := range desc.RingGears {
+		speed, rotation := scene.motion(r.Name, r.Speed, r.Rotation)
+		ring := &RingGear{name: r.Name, teeth: r.Teeth, x: r.X, y: r.Y, radius: r.Radius, thickness: r.Thickness, rotation: rotation, speed: speed, color: r.Color.RGBA()}
+		scene.spin(&ring.rotation, speed)
+		var err error
+		if ring.profile, err = toothShape(desc, 2*r.Thickness/float64(r.Teeth), r.Teeth, true); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		ring.node = scenegraph.NewNode(r.Name)
+		ring.node.Drawable = ringGearDrawable{game, ring}
+		scene.ringGears = append(scene.ringGears, ring)
+		scene.root.AddChild(ring.node)
+	}
+
+	scene.updateNodes()
+
+	return &scene, nil
+}
+
+// makeHub is the boss in the middle of a unit gear, with a bore for the shaft and a slot for
+// the key that locks the gear to it
+func makeHub() *path.Path {
+	const hubRadius, boreRadius, keyWidth, keyDepth = 0.3, 0.12, 0.06, 0.04
+
+	edge := math.Asin(keyWidth / 2 / boreRadius) // Angle from the key's centre line to its sides
+	boreStart := mymath.Vector2{X: -boreRadius * math.Sin(edge), Y: boreRadius * math.Cos(edge)}
+	slotTop := boreRadius*math.Cos(edge) + keyDepth
+
+	return path.NewPath().
+		Circle(mymath.Vector2{}, hubRadius).
+		MoveTo(boreStart).
+		ArcTo(mymath.Vector2{}, 2*math.Pi-2*edge).
+		LineTo(mymath.Vector2{X: keyWidth / 2, Y: slotTop}).
+		LineTo(mymath.Vector2{X: -keyWidth / 2, Y: slotTop}).
+		Close()
+}
+
+// solveTrain works out how the members of the scene's gear train turn
+func (s *Scene) solveTrain(desc *scenefile.Scene) error {
+	t := desc.Train
+	if t == nil {
+		return nil
 	}
 
-	s.sunGear.update()
+	members := map[string]*geartrain.Member{}
+	for _, c := range desc.Carriers {
+		carrier := geartrain.NewCarrier(c.Name, mymath.Vector2{})
+		carrier.Rotation = c.Rotation
+		members[c.Name] = carrier
+	}
+	for _, g := range desc.Gears {
+		gear := geartrain.NewGear(g.Name, g.Teeth, mymath.Vector2{X: g.X, Y: g.Y})
+		gear.Carrier, gear.Rotation = members[g.Carrier], g.Rotation
+		members[g.Name] = gear
+	}
+	for _, r := range desc.RingGears {
+		ring := geartrain.NewRingGear(r.Name, r.Teeth, mymath.Vector2{X: r.X, Y: r.Y})
+		ring.Rotation = r.Rotation
+		members[r.Name] = ring
+	}
+
+	s.train, s.input = geartrain.NewTrain(), members[t.Input]
+	for _, pair := range t.Meshes {
+		s.train.Mesh(members[pair[0]], members[pair[1]])
+	}
+	for _, pair := range t.Shafts {
+		s.train.Shaft(members[pair[0]], members[pair[1]])
+	}
+	if t.Fixed != "" {
+		s.train.Fix(members[t.Fixed])
+	}
+	s.train.Drive(s.input, t.Speed)
 
-	s.planetaryGearRotation += s.planetaryGearRotationSpeed
+	if err := s.train.Solve(); err != nil {
+		return fmt.Errorf("gear train: %w", err)
+	}
+	return nil
+}
 
-	if s.planetaryGearRotation > (2 * math.Pi) {
-		s.planetaryGearRotation -= (2 * math.Pi)
-	} else if s.planetaryGearRotation < 0 {
-		s.planetaryGearRotation += (2 * math.Pi)
+// motion is how a gear or carrier turns relative to its parent: solved if it is part of the
+// gear train, otherwise as given
+func (s *Scene) motion(name string, speed, rotation float64) (float64, float64) {
+	if s.train == nil {
+		return speed, rotation
+	}
+	for _, m := range s.train.Members {
+		if m.Name == name {
+			return m.RelativeSpeed(), m.RelativeRotation()
+		}
 	}
+	return speed, rotation
+}
 
-	for i := range s.planetaryGears {
-		s.planetaryGears[i].update()
+// updateNodes copies the gear positions and rotations into the scene graph
+func (s *Scene) updateNodes() {
+	for _, c := range s.carriers {
+		c.node.SetLocal(mymath.RotateZ(c.rotation))
+	}
+	for _, g := range s.gears {
+		g.node.SetLocal(g.transform())
+	}
+	for _, r := range s.ringGears {
+		r.node.SetLocal(r.transform())
 	}
 }
 
-func (g *Gear) update() {
-	g.rotation += g.rotationSpeed
-	if g.rotation > (2 * math.Pi) {
-		g.rotation -= (2 * math.Pi)
-	} else if g.rotation < 0 {
-		g.rotation += (2 * math.Pi)
+// describe logs every node in the scene graph and where its origin is in the world
+func (s *Scene) describe() {
+	s.root.Walk(func(n *scenegraph.Node) bool {
+		origin := n.World().TransformPoint(mymath.Vector3{})
+		log.Printf("%s at (%.3f, %.3f)", n.Path(), origin.X, origin.Y)
+		return true
+	})
+
+	if s.train == nil {
+		return
+	}
+	for _, m := range s.train.Members {
+		if m.Speed == 0 {
+			log.Printf("%s is held still", m.Name)
+		} else {
+			log.Printf("%s turns %.4f radians a frame, %.3f:1 from %s", m.Name, m.Speed, geartrain.Ratio(s.input, m), s.input.Name)
+		}
+	}
+}
+
+// spin turns an angle at speed radians per frame, looping after each full turn
+func (s *Scene) spin(angle *float64, speed float64) {
+	if speed == 0 {
+		return
+	}
+
+	turn := math.Copysign(2*math.Pi, speed)
+	track := animation.NewFloatTrack().Add(0, *angle, nil).Add(turn/(speed*framesPerSecond), *angle+turn, nil)
+	track.Mode = animation.Loop
+	animation.Bind(s.timeline, track, func(a float64) { *angle = a })
+}
+
+// update moves the scene on by elapsed seconds
+func (s *Scene) update(elapsed float64) {
+	s.timeline.Advance(elapsed)
+	s.updateNodes()
+}
+
+// Selection is a gear picked with the mouse. At most one of gear and ring is set.
+type Selection struct {
+	gear *Gear
+	ring *RingGear
+}
+
+func (s Selection) Empty() bool {
+	return s.gear == nil && s.ring == nil
+}
+
+func (s Selection) Name() string {
+	switch {
+	case s.gear != nil:
+		return s.gear.name
+	case s.ring != nil:
+		return s.ring.name
 	}
+	return ""
+}
+
+// pick finds the gear under the world space point p. Gears are tested against a ray looking
+// down on the scene, and the last one drawn is on top.
+func (s *Scene) pick(p mymath.Vector2) Selection {
+	ray := mymath.Ray{Origin: mymath.Vector3{X: p.X, Y: p.Y, Z: 1}, Direction: mymath.Vector3{Z: -1}}
+	picked := Selection{}
+
+	s.root.Walk(func(n *scenegraph.Node) bool {
+		switch d := n.Drawable.(type) {
+		case gearDrawable:
+			if _, hit := ray.IntersectSphere(d.gear.bounds()); hit {
+				picked = Selection{gear: d.gear}
+			}
+		case ringGearDrawable:
+			outer, inner := d.gear.bounds()
+			_, hit := ray.IntersectSphere(outer)
+			_, hole := ray.IntersectSphere(inner)
+			if hit && !hole {
+				picked = Selection{ring: d.gear}
+			}
+		}
+		return true
+	})
+
+	return picked
+}
+
+// transform places a unit gear: teeth stick out past radius 1
+func (g *Gear) transform() mymath.Mat4 {
+	return mymath.Translate(g.x, g.y, 0).Mul(mymath.RotateZ(g.rotation)).Mul(mymath.Scale(g.radius, g.radius, g.radius))
+}
+
+// transform places a unit ring gear: the race runs from thickness to radius 1
+func (g *RingGear) transform() mymath.Mat4 {
+	return mymath.Translate(g.x, g.y, 0).Mul(mymath.Scale(g.radius, g.radius, g.radius)).Mul(mymath.RotateZ(g.rotation))
+}
+
+// bounds is a circle around the gear and its teeth in world space
+func (g *Gear) bounds() mymath.Sphere {
+	return mymath.Sphere{Radius: g.profile.TipRadius()}.Transform(g.node.World())
+}
+
+// bounds are circles around the outside of the ring and around the hole in the middle,
+// inside the tips of its teeth
+func (g *RingGear) bounds() (outer, inner mymath.Sphere) {
+	world := g.node.World()
+	return mymath.Sphere{Radius: g.profile.RimRadius}.Transform(world), mymath.Sphere{Radius: g.profile.TipRadius()}.Transform(world)
 }
 
 func (g *Game) DrawScene() {
 	g.SetColor(color.RGBA{R: 255, G: 255, B: 255, A: 255})
-	g.scene.update()
+	g.scene.root.Draw()
+}
 
-	g.DrawGear(&g.scene.sunGear)
+type gearDrawable struct {
+	game *Game
+	gear *Gear
+}
 
-	g.PushMatrix()
-	g.RotateModel(g.scene.planetaryGearRotation)
-	for i := range g.scene.planetaryGears {
-		g.DrawGear(&g.scene.planetaryGears[i])
-	}
-	g.PopMatrix()
+func (d gearDrawable) Draw(world mymath.Mat4) {
+	d.game.DrawGear(d.gear, world)
+}
 
-	g.DrawRingGear(&g.scene.ringGear)
+type ringGearDrawable struct {
+	game *Game
+	gear *RingGear
 }
 
-func (g *Game) DrawGear(gear *Gear) {
+func (d ringGearDrawable) Draw(world mymath.Mat4) {
+	d.game.DrawRingGear(d.gear, world)
+}
+
+func (g *Game) DrawGear(gear *Gear, world mymath.Mat4) {
 	g.SetColor(gear.color)
-	g.PushMatrix()
-	g.TranslateModel(gear.x, gear.y, 0)
-	g.RotateModel(gear.rotation)
-	g.ScaleModel(gear.radius)
-	g.DrawGearSegments(gear.teeth)
-	g.PopMatrix()
+	if g.selection.gear == gear {
+		g.SetColor(HighlightColor)
+	}
+	g.pipeline.Push()
+	g.pipeline.LoadMatrix(world)
+	if g.toothStyle == TriangleTeeth {
+		g.DrawGearSegments(gear.teeth)
+	} else {
+		g.DrawProfile(gear.profile)
+	}
+
+	if g.solid {
+		g.SetColor(shade(g.currentColor, 0.5))
+		g.FillPath(g.scene.hub, raster.EvenOdd)
+	} else {
+		g.StrokePath(g.scene.hub, raster.NewStroke(1))
+	}
+
+	if g.selection.gear == gear {
+		g.SetColor(HighlightColor)
+		if g.solid {
+			g.SetColor(color.RGBA{A: 255}) // The gear itself is filled with the highlight
+		}
+		g.pipeline.RotateZ(-gear.rotation) // So the arrow stays still while the gear turns
+		g.DrawDirection(gear.speed, 0.65)
+	}
+	g.pipeline.Pop()
+}
+
+// DrawDirection annotates the gear on top of the stack with an arrow around its axis, at radius,
+// showing which way it turns. The arrow is drawn in the current color.
+func (g *Game) DrawDirection(speed, radius float64) {
+	if speed == 0 {
+		return
+	}
+
+	const sweep = 2 * math.Pi / 3
+	direction := math.Copysign(1, speed) // Anticlockwise if positive
+	start := math.Pi/2 - direction*sweep/2
+	end := start + direction*sweep
+
+	at := func(angle, r float64) mymath.Vector2 {
+		return mymath.Vector2{X: r * math.Cos(angle), Y: r * math.Sin(angle)}
+	}
+	head := direction * 0.15 // Angle the arrow head reaches back along the arc
+
+	arrow := path.NewPath().
+		MoveTo(at(start, radius)).
+		ArcTo(mymath.Vector2{}, end-start).
+		MoveTo(at(end-head, radius*1.1)).
+		LineTo(at(end, radius)).
+		LineTo(at(end-head, radius*0.9))
+
+	stroke := raster.NewStroke(annotationWidth)
+	stroke.Cap, stroke.Join = raster.RoundCap, raster.RoundJoin
+	g.StrokePath(arrow, stroke)
+}
+
+// shade darkens a color, 0 making it black and 1 leaving it as is
+func shade(c color.RGBA, amount float64) color.RGBA {
+	return color.RGBA{R: uint8(float64(c.R) * amount), G: uint8(float64(c.G) * amount), B: uint8(float64(c.B) * amount), A: c.A}
+}
+
+// DrawProfile draws involute teeth as an outline or as the triangles that fill them, or fills
+// them solid
+func (g *Game) DrawProfile(profile *gearprofile.Profile) {
+	if g.solid {
+		// Both edges of a ring run the same way, so only the even-odd rule leaves the middle open
+		if profile.Internal {
+			g.FillPolygon([][]mymath.Vector2{profile.Rim, profile.Outline}, raster.EvenOdd)
+		} else {
+			g.FillPolygon([][]mymath.Vector2{profile.Outline}, raster.NonZero)
+		}
+		return
+	}
+
+	if g.toothStyle == InvoluteTriangles {
+		for _, t := range profile.Triangles {
+			g.DrawTriangle(t[0], t[1], t[2])
+		}
+		return
+	}
+
+	g.DrawPolyline(profile.Outline, true)
+	if profile.Internal {
+		g.DrawPolyline(profile.Rim, true)
+	}
 }
 
 func (g *Game) DrawGearSegments(teeth int) {
 	arc := (2 * math.Pi) / float64(teeth)
 
 	for i := range teeth {
-		g.PushMatrix()
-		g.RotateModel(arc * float64(i))
+		g.pipeline.Push()
+		g.pipeline.RotateZ(arc * float64(i))
 		g.DrawHubPiece(arc)
-		g.TranslateModel(0, 1, 0)
+		g.pipeline.Translate(0, 1, 0)
 		g.drawGearTooth(arc)
-		g.PopMatrix()
+		g.pipeline.Pop()
 	}
 }
 
 func (g *Game) drawGearTooth(arc float64) {
 	g.DrawTriangle(
-		matrix.Vec2{-math.Sin(arc) / 2, 0},
-		matrix.Vec2{0, math.Sin(arc)},
-		matrix.Vec2{math.Sin(arc) / 2, 0},
+		mymath.Vector2{X: -math.Sin(arc) / 2, Y: 0},
+		mymath.Vector2{X: 0, Y: math.Sin(arc)},
+		mymath.Vector2{X: math.Sin(arc) / 2, Y: 0},
 	)
 }
 
 func (g *Game) DrawHubPiece(arc float64) {
 	g.DrawTriangle(
-		matrix.Vec2{0, 0},
-		matrix.Vec2{-math.Sin(arc) / 2, math.Cos(arc)},
-		matrix.Vec2{math.Sin(arc) / 2, math.Cos(arc)},
+		mymath.Vector2{X: 0, Y: 0},
+		mymath.Vector2{X: -math.Sin(arc) / 2, Y: math.Cos(arc)},
+		mymath.Vector2{X: math.Sin(arc) / 2, Y: math.Cos(arc)},
 	)
 }
 
-func (g *Game) DrawRingGear(gear *RingGear) {
+func (g *Game) DrawRingGear(gear *RingGear, world mymath.Mat4) {
 	arc := (2 * math.Pi) / float64(gear.teeth)
 	g.SetColor(gear.color)
-	g.PushMatrix()
-	g.ScaleModel(gear.radius)
-	g.RotateModel(gear.rotation)
+	if g.selection.ring == gear {
+		g.SetColor(HighlightColor)
+	}
+	g.pipeline.Push()
+	g.pipeline.LoadMatrix(world)
+
+	if g.selection.ring == gear {
+		g.pipeline.Push()
+		g.pipeline.RotateZ(-gear.rotation)
+		g.DrawDirection(gear.speed, 1.1)
+		g.pipeline.Pop()
+	}
+
+	if g.toothStyle != TriangleTeeth {
+		g.DrawProfile(gear.profile)
+		g.pipeline.Pop()
+		return
+	}
 
 	for i := range gear.teeth {
-		g.PushMatrix()
-		g.RotateModel(arc * float64(i))
+		g.pipeline.Push()
+		g.pipeline.RotateZ(arc * float64(i))
 		g.DrawRingGearSegment(arc, gear.thickness)
-		g.PushMatrix()
-		g.TranslateModel(0, gear.thickness, 0)
-		g.RotateModel(math.Pi)
-		g.ScaleModel(0.75)
+		g.pipeline.Push()
+		g.pipeline.Translate(0, gear.thickness, 0)
+		g.pipeline.RotateZ(math.Pi)
+		g.pipeline.Scale(0.75, 0.75, 1)
 		g.drawGearTooth(arc)
-		g.PopMatrix()
-		g.PopMatrix()
+		g.pipeline.Pop()
+		g.pipeline.Pop()
 	}
 
-	g.PopMatrix()
+	g.pipeline.Pop()
 }
 
 func (g *Game) DrawRingGearSegment(arc, raceThickness float64) {
-	a := matrix.Vec2{math.Cos(arc), math.Sin(arc)}
-	b := matrix.Vec2{math.Cos(arc) * raceThickness, math.Sin(arc) * raceThickness}
-	c := matrix.Vec2{1, 0}
-	d := matrix.Vec2{raceThickness, 0}
+	a := mymath.Vector2{X: math.Cos(arc), Y: math.Sin(arc)}
+	b := mymath.Vector2{X: math.Cos(arc) * raceThickness, Y: math.Sin(arc) * raceThickness}
+	c := mymath.Vector2{X: 1, Y: 0}
+	d := mymath.Vector2{X: raceThickness, Y: 0}
 
 	g.DrawTriangle(a, b, c) //
 	g.DrawTriangle(b, c, d)
@@ -1,156 +1,150 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"image/color"
 	"log"
 	"math"
 
-	matrix "github.com/go-gl/mathgl/mgl64"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/insood/graphics/internal/clock"
+	"github.com/insood/graphics/internal/headless"
+	mymath "github.com/insood/graphics/internal/math"
+	"github.com/insood/graphics/internal/raster"
+	"github.com/insood/graphics/internal/scenefile"
+	"github.com/insood/graphics/internal/transform"
 )
 
 const (
 	screenWidth  = 640
 	screenHeight = 480
-	near         = -10
-	far          = -500
-	starCount    = 500
-	fov          = math.Pi / 2
 )
 
 type Game struct {
 	drawMode  int
 	debugMode bool
 
-	canvas       *ebiten.Image
+	canvas       *raster.Framebuffer
 	currentColor color.RGBA
 
-	projectionMode   int
-	projectionMatrix matrix.Mat4
-	viewMatrix       matrix.Mat4
-	viewportMatrix   matrix.Mat4
-	modelMatrixStack []matrix.Mat4
+	projectionMode int
+	pipeline       *transform.Pipeline
+	fov            float64
+	near           float64 // The camera looks down -z, so near and far are negative
+	far            float64
 
+	clock *clock.Clock
 	scene *Scene
 }
 
-func NewGame() *Game {
+func NewGame(desc *scenefile.Scene, gameClock *clock.Clock) *Game {
 	game := Game{
 		debugMode: false,
 
-		canvas:       ebiten.NewImage(screenWidth, screenHeight),
+		canvas:       raster.NewFramebuffer(screenWidth, screenHeight),
 		currentColor: color.RGBA{},
 
-		projectionMatrix: matrix.Ident4(),
-		viewMatrix:       matrix.Ident4(),
-		viewportMatrix:   getViewport(screenWidth, screenHeight),
-		modelMatrixStack: []matrix.Mat4{matrix.Ident4()},
+		pipeline: transform.NewPipeline(screenWidth, screenHeight),
+
+		clock: gameClock,
+	}
+
+	defaults := defaultScene()
+	camera, starfield := defaults.Camera, defaults.Starfield
+	if desc.Starfield != nil {
+		starfield = desc.Starfield
 	}
 
-	game.scene = makeScene(&game)
+	game.fov, game.near, game.far = camera.FOV, -camera.Near, -camera.Far
+	if c := desc.Camera; c != nil {
+		if c.FOV != 0 {
+			game.fov = c.FOV
+		}
+		if c.Near != 0 {
+			game.near = -c.Near
+		}
+		if c.Far != 0 {
+			game.far = -c.Far
+		}
+	}
+
+	game.scene = makeScene(&game, starfield)
 
 	return &game
 }
 
 func (g *Game) Update() error {
-	g.scene.Update()
-
 	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
 		return ebiten.Termination
 	}
 
+	if g.clock.Replayed() {
+		return ebiten.Termination
+	}
+
+	if err := g.pipeline.Err(); err != nil {
+		return err
+	}
+
 	if inpututil.IsKeyJustPressed(ebiten.KeyD) {
 		g.debugMode = !g.debugMode
 	}
 
 	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		g.scene.active = !g.scene.active
+		g.clock.Paused = !g.clock.Paused
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+		g.clock.SingleStep()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		g.clock.ToggleSlowMotion()
+	}
+
+	for range g.clock.Frame() {
+		g.scene.Update(g.clock.Step)
 	}
 
 	return nil
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	screen.Clear()
+	screen.WritePixels(g.Render().Pixels())
+}
+
+// Render draws the current frame into the offscreen framebuffer
+func (g *Game) Render() *raster.Framebuffer {
 	g.canvas.Clear()
-	g.viewMatrix = matrix.Ident4() // At 0,0, looking in
+	g.pipeline.Reset()
+	g.pipeline.View = mymath.Ident4() // At 0,0, looking in
 
-	right := fovToWidth(fov, math.Abs(near))
+	right := fovToWidth(g.fov, math.Abs(g.near))
 	top := right * float64(screenHeight) / float64(screenWidth)
 
-	g.projectionMatrix = viewFrustum(-right, right, -top, top, near, far)
+	g.pipeline.Projection = viewFrustum(-right, right, -top, top, g.near, g.far)
 	g.scene.Draw()
-	screen.DrawImage(g.canvas, nil)
+	return g.canvas
 }
 
 func (g *Game) SetColor(color color.RGBA) {
 	g.currentColor = color
 }
 
-func (g *Game) PushMatrix() {
-	g.modelMatrixStack = append(g.modelMatrixStack, matrix.Mat4(g.modelMatrixStack[len(g.modelMatrixStack)-1]))
-}
-
-func (g *Game) PopMatrix() {
-	g.modelMatrixStack = g.modelMatrixStack[:len(g.modelMatrixStack)-1]
-}
-
-func (g *Game) ScaleModel(s float64) {
-	scale := matrix.Mat4FromRows(
-		matrix.Vec4{s, 0, 0, 0},
-		matrix.Vec4{0, s, 0, 0},
-		matrix.Vec4{0, 0, s, 0},
-		matrix.Vec4{0, 0, 0, 1},
-	)
-
-	g.modelMatrixStack[len(g.modelMatrixStack)-1] = g.modelMatrixStack[len(g.modelMatrixStack)-1].Mul4(scale)
-}
-
-func (g *Game) TranslateModel(x, y, z float64) {
-	translate := matrix.Mat4FromRows(
-		matrix.Vec4{1, 0, 0, x},
-		matrix.Vec4{0, 1, 0, y},
-		matrix.Vec4{0, 0, 1, z},
-		matrix.Vec4{0, 0, 0, 1},
-	)
-
-	g.modelMatrixStack[len(g.modelMatrixStack)-1] = g.modelMatrixStack[len(g.modelMatrixStack)-1].Mul4(translate)
-}
-
-func (g *Game) RotateModel(angle float64) {
-	rotate := matrix.Mat4FromRows(
-		matrix.Vec4{math.Cos(angle), -math.Sin(angle), 0, 0},
-		matrix.Vec4{math.Sin(angle), math.Cos(angle), 0, 0},
-		matrix.Vec4{0, 0, 1, 0},
-		matrix.Vec4{0, 0, 0, 1},
-	)
-
-	g.modelMatrixStack[len(g.modelMatrixStack)-1] = g.modelMatrixStack[len(g.modelMatrixStack)-1].Mul4(rotate)
-}
-
-func (g *Game) Project(worldx, worldy float64) matrix.Vec2 {
-	world := matrix.Vec4{worldx, worldy, 0, 1}
-
-	model := g.modelMatrixStack[len(g.modelMatrixStack)-1].Mul4x1(world)
-	camera := g.viewMatrix.Mul4x1(model)
-	ndc := g.projectionMatrix.Mul4x1(camera)
-
-	ndc_corrected := ndc.Mul(1.0 / ndc.W())
-
-	screen := g.viewportMatrix.Mul4x1(ndc_corrected)
+func (g *Game) Project(worldx, worldy float64) mymath.Vector2 {
+	projected := g.pipeline.Project(mymath.Vector3{X: worldx, Y: worldy, Z: 0})
 
 	if g.debugMode {
-		fmt.Println("world    : ", world)
-		fmt.Println("model    : ", model)
-		fmt.Println("camera   : ", camera)
-		fmt.Println("ndc      : ", ndc)
-		fmt.Println("ndc W-Cor: ", ndc_corrected)
-		fmt.Println("screen   : ", screen)
+		fmt.Println("clip     : ", projected.Clip)
+		fmt.Println("ndc      : ", projected.NDC)
+		fmt.Println("screen   : ", projected.Screen)
+		fmt.Println("visible  : ", projected.Visible)
 	}
 
-	return screen.Vec2()
+	return projected.Screen
 }
 
 func (g *Game) DrawPixel(x, y int) {
@@ -162,12 +156,67 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 }
 
 func main() {
-	ebiten.SetWindowSize(screenWidth, screenHeight)
-	ebiten.SetWindowTitle("3D Starfield")
+	headlessMode := flag.Bool("headless", false, "render frames offscreen without opening a window")
+	frames := flag.Int("frames", 1, "number of frames to render in headless mode")
+	outDir := flag.String("out", "frames", "directory to write headless frames to")
+	scenePath := flag.String("scene", "", "JSON scene file with starfield and camera settings")
+	savePath := flag.String("save-scene", "", "write the scene to a JSON file and exit")
+	recordPath := flag.String("record", "", "write how many ticks each frame ran to a file, to play back with -replay")
+	replayPath := flag.String("replay", "", "run the ticks recorded with -record rather than following the clock")
+	flag.Parse()
+
+	desc := defaultScene()
+
+	if *scenePath != "" {
+		var err error
+		if desc, err = scenefile.Load(*scenePath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *savePath != "" {
+		if err := scenefile.Save(*savePath, desc); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	game := NewGame()
+	source := clock.RealTime()
+	if *headlessMode {
+		source = clock.FixedTime(clock.DefaultStep)
+	}
+	gameClock := clock.NewClock(clock.DefaultStep, source)
+
+	if *replayPath != "" {
+		recording, err := clock.LoadRecording(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		gameClock.Replay(recording)
+	}
 
-	if err := ebiten.RunGame(game); err != nil {
+	var recording *clock.Recording
+	if *recordPath != "" {
+		recording = gameClock.Record()
+	}
+
+	game := NewGame(desc, gameClock)
+
+	var err error
+	if *headlessMode {
+		err = headless.Run(game, *frames, *outDir)
+	} else {
+		ebiten.SetWindowSize(screenWidth, screenHeight)
+		ebiten.SetWindowTitle("3D Starfield")
+		err = ebiten.RunGame(game)
+	}
+	if err != nil && !errors.Is(err, ebiten.Termination) { // A replay ending stops headless runs early
 		log.Fatal(err)
 	}
+
+	if recording != nil {
+		if err := recording.Save(*recordPath); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
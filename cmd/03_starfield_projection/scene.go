@@ -2,9 +2,16 @@ package main
 
 import (
 	"image/color"
+	"math"
 	"math/rand"
+
+	"github.com/insood/graphics/internal/animation"
+	"github.com/insood/graphics/internal/scenefile"
 )
 
+// Speeds in scene files are per frame at this many frames a second
+const framesPerSecond = 60
+
 type Star struct {
 	x float64
 	y float64
@@ -14,46 +21,64 @@ type Star struct {
 type Scene struct {
 	game               *Game
 	stars              []Star
-	active             bool
+	timeline           *animation.Timeline
 	speed              float64
 	starAppearDistance float64
 }
 
-func makeScene(game *Game) *Scene {
-	scene := Scene{game: game, active: true, speed: 1, starAppearDistance: -500}
+func defaultScene() *scenefile.Scene {
+	return &scenefile.Scene{
+		Camera:    &scenefile.Camera{FOV: math.Pi / 2, Near: 10, Far: 500},
+		Starfield: &scenefile.Starfield{Count: 500, Speed: 1, Acceleration: 0.01, Depth: 500},
+	}
+}
+
+// makeScene scatters stars across the screen at random depths. A seed makes the same stars
+// every run.
+func makeScene(game *Game, field *scenefile.Starfield) *Scene {
+	scene := Scene{game: game, timeline: animation.NewTimeline(), starAppearDistance: -field.Depth}
 
-	for range starCount {
-		x := float64(rand.Intn(screenWidth) - screenWidth/2)
-		y := float64(rand.Intn(screenHeight) - screenHeight/2)
-		z := rand.Float64() * scene.starAppearDistance
+	// The speed a second in, carried on for as long as the scene runs
+	ramp := animation.NewFloatTrack().Add(0, field.Speed, nil).Add(1, field.Speed+field.Acceleration*framesPerSecond, nil)
+	ramp.Mode = animation.Extrapolate
+	animation.Bind(scene.timeline, ramp, func(speed float64) { scene.speed = speed })
+
+	random := rand.Intn
+	depth := rand.Float64
+	if field.Seed != 0 {
+		seeded := rand.New(rand.NewSource(field.Seed))
+		random, depth = seeded.Intn, seeded.Float64
+	}
+
+	for range field.Count {
+		x := float64(random(screenWidth) - screenWidth/2)
+		y := float64(random(screenHeight) - screenHeight/2)
+		z := depth() * scene.starAppearDistance
 		scene.stars = append(scene.stars, Star{x, y, z})
 	}
 
 	return &scene
 }
 
-func (s *Scene) Update() {
-	if !s.active {
-		return
-	}
-
-	s.speed += 0.01
+// Update moves the stars on by elapsed seconds
+func (s *Scene) Update(elapsed float64) {
+	s.timeline.Advance(elapsed)
 
 	for i := range s.stars {
-		s.UpdateStar(&s.stars[i])
+		s.UpdateStar(&s.stars[i], elapsed*framesPerSecond)
 	}
 }
 
 func (s *Scene) Draw() {
 	for i := range s.stars {
-		s.game.PushMatrix()
+		s.game.pipeline.Push()
 		s.DrawStar(&s.stars[i])
-		s.game.PopMatrix()
+		s.game.pipeline.Pop()
 	}
 }
 
-func (s *Scene) UpdateStar(star *Star) {
-	star.z += s.speed
+func (s *Scene) UpdateStar(star *Star, frames float64) {
+	star.z += s.speed * frames
 
 	if star.z > 0 {
 		star.z = s.starAppearDistance
@@ -61,11 +86,10 @@ func (s *Scene) UpdateStar(star *Star) {
 }
 
 func (s *Scene) DrawStar(star *Star) {
-	s.game.TranslateModel(star.x, star.y, star.z)
+	s.game.pipeline.Translate(star.x, star.y, star.z)
 	xy := s.game.Project(star.x, star.y)
 
-	// Ebiten images are stored as premultiplied alpha
-	alpha := uint8(255 * (1 - (star.z / s.starAppearDistance)))
-	s.game.SetColor(color.RGBA{R: alpha, G: alpha, B: alpha, A: 0})
-	s.game.DrawPixel(int(xy[0]), int(xy[1]))
+	brightness := uint8(255 * (1 - (star.z / s.starAppearDistance)))
+	s.game.SetColor(color.RGBA{R: brightness, G: brightness, B: brightness, A: 255})
+	s.game.DrawPixel(int(xy.X), int(xy.Y))
 }
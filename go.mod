@@ -2,10 +2,7 @@ module github.com/insood/graphics
 
 go 1.24.0
 
-require (
-	github.com/go-gl/mathgl v1.2.0
-	github.com/hajimehoshi/ebiten/v2 v2.9.8
-)
+require github.com/hajimehoshi/ebiten/v2 v2.9.8
 
 require (
 	github.com/ebitengine/gomobile v0.0.0-20250923094054-ea854a63cce1 // indirect
@@ -0,0 +1,60 @@
+// Package animation plays keyframed values back over time.
+package animation
+
+import "math"
+
+// Easing reshapes progress between two keyframes. It takes and returns a fraction from 0 to 1.
+type Easing func(t float64) float64
+
+// Linear moves at a constant rate
+var Linear Easing = func(t float64) float64 {
+	return t
+}
+
+// Step holds the first keyframe's value until the next keyframe is reached
+var Step Easing = func(t float64) float64 {
+	if t >= 1 {
+		return 1
+	}
+	return 0
+}
+
+// EaseInOut starts and ends slowly, the same curve as CSS ease-in-out
+var EaseInOut = CubicBezier(0.42, 0, 0.58, 1)
+
+// CubicBezier is the curve from (0, 0) to (1, 1) with control points (x1, y1) and (x2, y2),
+// as in CSS cubic-bezier(). x1 and x2 are clamped between 0 and 1 so time only moves forwards.
+func CubicBezier(x1, y1, x2, y2 float64) Easing {
+	x1 = math.Max(0, math.Min(1, x1))
+	x2 = math.Max(0, math.Min(1, x2))
+
+	bezier := func(p1, p2, s float64) float64 {
+		u := 1 - s
+		return 3*u*u*s*p1 + 3*u*s*s*p2 + s*s*s
+	}
+
+	return func(t float64) float64 {
+		if t <= 0 || t >= 1 {
+			return t
+		}
+
+		// Find the curve parameter s where x(s) = t. x only increases, so bisection always
+		// converges; Newton's method would be faster but can overshoot on flat parts.
+		low, high := 0.0, 1.0
+		s := t
+		for range 50 {
+			x := bezier(x1, x2, s)
+			if math.Abs(x-t) < 1e-9 {
+				break
+			}
+			if x < t {
+				low = s
+			} else {
+				high = s
+			}
+			s = (low + high) / 2
+		}
+
+		return bezier(y1, y2, s)
+	}
+}
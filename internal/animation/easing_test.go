@@ -0,0 +1,83 @@
+package animation
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStep(t *testing.T) {
+	tests := []struct {
+		t    float64
+		want float64
+	}{
+		{0, 0},
+		{0.5, 0},
+		{0.999, 0},
+		{1, 1},
+	}
+
+	for _, tt := range tests {
+		if got := Step(tt.t); got != tt.want {
+			t.Errorf("Step(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestCubicBezier(t *testing.T) {
+	tests := []struct {
+		name      string
+		easing    Easing
+		monotonic bool // False for curves that overshoot
+	}{
+		{"ease in out", EaseInOut, true},
+		{"ease in", CubicBezier(0.42, 0, 1, 1), true},
+		{"ease out", CubicBezier(0, 0, 0.58, 1), true},
+		{"steep", CubicBezier(0.9, 0, 0.1, 1), true},
+		{"x out of range", CubicBezier(-1, 0, 2, 1), true},
+		{"overshoot", CubicBezier(0.68, -0.6, 0.32, 1.6), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.easing(0); got != 0 {
+				t.Errorf("starts at %v", got)
+			}
+			if got := tt.easing(1); got != 1 {
+				t.Errorf("ends at %v", got)
+			}
+			if !tt.monotonic {
+				return
+			}
+
+			previous := 0.0
+			for i := 1; i <= 1000; i++ {
+				got := tt.easing(float64(i) / 1000)
+				if got < previous-1e-9 {
+					t.Errorf("goes back from %v to %v at %v", previous, got, float64(i)/1000)
+				}
+				previous = got
+			}
+		})
+	}
+}
+
+func TestCubicBezierValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		easing Easing
+		t      float64
+		want   float64
+	}{
+		{"straight line", CubicBezier(1.0/3, 1.0/3, 2.0/3, 2.0/3), 0.3, 0.3},
+		{"ease in out is symmetric", EaseInOut, 0.5, 0.5},
+		{"x clamped", CubicBezier(-1, 0, 2, 1), 0.25, CubicBezier(0, 0, 1, 1)(0.25)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.easing(tt.t); math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
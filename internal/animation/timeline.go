@@ -0,0 +1,45 @@
+package animation
+
+// Timeline plays tracks together, applying their values as it advances through time
+type Timeline struct {
+	Speed  float64 // 1 is real time, 0.5 half speed
+	Paused bool
+
+	time     float64
+	bindings []func(time float64)
+}
+
+func NewTimeline() *Timeline {
+	return &Timeline{Speed: 1}
+}
+
+// Bind calls apply with the track's value every time the timeline moves. It is a function
+// rather than a method because methods can't have type parameters.
+func Bind[T any](tl *Timeline, track *Track[T], apply func(value T)) {
+	tl.bindings = append(tl.bindings, func(time float64) {
+		apply(track.Sample(time))
+	})
+	apply(track.Sample(tl.time))
+}
+
+// Time is how far into the timeline playback is, in seconds
+func (tl *Timeline) Time() float64 {
+	return tl.time
+}
+
+// Advance moves playback on by elapsed seconds of real time, scaled by Speed. Nothing moves
+// while paused.
+func (tl *Timeline) Advance(elapsed float64) {
+	if tl.Paused {
+		return
+	}
+	tl.Seek(tl.time + elapsed*tl.Speed)
+}
+
+// Seek jumps to a time in seconds, paused or not
+func (tl *Timeline) Seek(time float64) {
+	tl.time = time
+	for _, apply := range tl.bindings {
+		apply(time)
+	}
+}
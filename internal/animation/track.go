@@ -0,0 +1,157 @@
+package animation
+
+import (
+	"math"
+	"sort"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// What a track does after its last keyframe
+const (
+	Once        = iota // Hold the last value
+	Loop               // Start again from the first keyframe
+	PingPong           // Play backwards to the first keyframe, then forwards again
+	Extrapolate        // Carry on changing at the rate between the last two keyframes
+)
+
+type Keyframe[T any] struct {
+	Time   float64 // Seconds
+	Value  T
+	Easing Easing // Shapes the way to the next keyframe. Nil is Linear.
+}
+
+// Track is a value that changes over time, given by keyframes. Before the first keyframe it
+// has the first keyframe's value.
+type Track[T any] struct {
+	Mode      int
+	keyframes []Keyframe[T]
+	lerp      func(a, b T, t float64) T
+}
+
+// NewTrack makes a track for any type that can be blended: lerp returns a at 0 and b at 1
+func NewTrack[T any](lerp func(a, b T, t float64) T) *Track[T] {
+	return &Track[T]{Mode: Once, lerp: lerp}
+}
+
+func NewFloatTrack() *Track[float64] {
+	return NewTrack(func(a, b, t float64) float64 {
+		return a + (b-a)*t
+	})
+}
+
+func NewVector3Track() *Track[mymath.Vector3] {
+	return NewTrack(func(a, b mymath.Vector3, t float64) mymath.Vector3 {
+		return a.Add(b.Subtract(a).Multiply(t))
+	})
+}
+
+func NewColorTrack() *Track[mymath.Color3] {
+	return NewTrack(func(a, b mymath.Color3, t float64) mymath.Color3 {
+		return a.Multiply(1 - t).Add(b.Multiply(t))
+	})
+}
+
+// NewQuaternionTrack blends rotations along the shortest arc. Extrapolated, it keeps turning
+// about the same axis at the same rate.
+func NewQuaternionTrack() *Track[mymath.Quaternion] {
+	return NewTrack(func(a, b mymath.Quaternion, t float64) mymath.Quaternion {
+		if t >= 0 && t <= 1 {
+			return a.Slerp(b, t)
+		}
+
+		// Slerp falls back to a straight line for nearby rotations, which stops turning when
+		// carried on past b. Repeat the turn from a to b instead.
+		if a.Dot(b) < 0 {
+			b = b.Scale(-1)
+		}
+		axis, angle := b.Mul(a.Conjugate()).AxisAngle()
+		return mymath.QuatFromAxisAngle(axis, angle*t).Mul(a)
+	})
+}
+
+// Add inserts a keyframe, keeping keyframes in time order. A keyframe at the same time as
+// an existing one is placed after it.
+func (tr *Track[T]) Add(time float64, value T, easing Easing) *Track[T] {
+	i := sort.Search(len(tr.keyframes), func(i int) bool {
+		return tr.keyframes[i].Time > time
+	})
+
+	tr.keyframes = append(tr.keyframes, Keyframe[T]{})
+	copy(tr.keyframes[i+1:], tr.keyframes[i:])
+	tr.keyframes[i] = Keyframe[T]{Time: time, Value: value, Easing: easing}
+	return tr
+}
+
+func (tr *Track[T]) Keyframes() []Keyframe[T] {
+	return tr.keyframes
+}
+
+// Duration is the time from the first keyframe to the last
+func (tr *Track[T]) Duration() float64 {
+	if len(tr.keyframes) == 0 {
+		return 0
+	}
+	return tr.keyframes[len(tr.keyframes)-1].Time - tr.keyframes[0].Time
+}
+
+// Sample returns the value at a time in seconds. An empty track returns the zero value.
+func (tr *Track[T]) Sample(time float64) T {
+	var zero T
+	if len(tr.keyframes) == 0 {
+		return zero
+	}
+
+	first := tr.keyframes[0]
+	last := tr.keyframes[len(tr.keyframes)-1]
+	if time <= first.Time {
+		return first.Value
+	}
+
+	duration := tr.Duration()
+	if time >= last.Time {
+		if duration == 0 {
+			return last.Value
+		}
+
+		elapsed := time - first.Time
+		switch tr.Mode {
+		case Once:
+			return last.Value
+		case Extrapolate:
+			from := tr.keyframes[len(tr.keyframes)-2]
+			if from.Time == last.Time {
+				return last.Value
+			}
+			return tr.lerp(from.Value, last.Value, (time-from.Time)/(last.Time-from.Time))
+		case Loop:
+			time = first.Time + math.Mod(elapsed, duration)
+		case PingPong:
+			elapsed = math.Mod(elapsed, 2*duration)
+			if elapsed > duration {
+				elapsed = 2*duration - elapsed
+			}
+			time = first.Time + elapsed
+		}
+	}
+
+	// The last keyframe at or before time
+	i := sort.Search(len(tr.keyframes), func(i int) bool {
+		return tr.keyframes[i].Time > time
+	}) - 1
+	if i >= len(tr.keyframes)-1 {
+		return last.Value
+	}
+
+	from, to := tr.keyframes[i], tr.keyframes[i+1]
+	if to.Time == from.Time {
+		return to.Value
+	}
+
+	easing := from.Easing
+	if easing == nil {
+		easing = Linear
+	}
+
+	return tr.lerp(from.Value, to.Value, easing((time-from.Time)/(to.Time-from.Time)))
+}
@@ -0,0 +1,160 @@
+package animation
+
+import (
+	"math"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// ramp goes from 0 to 10 between 1 and 3 seconds
+func ramp(mode int) *Track[float64] {
+	tr := NewFloatTrack().Add(3, 10, nil).Add(1, 0, nil)
+	tr.Mode = mode
+	return tr
+}
+
+func TestSample(t *testing.T) {
+	tests := []struct {
+		name string
+		mode int
+		time float64
+		want float64
+	}{
+		{"before the start", Once, 0, 0},
+		{"first keyframe", Once, 1, 0},
+		{"between", Once, 1.5, 2.5},
+		{"last keyframe", Once, 3, 10},
+		{"held", Once, 100, 10},
+		{"loop before the end", Loop, 2.9, 9.5},
+		{"loop at the end", Loop, 3, 0},
+		{"loop wrapped", Loop, 3.5, 2.5},
+		{"loop wrapped twice", Loop, 6.5, 7.5},
+		{"loop before the start", Loop, -5, 0},
+		{"ping pong at the end", PingPong, 3, 10},
+		{"ping pong going back", PingPong, 3.5, 7.5},
+		{"ping pong back at the start", PingPong, 5, 0},
+		{"ping pong going forward again", PingPong, 6, 5},
+		{"extrapolate at the end", Extrapolate, 3, 10},
+		{"extrapolate past the end", Extrapolate, 4, 15},
+		{"extrapolate before the start", Extrapolate, -5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ramp(tt.mode).Sample(tt.time); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSampleEasing(t *testing.T) {
+	tr := NewFloatTrack().Add(0, 0, Step).Add(1, 10, EaseInOut).Add(2, 20, nil)
+
+	tests := []struct {
+		time float64
+		want float64
+	}{
+		{0.5, 0},
+		{0.99, 0},
+		{1, 10},
+		{1.5, 15},
+		{1.2, 10 + 10*EaseInOut(0.2)},
+	}
+
+	for _, tt := range tests {
+		if got := tr.Sample(tt.time); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Sample(%v) = %v, want %v", tt.time, got, tt.want)
+		}
+	}
+}
+
+func TestDuplicateKeyframeTimes(t *testing.T) {
+	// A jump from 5 to 10 at 2 seconds
+	tr := NewFloatTrack().Add(1, 0, nil).Add(2, 5, nil).Add(3, 20, nil).Add(2, 10, nil)
+
+	if got := tr.Keyframes()[2].Value; got != 10 {
+		t.Errorf("the later keyframe at 2s has %v, want it after the first", got)
+	}
+
+	tests := []struct {
+		time float64
+		want float64
+	}{
+		{1.5, 2.5},
+		{1.999, 4.995},
+		{2, 10},
+		{2.5, 15},
+	}
+
+	for _, tt := range tests {
+		if got := tr.Sample(tt.time); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Sample(%v) = %v, want %v", tt.time, got, tt.want)
+		}
+	}
+
+	// Extrapolating from two keyframes at the same time holds the last value
+	tr = NewFloatTrack().Add(0, 0, nil).Add(1, 5, nil).Add(1, 10, nil)
+	tr.Mode = Extrapolate
+	if got := tr.Sample(2); got != 10 {
+		t.Errorf("extrapolated a jump to %v, want 10", got)
+	}
+}
+
+func TestSampleFewKeyframes(t *testing.T) {
+	for _, mode := range []int{Once, Loop, PingPong, Extrapolate} {
+		if got := NewFloatTrack().Sample(1); got != 0 {
+			t.Errorf("mode %d: empty track gave %v", mode, got)
+		}
+
+		tr := NewFloatTrack().Add(1, 4, nil)
+		tr.Mode = mode
+		for _, time := range []float64{0, 1, 2} {
+			if got := tr.Sample(time); got != 4 {
+				t.Errorf("mode %d: single keyframe gave %v at %v", mode, got, time)
+			}
+		}
+	}
+}
+
+func TestSampleTypes(t *testing.T) {
+	v := NewVector3Track().Add(0, mymath.Vector3{}, nil).Add(2, mymath.Vector3{X: 2, Y: -4, Z: 6}, nil)
+	if got, want := v.Sample(1), (mymath.Vector3{X: 1, Y: -2, Z: 3}); got != want {
+		t.Errorf("vector track gave %v, want %v", got, want)
+	}
+
+	c := NewColorTrack().Add(0, mymath.Color3{R: 1}, nil).Add(1, mymath.Color3{B: 1}, nil)
+	if got, want := c.Sample(0.25), (mymath.Color3{R: 0.75, B: 0.25}); got != want {
+		t.Errorf("color track gave %v, want %v", got, want)
+	}
+}
+
+func TestExtrapolateQuaternion(t *testing.T) {
+	z := mymath.Vector3{Z: 1}
+	tests := []struct {
+		name string
+		step float64 // Angle turned from one keyframe to the next
+		time float64
+	}{
+		{"large step", 1, 2.5},
+		{"small step", 0.02, 50},
+		{"step over half a turn", 2, 1.5},
+		{"before the last keyframe", 1, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewQuaternionTrack().
+				Add(0, mymath.QuatIdent(), nil).
+				Add(1, mymath.QuatFromAxisAngle(z, tt.step), nil)
+			tr.Mode = Extrapolate
+
+			got := tr.Sample(tt.time)
+			want := mymath.QuatFromAxisAngle(z, tt.step*tt.time)
+			if math.Abs(math.Abs(got.Dot(want))-1) > 1e-9 {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Package clock runs a simulation in fixed steps, however often frames are drawn, so motion
+// is the same speed on every machine and a recorded run can be replayed exactly.
+package clock
+
+import "time"
+
+// DefaultStep is the simulated seconds per tick, matching ebiten's default of 60 updates a second
+const DefaultStep = 1.0 / 60
+
+// MaxTicks is the most ticks run in one frame. A frame that takes longer than this drops the
+// rest rather than falling further behind trying to catch up.
+const MaxTicks = 8
+
+// SlowMotion is the Scale used by ToggleSlowMotion
+const SlowMotion = 0.25
+
+// Source returns the real seconds elapsed since it was last called
+type Source func() float64
+
+// RealTime measures wall clock time between calls
+func RealTime() Source {
+	var last time.Time
+	return func() float64 {
+		now := time.Now()
+		if last.IsZero() {
+			last = now
+		}
+		elapsed := now.Sub(last).Seconds()
+		last = now
+		return elapsed
+	}
+}
+
+// FixedTime pretends that every frame takes the same time, for rendering frames offscreen
+func FixedTime(seconds float64) Source {
+	return func() float64 {
+		return seconds
+	}
+}
+
+type Clock struct {
+	Step   float64 // Simulated seconds per tick
+	Scale  float64 // 1 is real time, 0.25 quarter speed
+	Paused bool
+
+	source      Source
+	accumulator float64
+	ticks       int
+	pending     int // Single steps asked for while paused
+	recording   *Recording
+	replay      *Recording
+	frame       int
+}
+
+// NewClock makes a clock that ticks every step seconds of time from source
+func NewClock(step float64, source Source) *Clock {
+	return &Clock{Step: step, Scale: 1, source: source}
+}
+
+// Frame is called once per frame and returns how many ticks to simulate
+func (c *Clock) Frame() int {
+	elapsed := c.source()
+	ticks := 0
+
+	switch {
+	case c.replay != nil:
+		if c.frame < len(c.replay.Ticks) {
+			ticks = c.replay.Ticks[c.frame]
+		}
+	case c.Paused:
+		ticks = c.pending
+	default:
+		c.accumulator += elapsed * c.Scale
+		ticks = int(c.accumulator / c.Step)
+		c.accumulator -= float64(ticks) * c.Step
+		if ticks > MaxTicks {
+			ticks = MaxTicks
+			c.accumulator = 0
+		}
+	}
+
+	c.pending = 0
+	c.frame++
+	c.ticks += ticks
+	if c.recording != nil {
+		c.recording.Ticks = append(c.recording.Ticks, ticks)
+	}
+
+	return ticks
+}
+
+// SingleStep runs one tick on the next frame. It only has an effect while paused.
+func (c *Clock) SingleStep() {
+	if c.Paused {
+		c.pending++
+	}
+}
+
+// Time is the simulated time in seconds
+func (c *Clock) Time() float64 {
+	return float64(c.ticks) * c.Step
+}
+
+func (c *Clock) Ticks() int {
+	return c.ticks
+}
+
+// Record keeps the ticks of every frame from now on
+func (c *Clock) Record() *Recording {
+	c.recording = &Recording{Step: c.Step}
+	return c.recording
+}
+
+// Replay runs the ticks of a recording, one entry per frame, instead of measuring time. Pause,
+// single steps and slow motion are ignored because the recording already holds their effect.
+func (c *Clock) Replay(r *Recording) {
+	c.replay = r
+	c.Step = r.Step
+	c.frame = 0
+}
+
+// Replayed is true once every frame of a replay has been run
+func (c *Clock) Replayed() bool {
+	return c.replay != nil && c.frame >= len(c.replay.Ticks)
+}
+
+// ToggleSlowMotion switches between real time and SlowMotion
+func (c *Clock) ToggleSlowMotion() {
+	if c.Scale == 1 {
+		c.Scale = SlowMotion
+	} else {
+		c.Scale = 1
+	}
+}
@@ -0,0 +1,166 @@
+package clock
+
+import (
+	"slices"
+	"testing"
+)
+
+// frames returns each of seconds in turn, then zero
+func frames(seconds ...float64) Source {
+	return func() float64 {
+		if len(seconds) == 0 {
+			return 0
+		}
+		s := seconds[0]
+		seconds = seconds[1:]
+		return s
+	}
+}
+
+// run calls Frame n times and returns the ticks of each
+func run(c *Clock, n int) []int {
+	ticks := make([]int, n)
+	for i := range ticks {
+		ticks[i] = c.Frame()
+	}
+	return ticks
+}
+
+func TestFrame(t *testing.T) {
+	tests := []struct {
+		name   string
+		source Source
+		scale  float64
+		want   []int
+	}{
+		{"one tick a frame", FixedTime(0.25), 1, []int{1, 1, 1, 1}},
+		{"remainder carried over", FixedTime(0.375), 1, []int{1, 2, 1, 2}},
+		{"shorter than a step", FixedTime(0.125), 1, []int{0, 1, 0, 1}},
+		{"slow motion", FixedTime(0.5), SlowMotion, []int{0, 1, 0, 1}},
+		{"clamped", FixedTime(10), 1, []int{MaxTicks, MaxTicks}},
+		{"nothing carried over a clamp", frames(10, 0.125, 0.125), 1, []int{MaxTicks, 0, 1}},
+		{"stopped", FixedTime(0), 1, []int{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClock(0.25, tt.source)
+			c.Scale = tt.scale
+			got := run(c, len(tt.want))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ticks %v, want %v", got, tt.want)
+			}
+
+			total := 0
+			for _, n := range tt.want {
+				total += n
+			}
+			if c.Ticks() != total || c.Time() != float64(total)*0.25 {
+				t.Errorf("%d ticks and %vs after frames of %v", c.Ticks(), c.Time(), got)
+			}
+		})
+	}
+}
+
+func TestSingleStep(t *testing.T) {
+	c := NewClock(0.25, FixedTime(0.25))
+
+	c.SingleStep()
+	if got := c.Frame(); got != 1 {
+		t.Errorf("single step while running gave %d ticks, want the usual 1", got)
+	}
+
+	c.Paused = true
+	if got := c.Frame(); got != 0 {
+		t.Errorf("paused frame gave %d ticks", got)
+	}
+
+	c.SingleStep()
+	c.SingleStep()
+	if got := c.Frame(); got != 2 {
+		t.Errorf("two single steps gave %d ticks", got)
+	}
+	if got := c.Frame(); got != 0 {
+		t.Errorf("single steps carried over to the next frame as %d ticks", got)
+	}
+
+	// Time spent paused isn't caught up afterwards
+	c.Paused = false
+	if got := c.Frame(); got != 1 {
+		t.Errorf("first frame after pausing gave %d ticks", got)
+	}
+	if c.Time() != 1 {
+		t.Errorf("time %v, want 1", c.Time())
+	}
+}
+
+func TestToggleSlowMotion(t *testing.T) {
+	c := NewClock(DefaultStep, RealTime())
+	c.ToggleSlowMotion()
+	if c.Scale != SlowMotion {
+		t.Errorf("scale %v after one toggle", c.Scale)
+	}
+	c.ToggleSlowMotion()
+	if c.Scale != 1 {
+		t.Errorf("scale %v after two toggles", c.Scale)
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	c := NewClock(0.25, frames(0.1, 0.3, 0.6, 10, 0.2, 0.2, 0.2, 0.7))
+	recording := c.Record()
+
+	var want []int
+	for i := range 8 {
+		switch i {
+		case 4:
+			c.Paused = true
+			c.SingleStep()
+		case 6:
+			c.Paused = false
+			c.ToggleSlowMotion()
+		}
+		want = append(want, c.Frame())
+	}
+	if !slices.Equal(recording.Ticks, want) {
+		t.Fatalf("recorded %v, want %v", recording.Ticks, want)
+	}
+
+	// Replayed with a different step size, source and settings, which are all ignored
+	replay := NewClock(DefaultStep, FixedTime(1))
+	replay.Paused = true
+	replay.Scale = 3
+	replay.Replay(recording)
+
+	got := run(replay, len(want))
+	if !slices.Equal(got, want) {
+		t.Errorf("replayed %v, want %v", got, want)
+	}
+	if replay.Time() != c.Time() || replay.Ticks() != c.Ticks() {
+		t.Errorf("replay reached %vs in %d ticks, recording %vs in %d", replay.Time(), replay.Ticks(), c.Time(), c.Ticks())
+	}
+	if !replay.Replayed() {
+		t.Error("Replayed is false after the last frame")
+	}
+	if got := replay.Frame(); got != 0 {
+		t.Errorf("frame after the end of the replay gave %d ticks", got)
+	}
+}
+
+func TestReplayed(t *testing.T) {
+	c := NewClock(DefaultStep, FixedTime(1))
+	if c.Replayed() {
+		t.Error("Replayed is true without a replay")
+	}
+
+	c.Replay(&Recording{Step: DefaultStep, Ticks: []int{1, 1}})
+	for i := range 2 {
+		if c.Replayed() {
+			t.Errorf("Replayed is true after %d of 2 frames", i)
+		}
+		c.Frame()
+	}
+	if !c.Replayed() {
+		t.Error("Replayed is false after every frame")
+	}
+}
@@ -0,0 +1,44 @@
+package clock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Recording is how many ticks each frame of a run simulated. Replaying it gives the same
+// simulation on any machine, though not input such as the mouse.
+type Recording struct {
+	Step  float64 `json:"step"`
+	Ticks []int   `json:"ticks"`
+}
+
+func LoadRecording(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Recording
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if r.Step <= 0 {
+		return nil, fmt.Errorf("%s: step must be positive", path)
+	}
+	for i, ticks := range r.Ticks {
+		if ticks < 0 {
+			return nil, fmt.Errorf("%s: ticks[%d] must not be negative", path, i)
+		}
+	}
+
+	return &r, nil
+}
+
+func (r *Recording) Save(path string) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
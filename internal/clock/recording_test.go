@@ -0,0 +1,56 @@
+package clock
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRecordingRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	r := &Recording{Step: DefaultStep, Ticks: []int{1, 0, 2, 8}}
+	if err := r.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Step != r.Step || !slices.Equal(got.Ticks, r.Ticks) {
+		t.Errorf("loaded %v, want %v", got, r)
+	}
+}
+
+func TestLoadRecordingErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"not JSON", "ticks", "invalid character"},
+		{"no step", `{"ticks": [1]}`, "step must be positive"},
+		{"negative step", `{"step": -1, "ticks": [1]}`, "step must be positive"},
+		{"negative ticks", `{"step": 0.1, "ticks": [1, -1]}`, "ticks[1] must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "run.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := LoadRecording(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), path) {
+				t.Errorf("got %v, want an error naming the file with %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadRecording(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("loading a missing file succeeded")
+	}
+}
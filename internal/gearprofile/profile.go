@@ -0,0 +1,221 @@
+// Package gearprofile draws gear teeth with true involute flanks, the shape real gears are cut
+// to so that meshing teeth roll smoothly against each other.
+package gearprofile
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// Params describe a spur gear. Lengths are in whatever units Module is in.
+type Params struct {
+	Module        float64 // Pitch diameter divided by the number of teeth
+	Teeth         int
+	PressureAngle float64 // Radians, 20 degrees for most gears
+	Addendum      float64 // Height of a tooth above the pitch circle, in modules
+	Dedendum      float64 // Depth of a gap below the pitch circle, in modules
+	Backlash      float64 // Play between meshing teeth, measured along the pitch circle
+	Internal      bool    // A ring gear with its teeth on the inside
+	RimRadius     float64 // Internal gears only: outer radius of the ring
+	Steps         int     // Points along each involute flank
+}
+
+// Standard is a gear with a 20 degree pressure angle, a full depth tooth and no backlash
+func Standard(module float64, teeth int) Params {
+	return Params{
+		Module:        module,
+		Teeth:         teeth,
+		PressureAngle: 20 * math.Pi / 180,
+		Addendum:      1,
+		Dedendum:      1.25,
+		Steps:         8,
+	}
+}
+
+// StandardRing is a Standard internal gear with a rim one tooth depth outside its roots
+func StandardRing(module float64, teeth int) Params {
+	p := Standard(module, teeth)
+	p.Internal = true
+	p.RimRadius = p.RootRadius() + 2.25*module
+	return p
+}
+
+func (p Params) PitchRadius() float64 {
+	return p.Module * float64(p.Teeth) / 2
+}
+
+// BaseRadius is the circle the involute unwinds from
+func (p Params) BaseRadius() float64 {
+	return p.PitchRadius() * math.Cos(p.PressureAngle)
+}
+
+// TipRadius is where the teeth end: outside the pitch circle for external gears, inside it for
+// internal ones
+func (p Params) TipRadius() float64 {
+	if p.Internal {
+		return math.Max(p.PitchRadius()-p.Addendum*p.Module, p.BaseRadius())
+	}
+	return p.PitchRadius() + p.Addendum*p.Module
+}
+
+// RootRadius is the bottom of the gaps between teeth
+func (p Params) RootRadius() float64 {
+	if p.Internal {
+		return p.PitchRadius() + p.Dedendum*p.Module
+	}
+	return math.Max(p.PitchRadius()-p.Dedendum*p.Module, 0)
+}
+
+func (p Params) validate() error {
+	switch {
+	case p.Module <= 0:
+		return errors.New("module must be positive")
+	case p.Teeth < 3:
+		return fmt.Errorf("a gear needs at least 3 teeth, not %d", p.Teeth)
+	case p.PressureAngle <= 0 || p.PressureAngle >= math.Pi/4:
+		return errors.New("pressure angle must be between 0 and 45 degrees")
+	case p.Addendum < 0 || p.Dedendum < 0:
+		return errors.New("addendum and dedendum must not be negative")
+	case p.Backlash < 0 || p.Backlash >= math.Pi*p.Module/2:
+		return errors.New("backlash must be between 0 and half the circular pitch")
+	case p.Internal && p.RimRadius <= p.RootRadius():
+		return errors.New("the rim of an internal gear must be outside its roots")
+	}
+	return nil
+}
+
+// Profile is the outline of a gear, with tooth 0 centred on the +y axis
+type Profile struct {
+	Params
+
+	// Outline runs anticlockwise around the teeth. The last point joins back to the first.
+	Outline []mymath.Vector2
+
+	// Rim is the outer edge of an internal gear, anticlockwise, with a point at the same angle
+	// as each point of Outline
+	Rim []mymath.Vector2
+
+	// Triangles fill the gear: the whole disc for an external gear, the ring between Outline
+	// and Rim for an internal one
+	Triangles [][3]mymath.Vector2
+}
+
+func Generate(p Params) (*Profile, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	if p.Steps < 2 {
+		p.Steps = 2
+	}
+
+	profile := &Profile{Params: p}
+	flank := p.flank()
+	pitch := 2 * math.Pi / float64(p.Teeth)
+
+	for i := range p.Teeth {
+		centre := math.Pi/2 + float64(i)*pitch
+
+		// Up the right flank, across the tip, down the left flank, then along the root to the
+		// next tooth. Half angles are at most half a pitch, so angles never decrease and the
+		// outline never folds back on itself.
+		for _, f := range flank {
+			profile.add(f.radius, centre-f.halfAngle)
+		}
+		tip, root := flank[len(flank)-1], flank[0]
+		profile.arc(tip.radius, centre-tip.halfAngle, centre+tip.halfAngle)
+		for j := len(flank) - 1; j >= 0; j-- {
+			profile.add(flank[j].radius, centre+flank[j].halfAngle)
+		}
+		profile.arc(root.radius, centre+root.halfAngle, centre+pitch-root.halfAngle)
+	}
+
+	profile.triangulate()
+	return profile, nil
+}
+
+// flankPoint is a point on the side of a tooth, halfAngle from the tooth's centre line
+type flankPoint struct {
+	radius    float64
+	halfAngle float64
+}
+
+// flank runs from the root of a tooth to its tip
+func (p Params) flank() []flankPoint {
+	pitchRadius, base := p.PitchRadius(), p.BaseRadius()
+	root, tip := p.RootRadius(), p.TipRadius()
+	pitch := 2 * math.Pi / float64(p.Teeth)
+
+	// Measured at the pitch circle, the tooth and the gap are each half the pitch, less or
+	// plus the backlash
+	tooth := (math.Pi*p.Module/2 - p.Backlash/2) / pitchRadius
+	alpha := involute(p.PressureAngle)
+
+	// An internal gear's gaps have the shape of an external gear's teeth. A tooth can be no
+	// wider than a pitch, so with few teeth the flanks of neighbouring gaps meet in a point.
+	halfAngle := func(radius float64) float64 {
+		radius = math.Max(radius, base) // Below the base circle the flank runs straight to the root
+		a := tooth/2 + alpha - involute(math.Acos(base/radius))
+		if p.Internal {
+			gap := pitch - tooth
+			a = pitch/2 - (gap/2 + alpha - involute(math.Acos(base/radius)))
+		}
+		return math.Min(math.Max(a, 0), pitch/2)
+	}
+
+	points := []flankPoint{{root, halfAngle(root)}}
+
+	start := root
+	if !p.Internal && base > root {
+		points = append(points, flankPoint{base, halfAngle(base)})
+		start = base
+	}
+	for i := 1; i <= p.Steps; i++ {
+		r := start + (tip-start)*float64(i)/float64(p.Steps)
+		points = append(points, flankPoint{r, halfAngle(r)})
+	}
+
+	return points
+}
+
+// involute is the angle the involute of a circle has turned through around the circle at
+// pressure angle a
+func involute(a float64) float64 {
+	return math.Tan(a) - a
+}
+
+func (p *Profile) add(radius, angle float64) {
+	point := mymath.Vector2{X: radius * math.Cos(angle), Y: radius * math.Sin(angle)}
+	if n := len(p.Outline); n > 0 && point.Subtract(p.Outline[n-1]).Magnitude() < 1e-12 {
+		return
+	}
+	p.Outline = append(p.Outline, point)
+
+	if p.Internal {
+		p.Rim = append(p.Rim, mymath.Vector2{X: p.RimRadius * math.Cos(angle), Y: p.RimRadius * math.Sin(angle)})
+	}
+}
+
+// arc adds points between two angles, not including the ends, about every 3 degrees
+func (p *Profile) arc(radius, from, to float64) {
+	steps := int(math.Ceil((to - from) / (math.Pi / 60)))
+	for i := 1; i < steps; i++ {
+		p.add(radius, from+(to-from)*float64(i)/float64(steps))
+	}
+}
+
+func (p *Profile) triangulate() {
+	n := len(p.Outline)
+	for i := range n {
+		j := (i + 1) % n
+		if p.Internal {
+			p.Triangles = append(p.Triangles,
+				[3]mymath.Vector2{p.Outline[i], p.Rim[i], p.Rim[j]},
+				[3]mymath.Vector2{p.Outline[i], p.Rim[j], p.Outline[j]})
+		} else {
+			p.Triangles = append(p.Triangles, [3]mymath.Vector2{{}, p.Outline[i], p.Outline[j]})
+		}
+	}
+}
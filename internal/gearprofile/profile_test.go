@@ -0,0 +1,144 @@
+package gearprofile
+
+import (
+	"math"
+	"testing"
+)
+
+// params is every variety of gear the tests check
+func params() []Params {
+	var all []Params
+	for _, teeth := range []int{3, 4, 5, 6, 7, 8, 12, 20, 40, 100} {
+		all = append(all, Standard(1, teeth), StandardRing(1, teeth))
+
+		steep, loose := StandardRing(2, teeth), Standard(0.5, teeth)
+		steep.PressureAngle = 30 * math.Pi / 180
+		loose.Backlash = 0.4 * loose.Module
+		all = append(all, steep, loose)
+	}
+	return all
+}
+
+func TestOutlineIsMonotonic(t *testing.T) {
+	for _, p := range params() {
+		profile, err := Generate(p)
+		if err != nil {
+			t.Fatalf("%d teeth, internal %v: %v", p.Teeth, p.Internal, err)
+		}
+
+		// Going round the outline once, the angle never goes backwards
+		outline := profile.Outline
+		turned := 0.0
+		for i, a := range outline {
+			b := outline[(i+1)%len(outline)]
+			step := math.Remainder(math.Atan2(b.Y, b.X)-math.Atan2(a.Y, a.X), 2*math.Pi)
+			if step < -1e-9 {
+				t.Errorf("%d teeth, internal %v: outline turns back by %v at point %d", p.Teeth, p.Internal, step, i)
+				break
+			}
+			turned += step
+		}
+		if math.Abs(turned-2*math.Pi) > 1e-9 {
+			t.Errorf("%d teeth, internal %v: outline turns %v, want once round", p.Teeth, p.Internal, turned)
+		}
+	}
+}
+
+func TestToothCount(t *testing.T) {
+	for _, p := range params() {
+		profile, err := Generate(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Each tooth crosses the circle halfway between root and tip twice
+		middle := (p.RootRadius() + p.TipRadius()) / 2
+		crossings := 0
+		for i, a := range profile.Outline {
+			b := profile.Outline[(i+1)%len(profile.Outline)]
+			if (a.Magnitude() < middle) != (b.Magnitude() < middle) {
+				crossings++
+			}
+		}
+		if crossings != 2*p.Teeth {
+			t.Errorf("%d teeth, internal %v: %d crossings of the middle circle, want %d", p.Teeth, p.Internal, crossings, 2*p.Teeth)
+		}
+	}
+}
+
+func TestRadii(t *testing.T) {
+	for _, p := range params() {
+		profile, err := Generate(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		low, high := math.Min(p.RootRadius(), p.TipRadius()), math.Max(p.RootRadius(), p.TipRadius())
+		for _, point := range profile.Outline {
+			if r := point.Magnitude(); r < low-1e-9 || r > high+1e-9 {
+				t.Errorf("%d teeth, internal %v: point at radius %v, outside %v to %v", p.Teeth, p.Internal, r, low, high)
+				break
+			}
+		}
+
+		if p.Internal {
+			if len(profile.Rim) != len(profile.Outline) {
+				t.Errorf("%d teeth: %d rim points for %d outline points", p.Teeth, len(profile.Rim), len(profile.Outline))
+			}
+			if len(profile.Triangles) != 2*len(profile.Outline) {
+				t.Errorf("%d teeth: %d triangles, want two per outline point", p.Teeth, len(profile.Triangles))
+			}
+		} else if len(profile.Triangles) != len(profile.Outline) {
+			t.Errorf("%d teeth: %d triangles, want one per outline point", p.Teeth, len(profile.Triangles))
+		}
+	}
+}
+
+func TestStandardRadii(t *testing.T) {
+	gear, ring := Standard(2, 20), StandardRing(2, 20)
+
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"pitch", gear.PitchRadius(), 20},
+		{"base", gear.BaseRadius(), 20 * math.Cos(20*math.Pi/180)},
+		{"tip", gear.TipRadius(), 22},
+		{"root", gear.RootRadius(), 17.5},
+		{"ring tip", ring.TipRadius(), 20 * math.Cos(20*math.Pi/180)}, // Held at the base circle
+		{"ring root", ring.RootRadius(), 22.5},
+		{"ring rim", ring.RimRadius, 27},
+	}
+
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-9 {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(p *Params)
+	}{
+		{"no module", func(p *Params) { p.Module = 0 }},
+		{"two teeth", func(p *Params) { p.Teeth = 2 }},
+		{"flat pressure angle", func(p *Params) { p.PressureAngle = 0 }},
+		{"steep pressure angle", func(p *Params) { p.PressureAngle = math.Pi / 4 }},
+		{"negative addendum", func(p *Params) { p.Addendum = -1 }},
+		{"negative backlash", func(p *Params) { p.Backlash = -0.1 }},
+		{"too much backlash", func(p *Params) { p.Backlash = math.Pi / 2 }},
+		{"rim inside the roots", func(p *Params) { p.Internal, p.RimRadius = true, 10 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Standard(1, 20)
+			tt.change(&p)
+			if _, err := Generate(p); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
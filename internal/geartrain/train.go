@@ -0,0 +1,397 @@
+// Package geartrain works out how every gear in a train turns from its tooth count, the gears
+// it meshes with and which member is held still, so that teeth never slip.
+package geartrain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// Member is a gear or, with no teeth, a carrier: an arm turning about its axis with the axes
+// of other gears fixed to it, like the planets of a planetary gear set.
+type Member struct {
+	Name     string
+	Teeth    int
+	Internal bool           // A ring gear with its teeth on the inside
+	Position mymath.Vector2 // Axis, relative to the carrier's axis if there is one
+	Carrier  *Member        // Nil if the axis is fixed
+
+	// Rotation is the angle at time 0, with a tooth centred on the +y axis at 0. Solve keeps it
+	// for the driven member and for carriers, and works it out for the other gears.
+	Rotation float64
+	Speed    float64 // Radians per unit of time, set by Solve
+}
+
+func NewGear(name string, teeth int, position mymath.Vector2) *Member {
+	return &Member{Name: name, Teeth: teeth, Position: position}
+}
+
+func NewRingGear(name string, teeth int, position mymath.Vector2) *Member {
+	return &Member{Name: name, Teeth: teeth, Internal: true, Position: position}
+}
+
+func NewCarrier(name string, position mymath.Vector2) *Member {
+	return &Member{Name: name, Position: position}
+}
+
+func (m *Member) IsCarrier() bool {
+	return m.Teeth == 0
+}
+
+// RelativeSpeed is the speed as seen from the member's carrier
+func (m *Member) RelativeSpeed() float64 {
+	if m.Carrier == nil {
+		return m.Speed
+	}
+	return m.Speed - m.Carrier.Speed
+}
+
+// RelativeRotation is the rotation at time 0 as seen from the member's carrier
+func (m *Member) RelativeRotation() float64 {
+	if m.Carrier == nil {
+		return m.Rotation
+	}
+	return wrap(m.Rotation - m.Carrier.Rotation)
+}
+
+// pitch is the angle from one tooth to the next
+func (m *Member) pitch() float64 {
+	return 2 * math.Pi / float64(m.Teeth)
+}
+
+// world is where the member's axis is at time 0
+func (m *Member) world() mymath.Vector2 {
+	if m.Carrier == nil {
+		return m.Position
+	}
+	sin, cos := math.Sincos(m.Carrier.Rotation)
+	p := m.Position
+	return m.Carrier.world().Add(mymath.Vector2{X: p.X*cos - p.Y*sin, Y: p.X*sin + p.Y*cos})
+}
+
+// toothFraction is how far along the pitch from a tooth centre the member is in direction angle
+func (m *Member) toothFraction(angle float64) float64 {
+	f := (angle - m.Rotation - math.Pi/2) / m.pitch()
+	return f - math.Floor(f)
+}
+
+// Train is a set of members linked by meshing teeth and shared shafts
+type Train struct {
+	Members []*Member
+
+	meshes     [][2]*Member
+	shafts     [][2]*Member
+	fixed      *Member
+	input      *Member
+	inputSpeed float64
+}
+
+func NewTrain() *Train {
+	return &Train{}
+}
+
+// Add includes members in the train. Carriers of added gears are included automatically.
+func (t *Train) Add(members ...*Member) *Train {
+	for _, m := range members {
+		if !t.contains(m) {
+			t.Members = append(t.Members, m)
+		}
+		if m.Carrier != nil {
+			t.Add(m.Carrier)
+		}
+	}
+	return t
+}
+
+func (t *Train) contains(m *Member) bool {
+	for _, member := range t.Members {
+		if member == m {
+			return true
+		}
+	}
+	return false
+}
+
+// Mesh puts the teeth of two gears together
+func (t *Train) Mesh(a, b *Member) *Train {
+	t.Add(a, b)
+	t.meshes = append(t.meshes, [2]*Member{a, b})
+	return t
+}
+
+// Shaft joins two members so they turn together, as in a compound train
+func (t *Train) Shaft(a, b *Member) *Train {
+	t.Add(a, b)
+	t.shafts = append(t.shafts, [2]*Member{a, b})
+	return t
+}
+
+// Fix holds a member still
+func (t *Train) Fix(m *Member) *Train {
+	t.Add(m)
+	t.fixed = m
+	return t
+}
+
+// Drive turns a member at speed
+func (t *Train) Drive(m *Member, speed float64) *Train {
+	t.Add(m)
+	t.input, t.inputSpeed = m, speed
+	return t
+}
+
+// Ratio is how many times in turns for each turn of out. It is negative if they turn opposite ways.
+func Ratio(in, out *Member) float64 {
+	return in.Speed / out.Speed
+}
+
+// Solve sets the speed of every member and the rotation of every gear that meshes with the
+// driven member, directly or through other gears. It returns an error if the train can't turn,
+// if a member could turn freely, or if gears meshing in a loop can't all fit together.
+func (t *Train) Solve() error {
+	if t.input == nil {
+		return errors.New("no member is driven")
+	}
+
+	rows, err := t.equations()
+	if err != nil {
+		return err
+	}
+
+	speeds, err := t.solveSpeeds(rows)
+	if err != nil {
+		return err
+	}
+	for i, m := range t.Members {
+		m.Speed = speeds[i]
+	}
+
+	return t.solveRotations()
+}
+
+// equations are the rows of a linear system in the members' speeds. The last column of each row
+// is the right hand side.
+func (t *Train) equations() ([][]float64, error) {
+	index := map[*Member]int{}
+	for i, m := range t.Members {
+		index[m] = i
+		if m.Carrier != nil && !m.Carrier.IsCarrier() {
+			return nil, fmt.Errorf("%s is carried by %s, which is a gear", m.Name, m.Carrier.Name)
+		}
+	}
+
+	n := len(t.Members)
+	var rows [][]float64
+	row := func() []float64 {
+		r := make([]float64, n+1)
+		rows = append(rows, r)
+		return r
+	}
+
+	for _, mesh := range t.meshes {
+		a, b := mesh[0], mesh[1]
+		if a.IsCarrier() || b.IsCarrier() {
+			return nil, fmt.Errorf("%s and %s can't mesh: carriers have no teeth", a.Name, b.Name)
+		}
+		if a.Internal && b.Internal {
+			return nil, fmt.Errorf("%s and %s can't mesh: both are ring gears", a.Name, b.Name)
+		}
+
+		carrier, err := frame(a, b)
+		if err != nil {
+			return nil, err
+		}
+
+		// Seen from the carrier both axes stand still and the teeth roll together:
+		// Za (wa - wc) = -Zb (wb - wc), or +Zb (wb - wc) if one is a ring gear
+		za, zb := float64(a.Teeth), float64(b.Teeth)
+		if a.Internal || b.Internal {
+			zb = -zb
+		}
+
+		r := row()
+		r[index[a]] += za
+		r[index[b]] += zb
+		if carrier != nil {
+			r[index[carrier]] -= za + zb
+		}
+	}
+
+	for _, shaft := range t.shafts {
+		r := row()
+		r[index[shaft[0]]] += 1
+		r[index[shaft[1]]] -= 1
+	}
+
+	if t.fixed != nil {
+		row()[index[t.fixed]] = 1
+	}
+
+	r := row()
+	r[index[t.input]] = 1
+	r[n] = t.inputSpeed
+
+	return rows, nil
+}
+
+// frame is the carrier two meshing gears turn relative to, or nil if both axes are fixed. A gear
+// on a fixed axis can only stay meshed with a gear on a carrier if it shares the carrier's axis.
+func frame(a, b *Member) (*Member, error) {
+	if a.Carrier == b.Carrier {
+		return a.Carrier, nil
+	}
+	if a.Carrier != nil && b.Carrier != nil {
+		return nil, fmt.Errorf("%s and %s can't mesh: they are on different carriers", a.Name, b.Name)
+	}
+
+	fixed, carried := a, b
+	if a.Carrier != nil {
+		fixed, carried = b, a
+	}
+	if fixed.Position.Subtract(carried.Carrier.Position).Magnitude() > 1e-9 {
+		return nil, fmt.Errorf("%s and %s can't stay meshed: %s is not on the axis of %s", a.Name, b.Name, fixed.Name, carried.Carrier.Name)
+	}
+	return carried.Carrier, nil
+}
+
+// solveSpeeds reduces the system by Gauss-Jordan elimination
+func (t *Train) solveSpeeds(rows [][]float64) ([]float64, error) {
+	n := len(t.Members)
+	pivotRow := 0
+	pivots := make([]int, n)
+
+	for col := range n {
+		pivots[col] = -1
+
+		best := pivotRow
+		for r := pivotRow; r < len(rows); r++ {
+			if math.Abs(rows[r][col]) > math.Abs(rows[best][col]) {
+				best = r
+			}
+		}
+		if best >= len(rows) || math.Abs(rows[best][col]) < 1e-9 {
+			continue
+		}
+		rows[pivotRow], rows[best] = rows[best], rows[pivotRow]
+
+		p := rows[pivotRow]
+		scale := p[col]
+		for i := range p {
+			p[i] /= scale
+		}
+		for r := range rows {
+			if r == pivotRow || rows[r][col] == 0 {
+				continue
+			}
+			factor := rows[r][col]
+			for i := range rows[r] {
+				rows[r][i] -= factor * p[i]
+			}
+		}
+
+		pivots[col] = pivotRow
+		pivotRow++
+	}
+
+	for col, p := range pivots {
+		if p < 0 {
+			return nil, fmt.Errorf("%s can turn without the input turning: hold another member still", t.Members[col].Name)
+		}
+	}
+
+	// Left over rows must read 0 = 0, otherwise the constraints disagree and nothing can move
+	for _, r := range rows[pivotRow:] {
+		if math.Abs(r[n]) > 1e-9 {
+			return nil, errors.New("the train is locked: its gears can't all turn together")
+		}
+	}
+
+	speeds := make([]float64, n)
+	for col, p := range pivots {
+		speeds[col] = rows[p][n]
+	}
+	return speeds, nil
+}
+
+// solveRotations turns each gear, starting from the driven member, so that at every mesh a
+// tooth of one gear sits in a gap of the other. Rotating both gears as their speeds say keeps
+// it that way.
+func (t *Train) solveRotations() error {
+	known := map[*Member]bool{t.input: true}
+	for _, m := range t.Members {
+		if m.IsCarrier() {
+			known[m] = true
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, mesh := range t.meshes {
+			a, b := mesh[0], mesh[1]
+			if known[a] == known[b] {
+				continue
+			}
+			if known[b] {
+				a, b = b, a
+			}
+			b.Rotation = meshRotation(a, b)
+			known[b] = true
+			changed = true
+		}
+
+		// Gears sharing a shaft can be keyed at any angle, so keep the one they were given
+		for _, shaft := range t.shafts {
+			if known[shaft[0]] != known[shaft[1]] {
+				known[shaft[0]], known[shaft[1]] = true, true
+				changed = true
+			}
+		}
+	}
+
+	for _, mesh := range t.meshes {
+		a, b := mesh[0], mesh[1]
+		if !known[a] || !known[b] {
+			continue
+		}
+		offset := wrap(b.Rotation-meshRotation(a, b)) / b.pitch()
+		if math.Abs(offset-math.Round(offset)) > 1e-6 {
+			return fmt.Errorf("the teeth of %s and %s can't fit together: try different tooth counts or spacing", a.Name, b.Name)
+		}
+	}
+
+	return nil
+}
+
+// meshRotation is the rotation of b that fits its teeth between those of a
+func meshRotation(a, b *Member) float64 {
+	pa, pb := a.world(), b.world()
+
+	if a.Internal || b.Internal {
+		// Both gears are measured in the direction from the ring's centre to the inner gear's,
+		// where a tooth of one must be half a pitch from a tooth of the other
+		ring, inner := pa, pb
+		if b.Internal {
+			ring, inner = pb, pa
+		}
+		angle := math.Atan2(inner.Y-ring.Y, inner.X-ring.X)
+		f := a.toothFraction(angle) + 0.5
+		return wrap(angle - math.Pi/2 - f*b.pitch())
+	}
+
+	// Measured from each gear towards the other, the two fractions add up to half a pitch
+	angle := math.Atan2(pb.Y-pa.Y, pb.X-pa.X)
+	f := 0.5 - a.toothFraction(angle)
+	return wrap(angle + math.Pi - math.Pi/2 - f*b.pitch())
+}
+
+// wrap puts an angle between 0 and 2pi
+func wrap(angle float64) float64 {
+	angle = math.Mod(angle, 2*math.Pi)
+	if angle < 0 {
+		angle += 2 * math.Pi
+	}
+	return angle
+}
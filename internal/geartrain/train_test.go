@@ -0,0 +1,169 @@
+package geartrain
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+type planetary struct {
+	sun, ring, carrier *Member
+	planets            []*Member
+	train              *Train
+}
+
+// newPlanetary spaces planets evenly around a sun, with a ring just big enough to mesh with
+// them. Pitch radii are half the tooth count.
+func newPlanetary(sunTeeth, planetTeeth, planets int) planetary {
+	p := planetary{
+		sun:     NewGear("sun", sunTeeth, mymath.Vector2{}),
+		ring:    NewRingGear("ring", sunTeeth+2*planetTeeth, mymath.Vector2{}),
+		carrier: NewCarrier("carrier", mymath.Vector2{}),
+		train:   NewTrain(),
+	}
+
+	orbit := float64(sunTeeth+planetTeeth) / 2
+	for i := range planets {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(planets))
+		planet := NewGear("planet", planetTeeth, mymath.Vector2{X: orbit * cos, Y: orbit * sin})
+		planet.Carrier = p.carrier
+		p.planets = append(p.planets, planet)
+		p.train.Mesh(p.sun, planet).Mesh(planet, p.ring)
+	}
+	return p
+}
+
+func TestPlanetary(t *testing.T) {
+	tests := []struct {
+		name   string
+		fixed  func(p planetary) *Member
+		driven func(p planetary) *Member
+		// Speeds of the sun, ring and carrier when driven at 1
+		sun, ring, carrier float64
+	}{
+		// Zs = 24 and Zr = 48, so (ws - wc) / (wr - wc) = -2
+		{"ring fixed", func(p planetary) *Member { return p.ring }, func(p planetary) *Member { return p.sun }, 1, 0, 1.0 / 3},
+		{"sun fixed", func(p planetary) *Member { return p.sun }, func(p planetary) *Member { return p.ring }, 0, 1, 2.0 / 3},
+		{"carrier fixed", func(p planetary) *Member { return p.carrier }, func(p planetary) *Member { return p.sun }, 1, -0.5, 0},
+		{"driven by the carrier", func(p planetary) *Member { return p.ring }, func(p planetary) *Member { return p.carrier }, 3, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPlanetary(24, 12, 3)
+			if err := p.train.Fix(tt.fixed(p)).Drive(tt.driven(p), 1).Solve(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !near(p.sun.Speed, tt.sun) || !near(p.ring.Speed, tt.ring) || !near(p.carrier.Speed, tt.carrier) {
+				t.Errorf("sun, ring, carrier turn at %v, %v, %v, want %v, %v, %v",
+					p.sun.Speed, p.ring.Speed, p.carrier.Speed, tt.sun, tt.ring, tt.carrier)
+			}
+
+			// Willis' equation
+			zs, zr := float64(p.sun.Teeth), float64(p.ring.Teeth)
+			if got := (p.sun.Speed - p.carrier.Speed) / (p.ring.Speed - p.carrier.Speed); !near(got, -zr/zs) {
+				t.Errorf("(ws - wc) / (wr - wc) = %v, want %v", got, -zr/zs)
+			}
+
+			// Planets roll on the sun: Zp (wp - wc) = -Zs (ws - wc)
+			for _, planet := range p.planets {
+				want := -zs / float64(planet.Teeth) * (p.sun.Speed - p.carrier.Speed)
+				if got := planet.RelativeSpeed(); !near(got, want) {
+					t.Errorf("planet turns at %v relative to the carrier, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCompoundTrain(t *testing.T) {
+	// 10 drives 30, which shares a shaft with a 10 that drives 40
+	a := NewGear("a", 10, mymath.Vector2{})
+	b := NewGear("b", 30, mymath.Vector2{X: 20})
+	c := NewGear("c", 10, mymath.Vector2{X: 20})
+	d := NewGear("d", 40, mymath.Vector2{X: 45})
+
+	train := NewTrain().Mesh(a, b).Shaft(b, c).Mesh(c, d).Drive(a, 2)
+	if err := train.Solve(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := Ratio(a, d); !near(got, 12) {
+		t.Errorf("ratio %v, want 12", got)
+	}
+	if !near(b.Speed, -2.0/3) || !near(c.Speed, b.Speed) || !near(d.Speed, 1.0/6) {
+		t.Errorf("b, c, d turn at %v, %v, %v", b.Speed, c.Speed, d.Speed)
+	}
+}
+
+func TestSolveErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		train func() *Train
+		want  string
+	}{
+		{"planets don't fit", func() *Train {
+			// (Zs + Zr) / planets = 62 / 3 isn't whole, so the planets can't be evenly spaced
+			p := newPlanetary(20, 11, 3)
+			return p.train.Fix(p.ring).Drive(p.sun, 1)
+		}, "can't fit together"},
+		{"nothing driven", func() *Train {
+			p := newPlanetary(24, 12, 3)
+			return p.train.Fix(p.ring)
+		}, "no member is driven"},
+		{"nothing fixed", func() *Train {
+			p := newPlanetary(24, 12, 3)
+			return p.train.Drive(p.sun, 1)
+		}, "can turn without the input turning"},
+		{"gear left free", func() *Train {
+			a, b := NewGear("a", 10, mymath.Vector2{}), NewGear("b", 10, mymath.Vector2{X: 10})
+			return NewTrain().Mesh(a, b).Add(NewGear("idle", 10, mymath.Vector2{X: 50})).Drive(a, 1)
+		}, "idle can turn without the input turning"},
+		{"meshing in a triangle", func() *Train {
+			a := NewGear("a", 10, mymath.Vector2{})
+			b := NewGear("b", 10, mymath.Vector2{X: 10})
+			c := NewGear("c", 10, mymath.Vector2{X: 5, Y: 5 * math.Sqrt(3)})
+			return NewTrain().Mesh(a, b).Mesh(b, c).Mesh(c, a).Drive(a, 1)
+		}, "locked"},
+		{"two ring gears", func() *Train {
+			a, b := NewRingGear("a", 40, mymath.Vector2{}), NewRingGear("b", 40, mymath.Vector2{})
+			return NewTrain().Mesh(a, b).Drive(a, 1)
+		}, "both are ring gears"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.train().Solve()
+			if err == nil {
+				t.Fatalf("no error, want one containing %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanetsFit(t *testing.T) {
+	// (24 + 48) / 3 is whole, so every planet meshes with the sun and the ring
+	p := newPlanetary(24, 12, 3)
+	if err := p.train.Fix(p.ring).Drive(p.sun, 1).Solve(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, planet := range p.planets {
+		for _, other := range []*Member{p.sun, p.ring} {
+			offset := wrap(planet.Rotation-meshRotation(other, planet)) / planet.pitch()
+			if math.Abs(offset-math.Round(offset)) > 1e-6 {
+				t.Errorf("planet at %v is %v of a tooth out with the %s", planet.Position, offset, other.Name)
+			}
+		}
+	}
+}
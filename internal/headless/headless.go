@@ -0,0 +1,39 @@
+// Package headless steps a game without opening a window and writes each
+// rendered frame to disk as a PNG.
+package headless
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/insood/graphics/internal/raster"
+)
+
+type Game interface {
+	Update() error
+	Render() *raster.Framebuffer
+}
+
+func Run(game Game, frames int, outDir string) error {
+	if frames < 1 {
+		return fmt.Errorf("frames must be at least 1, got %d", frames)
+	}
+
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+
+	for frame := range frames {
+		if err := game.Update(); err != nil {
+			return err
+		}
+
+		path := filepath.Join(outDir, fmt.Sprintf("frame_%04d.png", frame))
+		if err := game.Render().SavePNG(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
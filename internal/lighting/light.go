@@ -0,0 +1,110 @@
+// Package lighting evaluates surface materials under a set of light sources.
+package lighting
+
+import (
+	"math"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+type Material struct {
+	Ambient   mymath.Color3
+	Diffuse   mymath.Color3 // Albedo for physically based models
+	Specular  mymath.Color3
+	Shininess float64
+
+	// Physically based models only. Both range from 0 to 1.
+	Metallic  float64
+	Roughness float64
+}
+
+// Tint scales the ambient and diffuse colors by a surface color, such as a vertex color.
+// Highlights keep the specular color.
+func (m Material) Tint(c mymath.Color3) Material {
+	m.Ambient = m.Ambient.Modulate(c)
+	m.Diffuse = m.Diffuse.Modulate(c)
+	return m
+}
+
+const (
+	Directional = iota
+	Point
+	Spot
+)
+
+type Light struct {
+	Type  int
+	Color mymath.Color3
+
+	Position  mymath.Vector3 // Point and Spot
+	Direction mymath.Vector3 // Directional and Spot: the direction the light travels in
+
+	// Point and Spot intensity is scaled by 1 / (Constant + Linear*d + Quadratic*d^2)
+	Constant  float64
+	Linear    float64
+	Quadratic float64
+
+	// Spot lights are at full intensity within InnerCone of Direction, fading to nothing at
+	// OuterCone. Both are half angles in radians.
+	InnerCone float64
+	OuterCone float64
+}
+
+func NewDirectionalLight(direction mymath.Vector3, color mymath.Color3) Light {
+	return Light{Type: Directional, Color: color, Direction: direction.Normalize()}
+}
+
+func NewPointLight(position mymath.Vector3, color mymath.Color3, linear, quadratic float64) Light {
+	return Light{Type: Point, Color: color, Position: position, Constant: 1, Linear: linear, Quadratic: quadratic}
+}
+
+func NewSpotLight(position, direction mymath.Vector3, color mymath.Color3, innerCone, outerCone float64) Light {
+	return Light{
+		Type:      Spot,
+		Color:     color,
+		Position:  position,
+		Direction: direction.Normalize(),
+		Constant:  1,
+		InnerCone: innerCone,
+		OuterCone: outerCone,
+	}
+}
+
+// Incident returns the unit vector from position towards the light and the light color that
+// arrives there after attenuation and cone falloff
+func (l Light) Incident(position mymath.Vector3) (mymath.Vector3, mymath.Color3) {
+	if l.Type == Directional {
+		return l.Direction.Multiply(-1), l.Color
+	}
+
+	offset := l.Position.Subtract(position)
+	toLight, ok := offset.NormalizeSafe()
+	if !ok {
+		return mymath.Vector3{}, mymath.Color3{}
+	}
+	distance := offset.Magnitude()
+
+	intensity := 1.0
+	if falloff := l.Constant + l.Linear*distance + l.Quadratic*distance*distance; falloff > 0 {
+		intensity = 1 / falloff
+	}
+
+	if l.Type == Spot {
+		cosAngle := toLight.Multiply(-1).Dot(l.Direction)
+		intensity *= smoothstep(math.Cos(l.OuterCone), math.Cos(l.InnerCone), cosAngle)
+	}
+
+	return toLight, l.Color.Multiply(intensity)
+}
+
+func smoothstep(edge0, edge1, x float64) float64 {
+	if edge0 == edge1 {
+		if x < edge0 {
+			return 0
+		}
+		return 1
+	}
+
+	t := math.Min(1, math.Max(0, (x-edge0)/(edge1-edge0)))
+	return t * t * (3 - 2*t)
+}
@@ -0,0 +1,187 @@
+package lighting
+
+import (
+	"math"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// ShadingModel computes the color of a surface point lit by a set of lights and seen from eye.
+// The normal must be unit length. Light colors are the irradiance on a surface facing the
+// light, so every model agrees on the brightness of a plain diffuse surface.
+type ShadingModel interface {
+	Name() string
+	Shade(position, normal, eye mymath.Vector3, material Material, lights []Light) mymath.Color3
+}
+
+// Lambert is ambient plus ideal diffuse reflection, without highlights
+type Lambert struct{}
+
+func (Lambert) Name() string {
+	return "Lambert"
+}
+
+func (Lambert) Shade(position, normal, eye mymath.Vector3, material Material, lights []Light) mymath.Color3 {
+	color := material.Ambient
+
+	for _, light := range lights {
+		toLight, radiance := light.Incident(position)
+		diffuseComponent := normal.Dot(toLight)
+		if diffuseComponent <= 0 {
+			continue
+		}
+
+		color = color.Add(material.Diffuse.Modulate(radiance).Multiply(diffuseComponent))
+	}
+
+	return color
+}
+
+// Phong highlights use the angle between the reflected light and the view direction
+type Phong struct{}
+
+func (Phong) Name() string {
+	return "Phong"
+}
+
+func (Phong) Shade(position, normal, eye mymath.Vector3, material Material, lights []Light) mymath.Color3 {
+	color := material.Ambient
+	view := eye.Subtract(position).Normalize()
+
+	for _, light := range lights {
+		toLight, radiance := light.Incident(position)
+
+		diffuseComponent := normal.Dot(toLight)
+		if diffuseComponent <= 0 {
+			continue
+		}
+		color = color.Add(material.Diffuse.Modulate(radiance).Multiply(diffuseComponent))
+
+		reflection := normal.Multiply(2 * diffuseComponent).Subtract(toLight)
+		specularComponent := math.Max(0, reflection.Dot(view))
+		color = color.Add(material.Specular.Modulate(radiance).Multiply(math.Pow(specularComponent, material.Shininess)))
+	}
+
+	return color
+}
+
+// BlinnPhong highlights use the angle between the normal and the half vector of the light and
+// view directions, which is cheaper and keeps highlights round at grazing angles
+type BlinnPhong struct{}
+
+func (BlinnPhong) Name() string {
+	return "Blinn-Phong"
+}
+
+func (BlinnPhong) Shade(position, normal, eye mymath.Vector3, material Material, lights []Light) mymath.Color3 {
+	color := material.Ambient
+	view := eye.Subtract(position).Normalize()
+
+	for _, light := range lights {
+		toLight, radiance := light.Incident(position)
+
+		diffuseComponent := normal.Dot(toLight)
+		if diffuseComponent <= 0 {
+			continue
+		}
+		color = color.Add(material.Diffuse.Modulate(radiance).Multiply(diffuseComponent))
+
+		half := toLight.Add(view).Normalize()
+		specularComponent := math.Max(0, normal.Dot(half))
+		color = color.Add(material.Specular.Modulate(radiance).Multiply(math.Pow(specularComponent, material.Shininess)))
+	}
+
+	return color
+}
+
+// CookTorrance is a physically based microfacet model using the GGX normal distribution,
+// Smith-Schlick geometry term and Schlick's Fresnel approximation. It reads the material's
+// Diffuse color as albedo along with Metallic and Roughness; Specular and Shininess are unused.
+type CookTorrance struct{}
+
+func (CookTorrance) Name() string {
+	return "Cook-Torrance"
+}
+
+// dielectricReflectance is the reflectance at normal incidence of common non-metals
+const dielectricReflectance = 0.04
+
+// minimumRoughness keeps perfectly smooth surfaces from producing an infinitely sharp peak
+const minimumRoughness = 0.03
+
+func (CookTorrance) Shade(position, normal, eye mymath.Vector3, material Material, lights []Light) mymath.Color3 {
+	color := material.Ambient
+	view := eye.Subtract(position).Normalize()
+
+	metallic := clamp01(material.Metallic)
+	roughness := math.Max(minimumRoughness, clamp01(material.Roughness))
+	albedo := material.Diffuse
+
+	dielectric := mymath.Color3{R: dielectricReflectance, G: dielectricReflectance, B: dielectricReflectance}
+	f0 := dielectric.Multiply(1 - metallic).Add(albedo.Multiply(metallic))
+
+	normalDotView := normal.Dot(view)
+	if normalDotView <= 0 {
+		return color
+	}
+
+	for _, light := range lights {
+		toLight, radiance := light.Incident(position)
+
+		normalDotLight := normal.Dot(toLight)
+		if normalDotLight <= 0 {
+			continue
+		}
+
+		half := toLight.Add(view).Normalize()
+		d := DistributionGGX(normal.Dot(half), roughness)
+		g := GeometrySmith(normalDotView, normalDotLight, roughness)
+		f := FresnelSchlick(math.Max(0, half.Dot(view)), f0)
+
+		// Energy not reflected at the surface is scattered diffusely, except by metals
+		kd := mymath.Color3{R: 1 - f.R, G: 1 - f.G, B: 1 - f.B}.Multiply(1 - metallic)
+
+		// Both terms are scaled by pi so that light colors are irradiance, see ShadingModel
+		diffuse := kd.Modulate(albedo)
+		specular := f.Multiply(math.Pi * d * g / (4 * normalDotView * normalDotLight))
+
+		color = color.Add(diffuse.Add(specular).Modulate(radiance).Multiply(normalDotLight))
+	}
+
+	return color
+}
+
+// DistributionGGX is the Trowbridge-Reitz GGX density of microfacets aligned with the half
+// vector. The roughness is squared to give a perceptually linear response.
+func DistributionGGX(normalDotHalf, roughness float64) float64 {
+	alpha := roughness * roughness
+	alpha2 := alpha * alpha
+	nh := math.Max(0, normalDotHalf)
+
+	denom := nh*nh*(alpha2-1) + 1
+	return alpha2 / (math.Pi * denom * denom)
+}
+
+// GeometrySmith is the fraction of microfacets visible from both the light and the viewer,
+// using the Schlick-GGX approximation with the direct lighting remapping of roughness
+func GeometrySmith(normalDotView, normalDotLight, roughness float64) float64 {
+	k := (roughness + 1) * (roughness + 1) / 8
+
+	schlick := func(cosTheta float64) float64 {
+		cosTheta = math.Max(0, cosTheta)
+		return cosTheta / (cosTheta*(1-k) + k)
+	}
+
+	return schlick(normalDotView) * schlick(normalDotLight)
+}
+
+// FresnelSchlick approximates the reflectance at an angle given the reflectance at normal
+// incidence
+func FresnelSchlick(cosTheta float64, f0 mymath.Color3) mymath.Color3 {
+	weight := math.Pow(1-clamp01(cosTheta), 5)
+	return f0.Multiply(1 - weight).Add(mymath.Color3{R: weight, G: weight, B: weight})
+}
+
+func clamp01(x float64) float64 {
+	return math.Min(1, math.Max(0, x))
+}
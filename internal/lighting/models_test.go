@@ -0,0 +1,172 @@
+package lighting
+
+import (
+	"math"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+var testMaterial = Material{
+	Ambient:   mymath.Color3{R: 0.1, G: 0.1, B: 0.1},
+	Diffuse:   mymath.Color3{R: 0.5, G: 0.5, B: 0.5},
+	Specular:  mymath.Color3{R: 1, G: 1, B: 1},
+	Shininess: 2,
+}
+
+var white = mymath.Color3{R: 1, G: 1, B: 1}
+
+// fromNormal is a unit vector in the xz plane, angle radians from the +z normal towards +x
+func fromNormal(angle float64) mymath.Vector3 {
+	return mymath.Vector3{X: math.Sin(angle), Z: math.Cos(angle)}
+}
+
+// shade lights a point at the origin with normal +z by one white directional light coming from
+// toLight, seen from toEye
+func shade(model ShadingModel, material Material, toLight, toEye mymath.Vector3) mymath.Color3 {
+	light := NewDirectionalLight(toLight.Multiply(-1), white)
+	return model.Shade(mymath.Vector3{}, mymath.Vector3{Z: 1}, toEye.Multiply(10), material, []Light{light})
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestLambert(t *testing.T) {
+	tests := []struct {
+		name    string
+		toLight mymath.Vector3
+		want    float64
+	}{
+		{"head on", fromNormal(0), 0.1 + 0.5},
+		{"60 degrees", fromNormal(math.Pi / 3), 0.1 + 0.5*0.5},
+		{"grazing", fromNormal(math.Pi / 2), 0.1},
+		{"from behind", fromNormal(2 * math.Pi / 3), 0.1}, // n.l is clamped, not negative
+		{"straight behind", fromNormal(math.Pi), 0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shade(Lambert{}, testMaterial, tt.toLight, fromNormal(0))
+			if !near(got.R, tt.want) {
+				t.Errorf("got %v, want %v", got.R, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhong(t *testing.T) {
+	tests := []struct {
+		name      string
+		toLight   mymath.Vector3
+		toEye     mymath.Vector3
+		shininess float64
+		want      float64
+	}{
+		{"mirror direction", fromNormal(0), fromNormal(0), 2, 0.1 + 0.5 + 1},
+		{"light at 45 degrees", fromNormal(math.Pi / 4), fromNormal(0), 2, 0.1 + 0.5*math.Sqrt2/2 + 0.5},
+		{"eye on the reflection", fromNormal(math.Pi / 4), fromNormal(-math.Pi / 4), 2, 0.1 + 0.5*math.Sqrt2/2 + 1},
+		// The reflection points away from the eye, which would be NaN for a fractional power
+		{"reflection away from the eye", fromNormal(math.Pi / 3), fromNormal(math.Pi / 3), 0.5, 0.1 + 0.5*0.5},
+		{"light behind", fromNormal(math.Pi), fromNormal(0), 2, 0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			material := testMaterial
+			material.Shininess = tt.shininess
+			got := shade(Phong{}, material, tt.toLight, tt.toEye)
+			if !near(got.R, tt.want) {
+				t.Errorf("got %v, want %v", got.R, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlinnPhong(t *testing.T) {
+	tests := []struct {
+		name      string
+		toLight   mymath.Vector3
+		toEye     mymath.Vector3
+		shininess float64
+		want      float64
+	}{
+		{"half vector on the normal", fromNormal(0), fromNormal(0), 2, 0.1 + 0.5 + 1},
+		{"half vector at 30 degrees", fromNormal(math.Pi / 3), fromNormal(0), 2, 0.1 + 0.5*0.5 + 0.75},
+		{"symmetric", fromNormal(math.Pi / 4), fromNormal(-math.Pi / 4), 2, 0.1 + 0.5*math.Sqrt2/2 + 1},
+		// The eye is below the surface, so the half vector points away from the normal
+		{"half vector below the surface", fromNormal(math.Pi / 3), fromNormal(math.Pi * 0.9), 0.5, 0.1 + 0.5*0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			material := testMaterial
+			material.Shininess = tt.shininess
+			got := shade(BlinnPhong{}, material, tt.toLight, tt.toEye)
+			if !near(got.R, tt.want) {
+				t.Errorf("got %v, want %v", got.R, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistributionGGX(t *testing.T) {
+	tests := []struct {
+		normalDotHalf, roughness, want float64
+	}{
+		{1, 1, 1 / math.Pi}, // Fully rough: the same density in every direction
+		{0, 1, 1 / math.Pi},
+		{0.5, 1, 1 / math.Pi},
+		{1, 0.5, 16 / math.Pi}, // alpha^2 = 1/16, so the peak is 1 / (pi alpha^2)
+		{0, 0.5, 0.0625 / math.Pi},
+		{math.Sqrt2 / 2, 0.5, 0.0625 / (math.Pi * 0.53125 * 0.53125)},
+		{-0.5, 0.5, 0.0625 / math.Pi}, // Clamped to 0
+	}
+
+	for _, tt := range tests {
+		if got := DistributionGGX(tt.normalDotHalf, tt.roughness); !near(got, tt.want) {
+			t.Errorf("DistributionGGX(%v, %v) = %v, want %v", tt.normalDotHalf, tt.roughness, got, tt.want)
+		}
+	}
+}
+
+func TestGeometrySmith(t *testing.T) {
+	tests := []struct {
+		normalDotView, normalDotLight, roughness, want float64
+	}{
+		{1, 1, 1, 1},
+		{0.5, 1, 1, 2.0 / 3}, // k = 1/2: 0.5 / (0.5*0.5 + 0.5)
+		{0.5, 0.5, 1, 4.0 / 9},
+		{0.5, 0.5, 0, (0.5 / 0.5625) * (0.5 / 0.5625)}, // k = 1/8
+		{0, 1, 0.5, 0},
+		{-0.5, 1, 0.5, 0}, // Clamped to 0
+	}
+
+	for _, tt := range tests {
+		if got := GeometrySmith(tt.normalDotView, tt.normalDotLight, tt.roughness); !near(got, tt.want) {
+			t.Errorf("GeometrySmith(%v, %v, %v) = %v, want %v", tt.normalDotView, tt.normalDotLight, tt.roughness, got, tt.want)
+		}
+	}
+}
+
+func TestFresnelSchlick(t *testing.T) {
+	f0 := mymath.Color3{R: 0.04, G: 0.5, B: 1}
+
+	tests := []struct {
+		cosTheta float64
+		want     mymath.Color3
+	}{
+		{1, f0},                              // Normal incidence
+		{0, mymath.Color3{R: 1, G: 1, B: 1}}, // Grazing: everything reflects
+		{0.5, mymath.Color3{R: 0.07, G: 0.515625, B: 1}},
+		{-1, mymath.Color3{R: 1, G: 1, B: 1}}, // Clamped to grazing
+		{2, f0},                               // Clamped to normal incidence
+	}
+
+	for _, tt := range tests {
+		got := FresnelSchlick(tt.cosTheta, f0)
+		if !near(got.R, tt.want.R) || !near(got.G, tt.want.G) || !near(got.B, tt.want.B) {
+			t.Errorf("FresnelSchlick(%v) = %v, want %v", tt.cosTheta, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package graphicsmath
+
+import "math"
+
+// AABB is an axis aligned bounding box. A box with Min greater than Max is empty.
+type AABB struct {
+	Min Vector3
+	Max Vector3
+}
+
+// EmptyAABB contains nothing, and grows to fit the first point it is extended by
+func EmptyAABB() AABB {
+	inf := math.Inf(1)
+	return AABB{Min: Vector3{inf, inf, inf}, Max: Vector3{-inf, -inf, -inf}}
+}
+
+func AABBFromPoints(points []Vector3) AABB {
+	b := EmptyAABB()
+	for _, p := range points {
+		b = b.Extend(p)
+	}
+	return b
+}
+
+func (b AABB) Extend(p Vector3) AABB {
+	return AABB{
+		Min: Vector3{math.Min(b.Min.X, p.X), math.Min(b.Min.Y, p.Y), math.Min(b.Min.Z, p.Z)},
+		Max: Vector3{math.Max(b.Max.X, p.X), math.Max(b.Max.Y, p.Y), math.Max(b.Max.Z, p.Z)},
+	}
+}
+
+func (b AABB) IsEmpty() bool {
+	return b.Min.X > b.Max.X || b.Min.Y > b.Max.Y || b.Min.Z > b.Max.Z
+}
+
+func (b AABB) Center() Vector3 {
+	return b.Min.Add(b.Max).Multiply(0.5)
+}
+
+// Extents are the half sizes of the box along each axis
+func (b AABB) Extents() Vector3 {
+	return b.Max.Subtract(b.Min).Multiply(0.5)
+}
+
+func (b AABB) Contains(p Vector3) bool {
+	return p.X >= b.Min.X && p.X <= b.Max.X &&
+		p.Y >= b.Min.Y && p.Y <= b.Max.Y &&
+		p.Z >= b.Min.Z && p.Z <= b.Max.Z
+}
+
+func (b AABB) Intersects(o AABB) bool {
+	return b.Min.X <= o.Max.X && b.Max.X >= o.Min.X &&
+		b.Min.Y <= o.Max.Y && b.Max.Y >= o.Min.Y &&
+		b.Min.Z <= o.Max.Z && b.Max.Z >= o.Min.Z
+}
+
+// Transform returns the axis aligned box around b after an affine transform
+func (b AABB) Transform(m Mat4) AABB {
+	if b.IsEmpty() {
+		return b
+	}
+
+	center := m.TransformPoint(b.Center())
+	e := b.Extents()
+	extents := Vector3{
+		math.Abs(m[0][0])*e.X + math.Abs(m[0][1])*e.Y + math.Abs(m[0][2])*e.Z,
+		math.Abs(m[1][0])*e.X + math.Abs(m[1][1])*e.Y + math.Abs(m[1][2])*e.Z,
+		math.Abs(m[2][0])*e.X + math.Abs(m[2][1])*e.Y + math.Abs(m[2][2])*e.Z,
+	}
+
+	return AABB{Min: center.Subtract(extents), Max: center.Add(extents)}
+}
+
+func (b AABB) Classify(p Plane) int {
+	if b.IsEmpty() {
+		return Outside
+	}
+
+	e := b.Extents()
+	radius := e.X*math.Abs(p.Normal.X) + e.Y*math.Abs(p.Normal.Y) + e.Z*math.Abs(p.Normal.Z)
+	return classify(p.Distance(b.Center()), radius)
+}
+
+// OBB converts the box into an oriented box with the world axes
+func (b AABB) OBB() OBB {
+	return OBB{Center: b.Center(), Axes: [3]Vector3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}, HalfExtents: b.Extents()}
+}
+
+// Sphere is a bounding sphere
+type Sphere struct {
+	Center Vector3
+	Radius float64
+}
+
+// SphereFromPoints fits a sphere around points with Ritter's algorithm. It is usually within a
+// few percent of the smallest sphere, but a few shapes, such as a regular tetrahedron, come out
+// over 20% larger. No points gives a zero radius sphere at the origin.
+func SphereFromPoints(points []Vector3) Sphere {
+	if len(points) == 0 {
+		return Sphere{}
+	}
+
+	farthest := func(from Vector3) Vector3 {
+		best, bestDistance := from, -1.0
+		for _, p := range points {
+			if d := p.Subtract(from).Magnitude(); d > bestDistance {
+				best, bestDistance = p, d
+			}
+		}
+		return best
+	}
+
+	a := farthest(points[0])
+	b := farthest(a)
+	s := Sphere{Center: a.Add(b).Multiply(0.5), Radius: b.Subtract(a).Magnitude() / 2}
+
+	for _, p := range points {
+		d := p.Subtract(s.Center).Magnitude()
+		if d > s.Radius {
+			// Grow just enough to reach p, keeping the far side of the sphere in place
+			radius := (s.Radius + d) / 2
+			s.Center = s.Center.Add(p.Subtract(s.Center).Multiply((radius - s.Radius) / d))
+			s.Radius = radius
+		}
+	}
+
+	return s
+}
+
+func (s Sphere) Contains(p Vector3) bool {
+	return p.Subtract(s.Center).Magnitude() <= s.Radius
+}
+
+func (s Sphere) Intersects(o Sphere) bool {
+	return o.Center.Subtract(s.Center).Magnitude() <= s.Radius+o.Radius
+}
+
+// Transform returns a sphere around s after an affine transform. Non-uniform scale grows the
+// radius by the largest scale factor.
+func (s Sphere) Transform(m Mat4) Sphere {
+	basis := m.Mat3()
+	scale := math.Max(basis.Col(0).Magnitude(), math.Max(basis.Col(1).Magnitude(), basis.Col(2).Magnitude()))
+	return Sphere{Center: m.TransformPoint(s.Center), Radius: s.Radius * scale}
+}
+
+func (s Sphere) Classify(p Plane) int {
+	return classify(p.Distance(s.Center), s.Radius)
+}
+
+// OBB is an oriented bounding box: Axes are unit length and perpendicular, and the box spans
+// HalfExtents along each of them either side of Center
+type OBB struct {
+	Center      Vector3
+	Axes        [3]Vector3
+	HalfExtents Vector3
+}
+
+// OBBFromPoints orients the box along the principal axes of points. The fit is usually tight
+// for elongated shapes but is not guaranteed to be the smallest box.
+func OBBFromPoints(points []Vector3) OBB {
+	if len(points) == 0 {
+		return OBB{Axes: [3]Vector3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}}
+	}
+
+	var mean Vector3
+	for _, p := range points {
+		mean = mean.Add(p)
+	}
+	mean = mean.Multiply(1 / float64(len(points)))
+
+	var covariance Mat3
+	for _, p := range points {
+		d := p.Subtract(mean)
+		covariance = covariance.add(Mat3{
+			{d.X * d.X, d.X * d.Y, d.X * d.Z},
+			{d.Y * d.X, d.Y * d.Y, d.Y * d.Z},
+			{d.Z * d.X, d.Z * d.Y, d.Z * d.Z},
+		})
+	}
+
+	vectors := symmetricEigenvectors(covariance)
+	axes := [3]Vector3{vectors.Col(0), vectors.Col(1), vectors.Col(2)}
+
+	var low, high [3]float64
+	for i, axis := range axes {
+		low[i], high[i] = math.Inf(1), math.Inf(-1)
+		for _, p := range points {
+			d := p.Subtract(mean).Dot(axis)
+			low[i] = math.Min(low[i], d)
+			high[i] = math.Max(high[i], d)
+		}
+	}
+
+	center := mean
+	for i, axis := range axes {
+		center = center.Add(axis.Multiply((low[i] + high[i]) / 2))
+	}
+
+	return OBB{
+		Center:      center,
+		Axes:        axes,
+		HalfExtents: Vector3{(high[0] - low[0]) / 2, (high[1] - low[1]) / 2, (high[2] - low[2]) / 2},
+	}
+}
+
+func (b OBB) halfExtent(i int) float64 {
+	return [3]float64{b.HalfExtents.X, b.HalfExtents.Y, b.HalfExtents.Z}[i]
+}
+
+func (b OBB) Contains(p Vector3) bool {
+	d := p.Subtract(b.Center)
+	for i, axis := range b.Axes {
+		if math.Abs(d.Dot(axis)) > b.halfExtent(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// Transform returns the box after an affine transform without shear
+func (b OBB) Transform(m Mat4) OBB {
+	r := OBB{Center: m.TransformPoint(b.Center)}
+	var extents [3]float64
+
+	for i, axis := range b.Axes {
+		scaled := m.TransformDirection(axis)
+		length := scaled.Magnitude()
+		r.Axes[i], _ = scaled.NormalizeSafe()
+		extents[i] = b.halfExtent(i) * length
+	}
+
+	r.HalfExtents = Vector3{extents[0], extents[1], extents[2]}
+	return r
+}
+
+func (b OBB) Classify(p Plane) int {
+	radius := 0.0
+	for i, axis := range b.Axes {
+		radius += b.halfExtent(i) * math.Abs(p.Normal.Dot(axis))
+	}
+	return classify(p.Distance(b.Center), radius)
+}
+
+// classify compares the signed distance from a plane to the center of a volume with the
+// volume's extent towards the plane
+func classify(distance, radius float64) int {
+	switch {
+	case distance > radius:
+		return Inside
+	case distance < -radius:
+		return Outside
+	default:
+		return Intersecting
+	}
+}
+
+// IntersectAABB returns the distance at which the ray enters b, or 0 when it starts inside
+func (r Ray) IntersectAABB(b AABB) (float64, bool) {
+	if b.IsEmpty() {
+		return 0, false
+	}
+
+	return slabs(
+		[3]float64{r.Origin.X, r.Origin.Y, r.Origin.Z},
+		[3]float64{r.Direction.X, r.Direction.Y, r.Direction.Z},
+		[3]float64{b.Min.X, b.Min.Y, b.Min.Z},
+		[3]float64{b.Max.X, b.Max.Y, b.Max.Z},
+	)
+}
+
+// IntersectOBB returns the distance at which the ray enters b, or 0 when it starts inside
+func (r Ray) IntersectOBB(b OBB) (float64, bool) {
+	var origin, direction, low, high [3]float64
+	d := r.Origin.Subtract(b.Center)
+
+	for i, axis := range b.Axes {
+		origin[i] = d.Dot(axis)
+		direction[i] = r.Direction.Dot(axis)
+		low[i], high[i] = -b.halfExtent(i), b.halfExtent(i)
+	}
+
+	return slabs(origin, direction, low, high)
+}
+
+// slabs intersects a ray with a box given as the overlap of three pairs of parallel planes
+func slabs(origin, direction, low, high [3]float64) (float64, bool) {
+	near, far := 0.0, math.Inf(1)
+
+	for i := range 3 {
+		if math.Abs(direction[i]) < Epsilon {
+			// Parallel to this pair of planes: either always between them or never
+			if origin[i] < low[i] || origin[i] > high[i] {
+				return 0, false
+			}
+			continue
+		}
+
+		t1 := (low[i] - origin[i]) / direction[i]
+		t2 := (high[i] - origin[i]) / direction[i]
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+
+		near = math.Max(near, t1)
+		far = math.Min(far, t2)
+		if near > far {
+			return 0, false
+		}
+	}
+
+	return near, true
+}
+
+// IntersectSphere returns the distance at which the ray enters s, or 0 when it starts inside
+func (r Ray) IntersectSphere(s Sphere) (float64, bool) {
+	offset := r.Origin.Subtract(s.Center)
+	a := r.Direction.Dot(r.Direction)
+	b := 2 * r.Direction.Dot(offset)
+	c := offset.Dot(offset) - s.Radius*s.Radius
+
+	if a < Epsilon {
+		return 0, false
+	}
+
+	discriminant := b*b - 4*a*c
+	if discriminant < 0 {
+		return 0, false
+	}
+
+	root := math.Sqrt(discriminant)
+	t0 := (-b - root) / (2 * a)
+	t1 := (-b + root) / (2 * a)
+
+	if t1 < 0 {
+		return 0, false
+	}
+	return math.Max(t0, 0), true
+}
+
+func (m Mat3) add(n Mat3) Mat3 {
+	for row := range 3 {
+		for col := range 3 {
+			m[row][col] += n[row][col]
+		}
+	}
+	return m
+}
+
+// symmetricEigenvectors diagonalizes a symmetric matrix with Jacobi rotations and returns its
+// eigenvectors as the columns of an orthonormal matrix
+func symmetricEigenvectors(a Mat3) Mat3 {
+	vectors := Ident3()
+
+	for range 50 {
+		if a[0][1] == 0 && a[0][2] == 0 && a[1][2] == 0 {
+			break
+		}
+
+		for p := 0; p < 2; p++ {
+			for q := p + 1; q < 3; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+
+				// Rotate in the pq plane by the angle that zeroes a[p][q]
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				rotation := Ident3()
+				rotation[p][p], rotation[q][q] = c, c
+				rotation[p][q], rotation[q][p] = s, -s
+
+				a = rotation.Transpose().Mul(a).Mul(rotation)
+				a[p][q], a[q][p] = 0, 0
+				vectors = vectors.Mul(rotation)
+			}
+		}
+	}
+
+	return vectors
+}
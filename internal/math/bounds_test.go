@@ -0,0 +1,296 @@
+package graphicsmath
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSymmetricEigenvectors(t *testing.T) {
+	tests := []struct {
+		name string
+		a    Mat3
+	}{
+		{"diagonal", Mat3{{3, 0, 0}, {0, 1, 0}, {0, 0, 2}}},
+		{"one pair", Mat3{{2, 1, 0}, {1, 2, 0}, {0, 0, 5}}},
+		{"full", Mat3{{4, 1, -2}, {1, 2, 0.5}, {-2, 0.5, 3}}},
+		{"repeated eigenvalues", Mat3{{2, 1, 1}, {1, 2, 1}, {1, 1, 2}}},
+		{"zero", Mat3{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := symmetricEigenvectors(tt.a)
+			if !mat3Near(v.Transpose().Mul(v), Ident3()) {
+				t.Errorf("eigenvectors %v are not orthonormal", v)
+			}
+
+			d := v.Transpose().Mul(tt.a).Mul(v)
+			for row := range 3 {
+				for col := range 3 {
+					if row != col && !near(d[row][col], 0) {
+						t.Errorf("%v is not diagonal", d)
+					}
+				}
+			}
+			for i := range 3 {
+				if got, want := tt.a.MulVector3(v.Col(i)), v.Col(i).Multiply(d[i][i]); !vector3Near(got, want) {
+					t.Errorf("column %d: A v is %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// corners are the eight corners of the box spanning half either side of centre along axes
+func corners(center Vector3, axes Mat4, half Vector3) []Vector3 {
+	var points []Vector3
+	for _, x := range []float64{-half.X, half.X} {
+		for _, y := range []float64{-half.Y, half.Y} {
+			for _, z := range []float64{-half.Z, half.Z} {
+				points = append(points, center.Add(axes.TransformDirection(Vector3{x, y, z})))
+			}
+		}
+	}
+	return points
+}
+
+func TestOBBFromPoints(t *testing.T) {
+	rotation := Rotate(Vector3{1, 2, 3}, 0.7)
+	center := Vector3{5, -2, 1}
+	half := Vector3{4, 2, 1}
+	points := corners(center, rotation, half)
+
+	b := OBBFromPoints(points)
+	if !vector3Near(b.Center, center) {
+		t.Errorf("center %v, want %v", b.Center, center)
+	}
+
+	// The axes come out in any order and either way round
+	want := map[float64]Vector3{4: rotation.Col(0).Vec3(), 2: rotation.Col(1).Vec3(), 1: rotation.Col(2).Vec3()}
+	for i, axis := range b.Axes {
+		if !near(axis.Magnitude(), 1) {
+			t.Errorf("axis %d has length %v", i, axis.Magnitude())
+		}
+		extent := math.Round(b.halfExtent(i)*1e6) / 1e6
+		w, ok := want[extent]
+		if !ok {
+			t.Errorf("axis %d has half extent %v", i, b.halfExtent(i))
+			continue
+		}
+		if !near(math.Abs(axis.Dot(w)), 1) {
+			t.Errorf("axis %d is %v, want along %v", i, axis, w)
+		}
+		delete(want, extent)
+	}
+
+	for _, p := range points {
+		if !b.Contains(p.Add(p.Subtract(center).Multiply(-1e-9))) {
+			t.Errorf("%v is outside the box", p)
+		}
+	}
+	if b.Contains(center.Add(rotation.Col(0).Vec3().Multiply(4.1))) {
+		t.Error("the box reaches past the points")
+	}
+}
+
+func TestOBBFromFewPoints(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []Vector3
+		center Vector3
+		volume float64
+	}{
+		{"none", nil, Vector3{}, 0},
+		{"one", []Vector3{{1, 2, 3}}, Vector3{1, 2, 3}, 0},
+		{"two", []Vector3{{0, 0, 0}, {2, 2, 0}}, Vector3{1, 1, 0}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := OBBFromPoints(tt.points)
+			if !vector3Near(b.Center, tt.center) {
+				t.Errorf("center %v, want %v", b.Center, tt.center)
+			}
+			if v := b.HalfExtents.X * b.HalfExtents.Y * b.HalfExtents.Z; !near(v, tt.volume) {
+				t.Errorf("volume %v, want %v", v, tt.volume)
+			}
+			if !mat3Near(Mat3FromRows(b.Axes[0], b.Axes[1], b.Axes[2]).Mul(Mat3FromRows(b.Axes[0], b.Axes[1], b.Axes[2]).Transpose()), Ident3()) {
+				t.Errorf("axes %v are not orthonormal", b.Axes)
+			}
+			for _, p := range tt.points {
+				if !b.Contains(p) {
+					t.Errorf("%v is outside the box", p)
+				}
+			}
+		})
+	}
+}
+
+func TestSphereFromPoints(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	var cloud []Vector3
+	for range 500 {
+		cloud = append(cloud, Vector3{random.NormFloat64(), random.NormFloat64() * 3, random.NormFloat64()})
+	}
+
+	tests := []struct {
+		name    string
+		points  []Vector3
+		minimum float64 // Radius of the smallest sphere, when known
+		slack   float64 // How much larger Ritter's sphere may be
+	}{
+		{"one point", []Vector3{{1, 2, 3}}, 0, 0},
+		{"two points", []Vector3{{1, 0, 0}, {-1, 0, 0}}, 1, 0},
+		{"cube", corners(Vector3{1, 1, 1}, Ident4(), Vector3{1, 1, 1}), math.Sqrt(3), 0.05},
+		{"regular tetrahedron", []Vector3{{1, 1, 1}, {1, -1, -1}, {-1, 1, -1}, {-1, -1, 1}}, math.Sqrt(3), 0.25},
+		{"cloud", cloud, 0, math.Inf(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := SphereFromPoints(tt.points)
+			for _, p := range tt.points {
+				if d := p.Subtract(s.Center).Magnitude(); d > s.Radius+1e-9 {
+					t.Errorf("%v is %v from the center, past the radius %v", p, d, s.Radius)
+				}
+			}
+			if s.Radius < tt.minimum-1e-9 || s.Radius > tt.minimum*(1+tt.slack)+1e-9 {
+				t.Errorf("radius %v, want no more than %v larger than %v", s.Radius, tt.slack, tt.minimum)
+			}
+		})
+	}
+
+	if s := SphereFromPoints(nil); s != (Sphere{}) {
+		t.Errorf("no points gave %v", s)
+	}
+}
+
+func TestAABBTransform(t *testing.T) {
+	b := AABB{Min: Vector3{-1, -2, -3}, Max: Vector3{1, 2, 3}}
+
+	tests := []struct {
+		name string
+		m    Mat4
+		want AABB
+	}{
+		{"identity", Ident4(), b},
+		{"translate", Translate(1, 1, 1), AABB{Min: Vector3{0, -1, -2}, Max: Vector3{2, 3, 4}}},
+		{"quarter turn", RotateZ(math.Pi / 2), AABB{Min: Vector3{-2, -1, -3}, Max: Vector3{2, 1, 3}}},
+		{"mirror", Scale(-2, 1, 1), AABB{Min: Vector3{-2, -2, -3}, Max: Vector3{2, 2, 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := b.Transform(tt.m)
+			if !vector3Near(got.Min, tt.want.Min) || !vector3Near(got.Max, tt.want.Max) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := EmptyAABB().Transform(Translate(1, 1, 1)); !got.IsEmpty() {
+		t.Errorf("empty box became %v", got)
+	}
+}
+
+func TestIntersectAABB(t *testing.T) {
+	box := AABB{Min: Vector3{-1, -1, -1}, Max: Vector3{1, 1, 1}}
+
+	tests := []struct {
+		name  string
+		ray   Ray
+		box   AABB
+		want  float64
+		found bool
+	}{
+		{"straight on", Ray{Vector3{-5, 0, 0}, Vector3{1, 0, 0}}, box, 4, true},
+		{"diagonal", Ray{Vector3{-3, -3, -3}, Vector3{1, 1, 1}}, box, 2, true},
+		{"unscaled direction", Ray{Vector3{0, 0, 5}, Vector3{0, 0, -2}}, box, 2, true},
+		{"pointing away", Ray{Vector3{-5, 0, 0}, Vector3{-1, 0, 0}}, box, 0, false},
+		{"passing by", Ray{Vector3{-5, 2, 0}, Vector3{1, 0, 0}}, box, 0, false},
+		{"missing a corner", Ray{Vector3{-3, 0, 0}, Vector3{1, 1.1, 0}}, box, 0, false},
+		{"parallel between the slabs", Ray{Vector3{-5, 0.5, -0.5}, Vector3{1, 0, 0}}, box, 4, true},
+		{"parallel outside a slab", Ray{Vector3{-5, 0.5, 1.5}, Vector3{1, 0, 0}}, box, 0, false},
+		{"parallel along a face", Ray{Vector3{-5, 1, 0}, Vector3{1, 0, 0}}, box, 4, true},
+		{"starting inside", Ray{Vector3{0.5, 0, 0}, Vector3{1, 0, 0}}, box, 0, true},
+		{"starting on a face", Ray{Vector3{-1, 0, 0}, Vector3{1, 0, 0}}, box, 0, true},
+		{"zero direction inside", Ray{Vector3{0, 0, 0}, Vector3{}}, box, 0, true},
+		{"zero direction outside", Ray{Vector3{5, 0, 0}, Vector3{}}, box, 0, false},
+		{"empty box", Ray{Vector3{-5, 0, 0}, Vector3{1, 0, 0}}, EmptyAABB(), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := tt.ray.IntersectAABB(tt.box)
+			if found != tt.found || found && !near(got, tt.want) {
+				t.Errorf("got %v, %v, want %v, %v", got, found, tt.want, tt.found)
+			}
+
+			// An OBB with the world axes gives the same answer
+			if tt.box.IsEmpty() {
+				return
+			}
+			got, found = tt.ray.IntersectOBB(tt.box.OBB())
+			if found != tt.found || found && !near(got, tt.want) {
+				t.Errorf("as an OBB got %v, %v, want %v, %v", got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestIntersectOBB(t *testing.T) {
+	// A 2x2x2 cube turned 45 degrees about z, so its corner points along x
+	b := AABB{Min: Vector3{-1, -1, -1}, Max: Vector3{1, 1, 1}}.OBB().Transform(RotateZ(math.Pi / 4))
+
+	tests := []struct {
+		name  string
+		ray   Ray
+		want  float64
+		found bool
+	}{
+		{"to the corner", Ray{Vector3{-5, 0, 0}, Vector3{1, 0, 0}}, 5 - math.Sqrt2, true},
+		{"past the corner", Ray{Vector3{-5, 1.5, 0}, Vector3{1, 0, 0}}, 0, false},
+		{"inside", Ray{Vector3{}, Vector3{0, 0, 1}}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := tt.ray.IntersectOBB(b)
+			if found != tt.found || found && !near(got, tt.want) {
+				t.Errorf("got %v, %v, want %v, %v", got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestIntersectSphere(t *testing.T) {
+	s := Sphere{Center: Vector3{0, 0, -10}, Radius: 2}
+
+	tests := []struct {
+		name  string
+		ray   Ray
+		want  float64
+		found bool
+	}{
+		{"straight on", Ray{Vector3{}, Vector3{0, 0, -1}}, 8, true},
+		{"unscaled direction", Ray{Vector3{}, Vector3{0, 0, -4}}, 2, true},
+		{"off centre", Ray{Vector3{0, math.Sqrt(3), 0}, Vector3{0, 0, -1}}, 9, true},
+		{"grazing", Ray{Vector3{0, 2, 0}, Vector3{0, 0, -1}}, 10, true},
+		{"passing by", Ray{Vector3{0, 2.1, 0}, Vector3{0, 0, -1}}, 0, false},
+		{"pointing away", Ray{Vector3{}, Vector3{0, 0, 1}}, 0, false},
+		{"starting inside", Ray{Vector3{0, 0, -9}, Vector3{0, 0, 1}}, 0, true},
+		{"starting at the centre", Ray{Vector3{0, 0, -10}, Vector3{1, 0, 0}}, 0, true},
+		{"already past", Ray{Vector3{0, 0, -13}, Vector3{0, 0, -1}}, 0, false},
+		{"zero direction", Ray{Vector3{0, 0, -10}, Vector3{}}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := tt.ray.IntersectSphere(s)
+			if found != tt.found || found && !near(got, tt.want) {
+				t.Errorf("got %v, %v, want %v, %v", got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
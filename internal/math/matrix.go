@@ -0,0 +1,366 @@
+package graphicsmath
+
+import "math"
+
+// Mat3 is a row major 3x3 matrix: m[row][column]
+type Mat3 [3][3]float64
+
+// Mat4 is a row major 4x4 matrix: m[row][column]. Vectors are columns, so transforms
+// compose right to left: a.Mul(b) applies b first.
+type Mat4 [4][4]float64
+
+func Ident3() Mat3 {
+	return Mat3{
+		{1, 0, 0},
+		{0, 1, 0},
+		{0, 0, 1},
+	}
+}
+
+func Mat3FromRows(r0, r1, r2 Vector3) Mat3 {
+	return Mat3{
+		{r0.X, r0.Y, r0.Z},
+		{r1.X, r1.Y, r1.Z},
+		{r2.X, r2.Y, r2.Z},
+	}
+}
+
+func (m Mat3) Row(i int) Vector3 {
+	return Vector3{m[i][0], m[i][1], m[i][2]}
+}
+
+func (m Mat3) Col(i int) Vector3 {
+	return Vector3{m[0][i], m[1][i], m[2][i]}
+}
+
+func (m Mat3) Mul(n Mat3) Mat3 {
+	var r Mat3
+	for row := range 3 {
+		for col := range 3 {
+			for k := range 3 {
+				r[row][col] += m[row][k] * n[k][col]
+			}
+		}
+	}
+	return r
+}
+
+func (m Mat3) MulVector3(v Vector3) Vector3 {
+	return Vector3{
+		m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z,
+		m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z,
+		m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z,
+	}
+}
+
+func (m Mat3) Transpose() Mat3 {
+	var r Mat3
+	for row := range 3 {
+		for col := range 3 {
+			r[row][col] = m[col][row]
+		}
+	}
+	return r
+}
+
+func (m Mat3) Determinant() float64 {
+	return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+}
+
+// Inverse returns false for singular matrices, along with the zero matrix
+func (m Mat3) Inverse() (Mat3, bool) {
+	det := m.Determinant()
+	if det == 0 {
+		return Mat3{}, false
+	}
+
+	inv := 1 / det
+	return Mat3{
+		{
+			(m[1][1]*m[2][2] - m[1][2]*m[2][1]) * inv,
+			(m[0][2]*m[2][1] - m[0][1]*m[2][2]) * inv,
+			(m[0][1]*m[1][2] - m[0][2]*m[1][1]) * inv,
+		},
+		{
+			(m[1][2]*m[2][0] - m[1][0]*m[2][2]) * inv,
+			(m[0][0]*m[2][2] - m[0][2]*m[2][0]) * inv,
+			(m[0][2]*m[1][0] - m[0][0]*m[1][2]) * inv,
+		},
+		{
+			(m[1][0]*m[2][1] - m[1][1]*m[2][0]) * inv,
+			(m[0][1]*m[2][0] - m[0][0]*m[2][1]) * inv,
+			(m[0][0]*m[1][1] - m[0][1]*m[1][0]) * inv,
+		},
+	}, true
+}
+
+// Mat4 embeds m as the upper left of an otherwise identity matrix
+func (m Mat3) Mat4() Mat4 {
+	return Mat4{
+		{m[0][0], m[0][1], m[0][2], 0},
+		{m[1][0], m[1][1], m[1][2], 0},
+		{m[2][0], m[2][1], m[2][2], 0},
+		{0, 0, 0, 1},
+	}
+}
+
+func Ident4() Mat4 {
+	return Mat4{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+func Mat4FromRows(r0, r1, r2, r3 Vector4) Mat4 {
+	return Mat4{
+		{r0.X, r0.Y, r0.Z, r0.W},
+		{r1.X, r1.Y, r1.Z, r1.W},
+		{r2.X, r2.Y, r2.Z, r2.W},
+		{r3.X, r3.Y, r3.Z, r3.W},
+	}
+}
+
+func (m Mat4) Row(i int) Vector4 {
+	return Vector4{m[i][0], m[i][1], m[i][2], m[i][3]}
+}
+
+func (m Mat4) Col(i int) Vector4 {
+	return Vector4{m[0][i], m[1][i], m[2][i], m[3][i]}
+}
+
+func (m Mat4) Mul(n Mat4) Mat4 {
+	var r Mat4
+	for row := range 4 {
+		for col := range 4 {
+			for k := range 4 {
+				r[row][col] += m[row][k] * n[k][col]
+			}
+		}
+	}
+	return r
+}
+
+func (m Mat4) MulVector4(v Vector4) Vector4 {
+	return Vector4{
+		m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z + m[0][3]*v.W,
+		m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z + m[1][3]*v.W,
+		m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z + m[2][3]*v.W,
+		m[3][0]*v.X + m[3][1]*v.Y + m[3][2]*v.Z + m[3][3]*v.W,
+	}
+}
+
+// TransformPoint applies m to p with w = 1. There is no perspective divide.
+func (m Mat4) TransformPoint(p Vector3) Vector3 {
+	return m.MulVector4(p.Vec4(1)).Vec3()
+}
+
+// TransformDirection applies m to d with w = 0, ignoring translation
+func (m Mat4) TransformDirection(d Vector3) Vector3 {
+	return m.MulVector4(d.Vec4(0)).Vec3()
+}
+
+func (m Mat4) Transpose() Mat4 {
+	var r Mat4
+	for row := range 4 {
+		for col := range 4 {
+			r[row][col] = m[col][row]
+		}
+	}
+	return r
+}
+
+// Mat3 is the upper left 3x3 of m: its rotation and scale without translation
+func (m Mat4) Mat3() Mat3 {
+	return Mat3{
+		{m[0][0], m[0][1], m[0][2]},
+		{m[1][0], m[1][1], m[1][2]},
+		{m[2][0], m[2][1], m[2][2]},
+	}
+}
+
+// minor is the determinant of m without the given row and column
+func (m Mat4) minor(row, col int) float64 {
+	var sub Mat3
+	r := 0
+	for i := range 4 {
+		if i == row {
+			continue
+		}
+		c := 0
+		for j := range 4 {
+			if j == col {
+				continue
+			}
+			sub[r][c] = m[i][j]
+			c++
+		}
+		r++
+	}
+	return sub.Determinant()
+}
+
+func (m Mat4) Determinant() float64 {
+	det := 0.0
+	sign := 1.0
+	for col := range 4 {
+		det += sign * m[0][col] * m.minor(0, col)
+		sign = -sign
+	}
+	return det
+}
+
+// Inverse returns false for singular matrices, along with the zero matrix
+func (m Mat4) Inverse() (Mat4, bool) {
+	det := m.Determinant()
+	if det == 0 {
+		return Mat4{}, false
+	}
+
+	// The inverse is the transposed matrix of cofactors divided by the determinant
+	var r Mat4
+	for row := range 4 {
+		for col := range 4 {
+			cofactor := m.minor(row, col)
+			if (row+col)%2 == 1 {
+				cofactor = -cofactor
+			}
+			r[col][row] = cofactor / det
+		}
+	}
+	return r, true
+}
+
+// NormalMatrix transforms surface normals so they stay perpendicular under non-uniform
+// scale: the inverse transpose of the upper 3x3. Singular matrices return false.
+func (m Mat4) NormalMatrix() (Mat3, bool) {
+	inv, ok := m.Mat3().Inverse()
+	return inv.Transpose(), ok
+}
+
+func Translate(x, y, z float64) Mat4 {
+	return Mat4{
+		{1, 0, 0, x},
+		{0, 1, 0, y},
+		{0, 0, 1, z},
+		{0, 0, 0, 1},
+	}
+}
+
+func Scale(x, y, z float64) Mat4 {
+	return Mat4{
+		{x, 0, 0, 0},
+		{0, y, 0, 0},
+		{0, 0, z, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Shear slides each axis along the others: x' = x + xy*y + xz*z, y' = yx*x + y + yz*z and
+// z' = zx*x + zy*y + z
+func Shear(xy, xz, yx, yz, zx, zy float64) Mat4 {
+	return Mat4{
+		{1, xy, xz, 0},
+		{yx, 1, yz, 0},
+		{zx, zy, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// RotateX turns counter-clockwise about the x axis, looking down the axis towards the origin
+func RotateX(angle float64) Mat4 {
+	c, s := math.Cos(angle), math.Sin(angle)
+	return Mat4{
+		{1, 0, 0, 0},
+		{0, c, -s, 0},
+		{0, s, c, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+func RotateY(angle float64) Mat4 {
+	c, s := math.Cos(angle), math.Sin(angle)
+	return Mat4{
+		{c, 0, s, 0},
+		{0, 1, 0, 0},
+		{-s, 0, c, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+func RotateZ(angle float64) Mat4 {
+	c, s := math.Cos(angle), math.Sin(angle)
+	return Mat4{
+		{c, -s, 0, 0},
+		{s, c, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Rotate turns counter-clockwise about an arbitrary axis (Rodrigues' formula). The axis does
+// not need to be unit length; a zero axis gives the identity.
+func Rotate(axis Vector3, angle float64) Mat4 {
+	length := axis.Magnitude()
+	if length == 0 {
+		return Ident4()
+	}
+
+	a := axis.Multiply(1 / length)
+	c, s := math.Cos(angle), math.Sin(angle)
+	t := 1 - c
+
+	return Mat4{
+		{t*a.X*a.X + c, t*a.X*a.Y - s*a.Z, t*a.X*a.Z + s*a.Y, 0},
+		{t*a.X*a.Y + s*a.Z, t*a.Y*a.Y + c, t*a.Y*a.Z - s*a.X, 0},
+		{t*a.X*a.Z - s*a.Y, t*a.Y*a.Z + s*a.X, t*a.Z*a.Z + c, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// LookAt is a right handed view matrix: the camera sits at eye, looks at target down its
+// negative z axis, with up pointing along positive y
+func LookAt(eye, target, up Vector3) Mat4 {
+	forward := target.Subtract(eye).Normalize()
+	right := forward.Cross(up).Normalize()
+	trueUp := right.Cross(forward)
+
+	return Mat4{
+		{right.X, right.Y, right.Z, -right.Dot(eye)},
+		{trueUp.X, trueUp.Y, trueUp.Z, -trueUp.Dot(eye)},
+		{-forward.X, -forward.Y, -forward.Z, forward.Dot(eye)},
+		{0, 0, 0, 1},
+	}
+}
+
+// Frustum maps the view volume between the near and far planes (positive distances in front
+// of a camera looking down negative z) to clip space, with NDC z from -1 (near) to 1 (far)
+func Frustum(left, right, bottom, top, near, far float64) Mat4 {
+	return Mat4{
+		{2 * near / (right - left), 0, (right + left) / (right - left), 0},
+		{0, 2 * near / (top - bottom), (top + bottom) / (top - bottom), 0},
+		{0, 0, -(far + near) / (far - near), -2 * far * near / (far - near)},
+		{0, 0, -1, 0},
+	}
+}
+
+// Perspective is a symmetric Frustum from a vertical field of view in radians
+func Perspective(fovy, aspect, near, far float64) Mat4 {
+	top := near * math.Tan(fovy/2)
+	right := top * aspect
+	return Frustum(-right, right, -top, top, near, far)
+}
+
+// Ortho maps the box to NDC without perspective. Like Frustum, near and far are distances in
+// front of a camera looking down negative z.
+func Ortho(left, right, bottom, top, near, far float64) Mat4 {
+	return Mat4{
+		{2 / (right - left), 0, 0, -(right + left) / (right - left)},
+		{0, 2 / (top - bottom), 0, -(top + bottom) / (top - bottom)},
+		{0, 0, -2 / (far - near), -(far + near) / (far - near)},
+		{0, 0, 0, 1},
+	}
+}
@@ -0,0 +1,389 @@
+package graphicsmath
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func vector3Near(a, b Vector3) bool {
+	return near(a.X, b.X) && near(a.Y, b.Y) && near(a.Z, b.Z)
+}
+
+func mat3Near(a, b Mat3) bool {
+	for row := range 3 {
+		for col := range 3 {
+			if !near(a[row][col], b[row][col]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func mat4Near(a, b Mat4) bool {
+	for row := range 4 {
+		for col := range 4 {
+			if !near(a[row][col], b[row][col]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// counting is singular: every row is the one before plus {4, 4, 4, 4}
+var counting = Mat4{
+	{1, 2, 3, 4},
+	{5, 6, 7, 8},
+	{9, 10, 11, 12},
+	{13, 14, 15, 16},
+}
+
+// general has no special structure and an inverse of whole numbers
+var general = Mat4{
+	{2, 0, 0, 1},
+	{0, 3, 0, 0},
+	{0, 0, 4, 0},
+	{1, 0, 0, 1},
+}
+
+func TestMat4Mul(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Mat4
+		want Mat4
+	}{
+		{"identity", counting, Ident4(), counting},
+		{"identity first", Ident4(), counting, counting},
+		{"squared", counting, counting, Mat4{
+			{90, 100, 110, 120},
+			{202, 228, 254, 280},
+			{314, 356, 398, 440},
+			{426, 484, 542, 600},
+		}},
+		{"translations add", Translate(1, 2, 3), Translate(4, 5, 6), Translate(5, 7, 9)},
+		{"scales multiply", Scale(2, 3, 4), Scale(5, 6, 7), Scale(10, 18, 28)},
+		{"quarter turns", RotateZ(math.Pi / 2), RotateZ(math.Pi / 2), RotateZ(math.Pi)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Mul(tt.b); !mat4Near(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMat4MulOrder(t *testing.T) {
+	// a.Mul(b) applies b first
+	p := Vector3{1, 1, 1}
+	if got := Translate(1, 2, 3).Mul(Scale(2, 2, 2)).TransformPoint(p); !vector3Near(got, Vector3{3, 4, 5}) {
+		t.Errorf("scale then translate: got %v", got)
+	}
+	if got := Scale(2, 2, 2).Mul(Translate(1, 2, 3)).TransformPoint(p); !vector3Near(got, Vector3{4, 6, 8}) {
+		t.Errorf("translate then scale: got %v", got)
+	}
+	if got := Translate(1, 2, 3).TransformDirection(p); !vector3Near(got, p) {
+		t.Errorf("directions are not translated: got %v", got)
+	}
+}
+
+func TestMat4Determinant(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Mat4
+		want float64
+	}{
+		{"identity", Ident4(), 1},
+		{"scale", Scale(2, 3, 4), 24},
+		{"mirror", Scale(-1, 1, 1), -1},
+		{"translation", Translate(5, -6, 7), 1},
+		{"rotation", Rotate(Vector3{1, 2, 3}, 0.7), 1},
+		{"shear", Shear(1, 2, 3, 4, 5, 6), 20},
+		{"general", general, 12},
+		{"singular", counting, 0},
+		{"flattened", Scale(1, 0, 1), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.Determinant(); !near(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMat4Inverse(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Mat4
+		want Mat4
+	}{
+		{"identity", Ident4(), Ident4()},
+		{"translation", Translate(1, -2, 3), Translate(-1, 2, -3)},
+		{"scale", Scale(2, 4, -8), Scale(0.5, 0.25, -0.125)},
+		{"rotation", RotateX(0.3), RotateX(-0.3)},
+		{"general", general, Mat4{
+			{1, 0, 0, -1},
+			{0, 1.0 / 3, 0, 0},
+			{0, 0, 0.25, 0},
+			{-1, 0, 0, 2},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.m.Inverse()
+			if !ok {
+				t.Fatal("reported singular")
+			}
+			if !mat4Near(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if product := tt.m.Mul(got); !mat4Near(product, Ident4()) {
+				t.Errorf("m * inverse = %v, want the identity", product)
+			}
+			if product := got.Mul(tt.m); !mat4Near(product, Ident4()) {
+				t.Errorf("inverse * m = %v, want the identity", product)
+			}
+		})
+	}
+}
+
+func TestMat4InverseComposed(t *testing.T) {
+	m := Translate(1, 2, 3).Mul(Rotate(Vector3{1, -1, 2}, 1.2)).Mul(Scale(2, 3, 0.5)).Mul(Shear(0.1, 0, 0.2, 0, 0, 0.3))
+	inverse, ok := m.Inverse()
+	if !ok {
+		t.Fatal("reported singular")
+	}
+	if product := m.Mul(inverse); !mat4Near(product, Ident4()) {
+		t.Errorf("m * inverse = %v, want the identity", product)
+	}
+}
+
+func TestMat4InverseSingular(t *testing.T) {
+	for _, m := range []Mat4{counting, Scale(1, 0, 1), {}} {
+		if got, ok := m.Inverse(); ok || got != (Mat4{}) {
+			t.Errorf("Inverse(%v) = %v, %v, want the zero matrix and false", m, got, ok)
+		}
+	}
+}
+
+func TestMat4Transpose(t *testing.T) {
+	want := Mat4{
+		{1, 5, 9, 13},
+		{2, 6, 10, 14},
+		{3, 7, 11, 15},
+		{4, 8, 12, 16},
+	}
+	if got := counting.Transpose(); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := counting.Transpose().Transpose(); got != counting {
+		t.Errorf("transposing twice: got %v", got)
+	}
+
+	// The transpose of a product is the product of the transposes the other way round
+	if a, b := counting.Mul(general).Transpose(), general.Transpose().Mul(counting.Transpose()); !mat4Near(a, b) {
+		t.Errorf("(AB)^T = %v, B^T A^T = %v", a, b)
+	}
+
+	// A rotation's transpose is its inverse
+	r := Rotate(Vector3{2, 1, -1}, 2)
+	if product := r.Mul(r.Transpose()); !mat4Near(product, Ident4()) {
+		t.Errorf("R R^T = %v, want the identity", product)
+	}
+}
+
+func TestLookAt(t *testing.T) {
+	tests := []struct {
+		name              string
+		eye, target, up   Vector3
+		point, wantInView Vector3
+	}{
+		{"target in front", Vector3{0, 0, 5}, Vector3{}, Vector3{0, 1, 0}, Vector3{}, Vector3{0, 0, -5}},
+		{"eye at the origin of view space", Vector3{0, 0, 5}, Vector3{}, Vector3{0, 1, 0}, Vector3{0, 0, 5}, Vector3{}},
+		{"right is +x", Vector3{0, 0, 5}, Vector3{}, Vector3{0, 1, 0}, Vector3{1, 0, 5}, Vector3{1, 0, 0}},
+		{"up is +y", Vector3{0, 0, 5}, Vector3{}, Vector3{0, 1, 0}, Vector3{0, 1, 5}, Vector3{0, 1, 0}},
+		{"looking down -x", Vector3{5, 0, 0}, Vector3{}, Vector3{0, 1, 0}, Vector3{0, 0, -1}, Vector3{1, 0, -5}},
+		{"up is straightened", Vector3{0, 0, 5}, Vector3{}, Vector3{0, 1, 1}, Vector3{0, 1, 5}, Vector3{0, 1, 0}},
+		{"looking down from above", Vector3{0, 10, 0}, Vector3{}, Vector3{0, 0, -1}, Vector3{0, 0, -1}, Vector3{0, 1, -10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := LookAt(tt.eye, tt.target, tt.up)
+			if got := view.TransformPoint(tt.point); !vector3Near(got, tt.wantInView) {
+				t.Errorf("got %v, want %v", got, tt.wantInView)
+			}
+		})
+	}
+}
+
+// ndc projects a view space point and divides by w
+func ndc(projection Mat4, p Vector3) Vector3 {
+	clip := projection.MulVector4(p.Vec4(1))
+	return clip.Vec3().Multiply(1 / clip.W)
+}
+
+func TestPerspective(t *testing.T) {
+	// 90 degrees, so the frustum is as wide as it is deep
+	projection := Perspective(math.Pi/2, 2, 1, 10)
+
+	tests := []struct {
+		name  string
+		point Vector3
+		want  Vector3
+	}{
+		{"near centre", Vector3{0, 0, -1}, Vector3{0, 0, -1}},
+		{"far centre", Vector3{0, 0, -10}, Vector3{0, 0, 1}},
+		{"near top right", Vector3{2, 1, -1}, Vector3{1, 1, -1}},
+		{"far bottom left", Vector3{-20, -10, -10}, Vector3{-1, -1, 1}},
+		{"depth is not linear", Vector3{1, 0.5, -2}, Vector3{0.25, 0.25, 1.0 / 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ndc(projection, tt.point); !vector3Near(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrtho(t *testing.T) {
+	projection := Ortho(-2, 2, -1, 1, 1, 11)
+
+	tests := []struct {
+		name  string
+		point Vector3
+		want  Vector3
+	}{
+		{"near bottom left", Vector3{-2, -1, -1}, Vector3{-1, -1, -1}},
+		{"far top right", Vector3{2, 1, -11}, Vector3{1, 1, 1}},
+		{"centre", Vector3{0, 0, -6}, Vector3{}},
+		{"depth is linear", Vector3{1, 0.5, -3.5}, Vector3{0.5, 0.5, -0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ndc(projection, tt.point); !vector3Near(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		name  string
+		axis  Vector3
+		angle float64
+		point Vector3
+		want  Vector3
+	}{
+		{"about z", Vector3{0, 0, 1}, math.Pi / 2, Vector3{1, 0, 0}, Vector3{0, 1, 0}},
+		{"about x", Vector3{1, 0, 0}, math.Pi / 2, Vector3{0, 1, 0}, Vector3{0, 0, 1}},
+		{"about y", Vector3{0, 1, 0}, math.Pi / 2, Vector3{0, 0, 1}, Vector3{1, 0, 0}},
+		{"axis need not be unit length", Vector3{0, 0, 5}, math.Pi / 2, Vector3{1, 0, 0}, Vector3{0, 1, 0}},
+		{"diagonal axis cycles the axes", Vector3{1, 1, 1}, 2 * math.Pi / 3, Vector3{1, 0, 0}, Vector3{0, 1, 0}},
+		{"diagonal axis cycles the axes again", Vector3{1, 1, 1}, 2 * math.Pi / 3, Vector3{0, 0, 1}, Vector3{1, 0, 0}},
+		{"points on the axis stay put", Vector3{1, 2, 3}, 1.1, Vector3{2, 4, 6}, Vector3{2, 4, 6}},
+		{"half turn about a diagonal", Vector3{1, 1, 0}, math.Pi, Vector3{1, 0, 0}, Vector3{0, 1, 0}},
+		{"zero axis", Vector3{}, 1, Vector3{1, 2, 3}, Vector3{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Rotate(tt.axis, tt.angle).TransformPoint(tt.point); !vector3Near(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotateMatchesAxisRotations(t *testing.T) {
+	for _, angle := range []float64{-2, 0.3, math.Pi} {
+		if got, want := Rotate(Vector3{1, 0, 0}, angle), RotateX(angle); !mat4Near(got, want) {
+			t.Errorf("x axis, %v: got %v, want %v", angle, got, want)
+		}
+		if got, want := Rotate(Vector3{0, 1, 0}, angle), RotateY(angle); !mat4Near(got, want) {
+			t.Errorf("y axis, %v: got %v, want %v", angle, got, want)
+		}
+		if got, want := Rotate(Vector3{0, 0, 1}, angle), RotateZ(angle); !mat4Near(got, want) {
+			t.Errorf("z axis, %v: got %v, want %v", angle, got, want)
+		}
+	}
+}
+
+// general3 has determinant 1, so its inverse is whole numbers
+var general3 = Mat3{
+	{1, 2, 3},
+	{0, 1, 4},
+	{5, 6, 0},
+}
+
+func TestMat3(t *testing.T) {
+	if got := general3.Determinant(); !near(got, 1) {
+		t.Errorf("Determinant: got %v, want 1", got)
+	}
+
+	want := Mat3{
+		{-24, 18, 5},
+		{20, -15, -4},
+		{-5, 4, 1},
+	}
+	inverse, ok := general3.Inverse()
+	if !ok || !mat3Near(inverse, want) {
+		t.Errorf("Inverse: got %v, %v, want %v", inverse, ok, want)
+	}
+	if product := general3.Mul(inverse); !mat3Near(product, Ident3()) {
+		t.Errorf("m * inverse = %v, want the identity", product)
+	}
+
+	if got, ok := (Mat3{{1, 2, 3}, {2, 4, 6}, {0, 0, 1}}).Inverse(); ok || got != (Mat3{}) {
+		t.Errorf("singular Inverse: got %v, %v", got, ok)
+	}
+
+	transposed := Mat3{
+		{1, 0, 5},
+		{2, 1, 6},
+		{3, 4, 0},
+	}
+	if got := general3.Transpose(); got != transposed {
+		t.Errorf("Transpose: got %v, want %v", got, transposed)
+	}
+
+	if got := general3.MulVector3(Vector3{1, 1, 1}); got != (Vector3{6, 5, 11}) {
+		t.Errorf("MulVector3: got %v", got)
+	}
+
+	// Mat3 and Mat4 agree on the upper 3x3
+	if got := general3.Mat4().Mat3(); got != general3 {
+		t.Errorf("Mat4().Mat3(): got %v", got)
+	}
+	if got := general3.Mul(general3); !mat3Near(got, general3.Mat4().Mul(general3.Mat4()).Mat3()) {
+		t.Errorf("Mul disagrees with Mat4.Mul: got %v", got)
+	}
+}
+
+func TestNormalMatrix(t *testing.T) {
+	// Squashing a 45 degree slope along y tilts its normal towards y
+	normals, ok := Scale(1, 0.5, 1).NormalMatrix()
+	if !ok {
+		t.Fatal("reported singular")
+	}
+	if got := normals.MulVector3(Vector3{1, 1, 0}); !vector3Near(got, Vector3{1, 2, 0}) {
+		t.Errorf("got %v", got)
+	}
+
+	if _, ok := Scale(1, 0, 1).NormalMatrix(); ok {
+		t.Error("a flattening scale has no normal matrix")
+	}
+}
@@ -0,0 +1,151 @@
+package graphicsmath
+
+import "math"
+
+// Results of classifying a bounding volume against a plane or frustum
+const (
+	Outside      = iota // entirely behind the plane, or outside the frustum
+	Intersecting        // straddles the plane
+	Inside              // entirely in front of the plane, or inside the frustum
+)
+
+// Plane holds the points p where Normal.Dot(p) + D == 0. Points on the side Normal points
+// towards are in front of the plane.
+type Plane struct {
+	Normal Vector3
+	D      float64
+}
+
+// NewPlane makes the plane through point facing normal
+func NewPlane(normal, point Vector3) Plane {
+	normal = normal.Normalize()
+	return Plane{Normal: normal, D: -normal.Dot(point)}
+}
+
+// PlaneFromPoints makes the plane through a, b and c, facing the side they appear
+// counter-clockwise from. Collinear points return false.
+func PlaneFromPoints(a, b, c Vector3) (Plane, bool) {
+	normal, ok := b.Subtract(a).Cross(c.Subtract(a)).NormalizeSafe()
+	if !ok {
+		return Plane{}, false
+	}
+	return Plane{Normal: normal, D: -normal.Dot(a)}, true
+}
+
+// planeFromVector4 reads a homogeneous plane (x, y, z, w) as Normal (x, y, z) and D w,
+// scaled so Distance is in world units
+func planeFromVector4(v Vector4) Plane {
+	length := v.Vec3().Magnitude()
+	if length < Epsilon {
+		return Plane{D: v.W}
+	}
+	return Plane{Normal: v.Vec3().Multiply(1 / length), D: v.W / length}
+}
+
+// Distance is the signed distance from the plane to p, positive in front
+func (p Plane) Distance(q Vector3) float64 {
+	return p.Normal.Dot(q) + p.D
+}
+
+// Ray is a half line from Origin. Hit distances are measured in multiples of Direction, so
+// they are world units only when Direction is unit length.
+type Ray struct {
+	Origin    Vector3
+	Direction Vector3
+}
+
+func (r Ray) At(t float64) Vector3 {
+	return r.Origin.Add(r.Direction.Multiply(t))
+}
+
+// IntersectPlane returns where the ray crosses p. Rays parallel to the plane never hit it.
+func (r Ray) IntersectPlane(p Plane) (float64, bool) {
+	denominator := p.Normal.Dot(r.Direction)
+	if math.Abs(denominator) < Epsilon {
+		return 0, false
+	}
+
+	t := -p.Distance(r.Origin) / denominator
+	return t, t >= 0
+}
+
+// Volume is a bounding volume that can be tested against planes
+type Volume interface {
+	Classify(p Plane) int
+}
+
+// ViewFrustum is a convex volume bounded by planes facing inwards
+type ViewFrustum []Plane
+
+// ViewFrustumFromMatrix extracts the six planes of the clip volume -w <= x,y,z <= w of m. For a
+// projection times a view matrix the planes are in world space.
+func ViewFrustumFromMatrix(m Mat4) ViewFrustum {
+	x, y, z, w := m.Row(0), m.Row(1), m.Row(2), m.Row(3)
+
+	return ViewFrustum{
+		planeFromVector4(w.Add(x)),      // left
+		planeFromVector4(w.Subtract(x)), // right
+		planeFromVector4(w.Add(y)),      // bottom
+		planeFromVector4(w.Subtract(y)), // top
+		planeFromVector4(w.Add(z)),      // near
+		planeFromVector4(w.Subtract(z)), // far
+	}
+}
+
+func (f ViewFrustum) ContainsPoint(p Vector3) bool {
+	for _, plane := range f {
+		if plane.Distance(p) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Classify is conservative: a volume near a corner of the frustum can be reported as
+// Intersecting even though it is outside, but nothing visible is ever reported Outside.
+func (f ViewFrustum) Classify(v Volume) int {
+	result := Inside
+	for _, plane := range f {
+		switch v.Classify(plane) {
+		case Outside:
+			return Outside
+		case Intersecting:
+			result = Intersecting
+		}
+	}
+	return result
+}
+
+// Intersects reports whether any part of v may be inside the frustum
+func (f ViewFrustum) Intersects(v Volume) bool {
+	return f.Classify(v) != Outside
+}
+
+// IntersectTriangle returns where the ray passes through the triangle abc from either side,
+// using the Möller-Trumbore algorithm
+func (r Ray) IntersectTriangle(a, b, c Vector3) (float64, bool) {
+	edge1 := b.Subtract(a)
+	edge2 := c.Subtract(a)
+
+	p := r.Direction.Cross(edge2)
+	determinant := edge1.Dot(p)
+	if math.Abs(determinant) < Epsilon {
+		return 0, false // Parallel to the triangle, or the triangle has no area
+	}
+	inverse := 1 / determinant
+
+	offset := r.Origin.Subtract(a)
+	u := offset.Dot(p) * inverse
+	if u < 0 || u > 1 {
+		return 0, false
+	}
+
+	q := offset.Cross(edge1)
+	v := r.Direction.Dot(q) * inverse
+	if v < 0 || u+v > 1 {
+		return 0, false
+	}
+
+	t := edge2.Dot(q) * inverse
+	return t, t >= 0
+}
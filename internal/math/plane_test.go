@@ -0,0 +1,185 @@
+package graphicsmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPlaneFromPoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b, c Vector3
+		normal  Vector3
+		d       float64
+		ok      bool
+	}{
+		{"counter-clockwise", Vector3{0, 0, 1}, Vector3{1, 0, 1}, Vector3{0, 1, 1}, Vector3{0, 0, 1}, -1, true},
+		{"clockwise", Vector3{0, 0, 1}, Vector3{0, 1, 1}, Vector3{1, 0, 1}, Vector3{0, 0, -1}, 1, true},
+		{"collinear", Vector3{0, 0, 0}, Vector3{1, 1, 1}, Vector3{2, 2, 2}, Vector3{}, 0, false},
+		{"repeated", Vector3{1, 0, 0}, Vector3{1, 0, 0}, Vector3{0, 1, 0}, Vector3{}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := PlaneFromPoints(tt.a, tt.b, tt.c)
+			if ok != tt.ok || ok && (!vector3Near(p.Normal, tt.normal) || !near(p.D, tt.d)) {
+				t.Errorf("got %v, %v, want normal %v and D %v", p, ok, tt.normal, tt.d)
+			}
+		})
+	}
+}
+
+func TestPlaneDistance(t *testing.T) {
+	p := NewPlane(Vector3{0, 2, 0}, Vector3{5, 3, 5}) // y = 3, facing up
+
+	tests := []struct {
+		point Vector3
+		want  float64
+	}{
+		{Vector3{0, 3, 0}, 0},
+		{Vector3{1, 5, -1}, 2},
+		{Vector3{0, -1, 0}, -4},
+	}
+
+	for _, tt := range tests {
+		if got := p.Distance(tt.point); !near(got, tt.want) {
+			t.Errorf("distance to %v is %v, want %v", tt.point, got, tt.want)
+		}
+	}
+}
+
+func TestIntersectPlane(t *testing.T) {
+	p := NewPlane(Vector3{0, 0, 1}, Vector3{0, 0, -5})
+
+	tests := []struct {
+		name  string
+		ray   Ray
+		want  float64
+		found bool
+	}{
+		{"straight on", Ray{Vector3{}, Vector3{0, 0, -1}}, 5, true},
+		{"from behind", Ray{Vector3{0, 0, -10}, Vector3{0, 0, 1}}, 5, true},
+		{"slanted", Ray{Vector3{}, Vector3{1, 0, -1}}, 5, true},
+		{"pointing away", Ray{Vector3{}, Vector3{0, 0, 1}}, 0, false},
+		{"parallel", Ray{Vector3{}, Vector3{1, 0, 0}}, 0, false},
+		{"starting on it", Ray{Vector3{0, 0, -5}, Vector3{0, 1, -1}}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := tt.ray.IntersectPlane(p)
+			if found != tt.found || found && !near(got, tt.want) {
+				t.Errorf("got %v, %v, want %v, %v", got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestIntersectTriangle(t *testing.T) {
+	abc := [3]Vector3{{0, 0, -5}, {4, 0, -5}, {0, 4, -5}}
+	flat := [3]Vector3{{0, 0, -5}, {4, 0, -5}, {2, 0, -5}}
+	down := Vector3{0, 0, -1}
+	at := func(x, y float64) Ray { return Ray{Vector3{x, y, 0}, down} }
+
+	tests := []struct {
+		name     string
+		ray      Ray
+		triangle [3]Vector3
+		want     float64
+		found    bool
+	}{
+		{"inside", at(1, 1), abc, 5, true},
+		{"from behind", Ray{Vector3{1, 1, -10}, Vector3{0, 0, 1}}, abc, 5, true},
+		{"slanted", Ray{Vector3{-1, 1, 0}, Vector3{0.4, 0, -1}}, abc, 5, true},
+		{"on the edge ab", at(2, 0), abc, 5, true},
+		{"on the edge ac", at(0, 2), abc, 5, true},
+		{"on the edge bc", at(2, 2), abc, 5, true},
+		{"on a corner", at(4, 0), abc, 5, true},
+		{"just past ab", at(2, -1e-6), abc, 0, false},
+		{"just past bc", at(2, 2+1e-6), abc, 0, false},
+		{"outside", at(3, 3), abc, 0, false},
+		{"pointing away", Ray{Vector3{1, 1, 0}, Vector3{0, 0, 1}}, abc, 0, false},
+		{"parallel", Ray{Vector3{-1, 1, -5}, Vector3{1, 0, 0}}, abc, 0, false},
+		{"no area", at(1, 1), flat, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b, c := tt.triangle[0], tt.triangle[1], tt.triangle[2]
+			got, found := tt.ray.IntersectTriangle(a, b, c)
+			if found != tt.found || found && !near(got, tt.want) {
+				t.Errorf("got %v, %v, want %v, %v", got, found, tt.want, tt.found)
+			}
+
+			// The winding makes no difference
+			got, found = tt.ray.IntersectTriangle(a, c, b)
+			if found != tt.found || found && !near(got, tt.want) {
+				t.Errorf("wound the other way got %v, %v", got, found)
+			}
+		})
+	}
+}
+
+func TestViewFrustumFromMatrix(t *testing.T) {
+	f := ViewFrustumFromMatrix(Perspective(math.Pi/2, 1, 1, 100))
+
+	tests := []struct {
+		name  string
+		point Vector3
+		want  bool
+	}{
+		{"centre", Vector3{0, 0, -10}, true},
+		{"near the edge", Vector3{9.9, 0, -10}, true},
+		{"past the edge", Vector3{10.1, 0, -10}, false},
+		{"before the near plane", Vector3{0, 0, -0.5}, false},
+		{"past the far plane", Vector3{0, 0, -101}, false},
+		{"behind", Vector3{0, 0, 10}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.ContainsPoint(tt.point); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViewFrustumClassify(t *testing.T) {
+	// A box from -10 to 10 across and from 1 to 20 in front of the camera
+	f := ViewFrustumFromMatrix(Ortho(-10, 10, -10, 10, 1, 20))
+	box := func(x, y, z, half float64) AABB {
+		return AABB{Min: Vector3{x - half, y - half, z - half}, Max: Vector3{x + half, y + half, z + half}}
+	}
+
+	tests := []struct {
+		name   string
+		volume Volume
+		want   int
+	}{
+		{"box inside", box(0, 0, -10, 2), Inside},
+		{"box straddling the left plane", box(-10, 0, -10, 2), Intersecting},
+		{"box straddling the near plane", box(0, 0, -1, 0.5), Intersecting},
+		{"box touching the right plane", box(12, 0, -10, 2), Intersecting},
+		{"box outside", box(15, 0, -10, 2), Outside},
+		{"box behind the camera", box(0, 0, 5, 2), Outside},
+		{"box around the frustum", box(0, 0, -10, 50), Intersecting},
+		{"empty box", EmptyAABB(), Outside},
+		{"sphere inside", Sphere{Vector3{0, 0, -10}, 3}, Inside},
+		{"sphere straddling the far plane", Sphere{Vector3{0, 0, -20}, 3}, Intersecting},
+		{"sphere outside", Sphere{Vector3{0, 14, -10}, 3}, Outside},
+		{"turned box straddling", box(9, 0, -10, 1).OBB().Transform(Translate(9, 0, -10).Mul(RotateZ(math.Pi / 4)).Mul(Translate(-9, 0, 10))), Intersecting},
+		{"turned box inside", box(8, 0, -10, 1).OBB().Transform(Translate(8, 0, -10).Mul(RotateZ(math.Pi / 4)).Mul(Translate(-8, 0, 10))), Inside},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.Classify(tt.volume); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+			if got := f.Intersects(tt.volume); got != (tt.want != Outside) {
+				t.Errorf("Intersects is %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package graphicsmath
+
+import "math"
+
+// Quaternion represents a rotation as W + Xi + Yj + Zk. Rotations use unit quaternions.
+type Quaternion struct {
+	W float64
+	X float64
+	Y float64
+	Z float64
+}
+
+func QuatIdent() Quaternion {
+	return Quaternion{W: 1}
+}
+
+// QuatFromAxisAngle turns counter-clockwise about axis. A zero axis gives the identity.
+func QuatFromAxisAngle(axis Vector3, angle float64) Quaternion {
+	length := axis.Magnitude()
+	if length == 0 {
+		return QuatIdent()
+	}
+
+	s := math.Sin(angle/2) / length
+	return Quaternion{W: math.Cos(angle / 2), X: axis.X * s, Y: axis.Y * s, Z: axis.Z * s}
+}
+
+// AxisAngle returns a unit axis and an angle in [0, 2pi]. The identity has no unique axis and
+// returns +x.
+func (q Quaternion) AxisAngle() (Vector3, float64) {
+	q = q.Normalize()
+	angle := 2 * math.Acos(math.Max(-1, math.Min(1, q.W)))
+	s := math.Sqrt(1 - q.W*q.W)
+
+	if s < Epsilon {
+		return Vector3{1, 0, 0}, 0
+	}
+
+	return Vector3{q.X / s, q.Y / s, q.Z / s}, angle
+}
+
+// Mul composes rotations: q.Mul(r) applies r first, then q
+func (q Quaternion) Mul(r Quaternion) Quaternion {
+	return Quaternion{
+		W: q.W*r.W - q.X*r.X - q.Y*r.Y - q.Z*r.Z,
+		X: q.W*r.X + q.X*r.W + q.Y*r.Z - q.Z*r.Y,
+		Y: q.W*r.Y - q.X*r.Z + q.Y*r.W + q.Z*r.X,
+		Z: q.W*r.Z + q.X*r.Y - q.Y*r.X + q.Z*r.W,
+	}
+}
+
+func (q Quaternion) Conjugate() Quaternion {
+	return Quaternion{W: q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+}
+
+func (q Quaternion) Dot(r Quaternion) float64 {
+	return q.W*r.W + q.X*r.X + q.Y*r.Y + q.Z*r.Z
+}
+
+func (q Quaternion) Length() float64 {
+	return math.Sqrt(q.Dot(q))
+}
+
+// Normalize returns the identity for the zero quaternion
+func (q Quaternion) Normalize() Quaternion {
+	length := q.Length()
+	if length == 0 {
+		return QuatIdent()
+	}
+	return q.Scale(1 / length)
+}
+
+// Inverse returns false, along with the zero quaternion, for the zero quaternion
+func (q Quaternion) Inverse() (Quaternion, bool) {
+	lengthSquared := q.Dot(q)
+	if lengthSquared == 0 {
+		return Quaternion{}, false
+	}
+	return q.Conjugate().Scale(1 / lengthSquared), true
+}
+
+func (q Quaternion) Scale(s float64) Quaternion {
+	return Quaternion{W: q.W * s, X: q.X * s, Y: q.Y * s, Z: q.Z * s}
+}
+
+func (q Quaternion) Add(r Quaternion) Quaternion {
+	return Quaternion{W: q.W + r.W, X: q.X + r.X, Y: q.Y + r.Y, Z: q.Z + r.Z}
+}
+
+// Rotate applies the rotation of a unit quaternion to v
+func (q Quaternion) Rotate(v Vector3) Vector3 {
+	u := Vector3{q.X, q.Y, q.Z}
+	t := u.Cross(v).Multiply(2)
+	return v.Add(t.Multiply(q.W)).Add(u.Cross(t))
+}
+
+func (q Quaternion) Mat3() Mat3 {
+	w, x, y, z := q.W, q.X, q.Y, q.Z
+	return Mat3{
+		{1 - 2*(y*y+z*z), 2 * (x*y - w*z), 2 * (x*z + w*y)},
+		{2 * (x*y + w*z), 1 - 2*(x*x+z*z), 2 * (y*z - w*x)},
+		{2 * (x*z - w*y), 2 * (y*z + w*x), 1 - 2*(x*x+y*y)},
+	}
+}
+
+func (q Quaternion) Mat4() Mat4 {
+	return q.Mat3().Mat4()
+}
+
+// QuatFromMat3 extracts the rotation of an orthonormal matrix (Shepperd's method)
+func QuatFromMat3(m Mat3) Quaternion {
+	trace := m[0][0] + m[1][1] + m[2][2]
+
+	var q Quaternion
+	switch {
+	case trace > 0:
+		s := 2 * math.Sqrt(trace+1)
+		q = Quaternion{W: s / 4, X: (m[2][1] - m[1][2]) / s, Y: (m[0][2] - m[2][0]) / s, Z: (m[1][0] - m[0][1]) / s}
+	case m[0][0] > m[1][1] && m[0][0] > m[2][2]:
+		s := 2 * math.Sqrt(1+m[0][0]-m[1][1]-m[2][2])
+		q = Quaternion{W: (m[2][1] - m[1][2]) / s, X: s / 4, Y: (m[0][1] + m[1][0]) / s, Z: (m[0][2] + m[2][0]) / s}
+	case m[1][1] > m[2][2]:
+		s := 2 * math.Sqrt(1+m[1][1]-m[0][0]-m[2][2])
+		q = Quaternion{W: (m[0][2] - m[2][0]) / s, X: (m[0][1] + m[1][0]) / s, Y: s / 4, Z: (m[1][2] + m[2][1]) / s}
+	default:
+		s := 2 * math.Sqrt(1+m[2][2]-m[0][0]-m[1][1])
+		q = Quaternion{W: (m[1][0] - m[0][1]) / s, X: (m[0][2] + m[2][0]) / s, Y: (m[1][2] + m[2][1]) / s, Z: s / 4}
+	}
+
+	return q.Normalize()
+}
+
+// QuatFromMat4 extracts the rotation of the upper 3x3 of m, which must be orthonormal
+func QuatFromMat4(m Mat4) Quaternion {
+	return QuatFromMat3(m.Mat3())
+}
+
+// Slerp interpolates along the shortest arc between two unit quaternions at constant
+// angular speed. t = 0 returns q, t = 1 returns r (or its negation, the same rotation).
+func (q Quaternion) Slerp(r Quaternion, t float64) Quaternion {
+	cosTheta := q.Dot(r)
+
+	// q and -q are the same rotation, take the shorter way round
+	if cosTheta < 0 {
+		r = r.Scale(-1)
+		cosTheta = -cosTheta
+	}
+
+	// Nearly parallel: fall back to normalized linear interpolation to avoid dividing by ~0
+	if cosTheta > 0.9995 {
+		return q.Scale(1 - t).Add(r.Scale(t)).Normalize()
+	}
+
+	theta := math.Acos(cosTheta)
+	sinTheta := math.Sin(theta)
+
+	return q.Scale(math.Sin((1-t)*theta) / sinTheta).Add(r.Scale(math.Sin(t*theta) / sinTheta))
+}
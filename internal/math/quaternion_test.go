@@ -0,0 +1,133 @@
+package graphicsmath
+
+import (
+	"math"
+	"testing"
+)
+
+// sameRotation allows for q and -q, which turn the same way
+func sameRotation(q, r Quaternion) bool {
+	return near(math.Abs(q.Dot(r)), 1)
+}
+
+func TestSlerp(t *testing.T) {
+	quarter := QuatFromAxisAngle(Vector3{0, 0, 1}, math.Pi/2)
+	eighth := QuatFromAxisAngle(Vector3{0, 0, 1}, math.Pi/4)
+	tilted := QuatFromAxisAngle(Vector3{1, 2, 3}, 2)
+
+	tests := []struct {
+		name string
+		q, r Quaternion
+		t    float64
+		want Quaternion
+	}{
+		{"start", QuatIdent(), quarter, 0, QuatIdent()},
+		{"end", QuatIdent(), quarter, 1, quarter},
+		{"midpoint", QuatIdent(), quarter, 0.5, eighth},
+		{"quarter of the way", QuatIdent(), quarter, 0.25, QuatFromAxisAngle(Vector3{0, 0, 1}, math.Pi/8)},
+		{"shortest way round", QuatIdent(), quarter.Scale(-1), 0.5, eighth},
+		{"nearly parallel", QuatIdent(), QuatFromAxisAngle(Vector3{0, 1, 0}, 0.01), 0.5, QuatFromAxisAngle(Vector3{0, 1, 0}, 0.005)},
+		{"arbitrary axis", QuatFromAxisAngle(Vector3{1, 2, 3}, 0.5), tilted, 0.5, QuatFromAxisAngle(Vector3{1, 2, 3}, 1.25)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.q.Slerp(tt.r, tt.t)
+			if !sameRotation(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if !near(got.Length(), 1) {
+				t.Errorf("length %v, want 1", got.Length())
+			}
+		})
+	}
+}
+
+func TestSlerpConstantSpeed(t *testing.T) {
+	q := QuatFromAxisAngle(Vector3{1, 0, 0}, 0.2)
+	r := QuatFromAxisAngle(Vector3{0, 1, 1}, 2.5)
+	total := 2 * math.Acos(math.Abs(q.Dot(r)))
+
+	for _, step := range []float64{0.1, 0.3, 0.6, 0.9} {
+		angle := 2 * math.Acos(math.Min(1, math.Abs(q.Dot(q.Slerp(r, step)))))
+		if !near(angle, step*total) {
+			t.Errorf("t = %v: turned %v, want %v", step, angle, step*total)
+		}
+	}
+}
+
+func TestAxisAngleRoundTrip(t *testing.T) {
+	tests := []struct {
+		axis  Vector3
+		angle float64
+	}{
+		{Vector3{1, 0, 0}, math.Pi / 2},
+		{Vector3{0, 1, 0}, 0.1},
+		{Vector3{0, 0, 1}, math.Pi},
+		{Vector3{1, 2, 3}, 2},
+		{Vector3{-1, 0.5, 0.25}, 3 * math.Pi / 2},
+		{Vector3{0, 0, 4}, 1}, // Not unit length
+	}
+
+	for _, tt := range tests {
+		q := QuatFromAxisAngle(tt.axis, tt.angle)
+		if !near(q.Length(), 1) {
+			t.Errorf("QuatFromAxisAngle(%v, %v) has length %v", tt.axis, tt.angle, q.Length())
+		}
+
+		axis, angle := q.AxisAngle()
+		if !vector3Near(axis, tt.axis.Normalize()) || !near(angle, tt.angle) {
+			t.Errorf("%v, %v came back as %v, %v", tt.axis, tt.angle, axis, angle)
+		}
+	}
+
+	if axis, angle := QuatIdent().AxisAngle(); axis != (Vector3{1, 0, 0}) || angle != 0 {
+		t.Errorf("identity: got %v, %v", axis, angle)
+	}
+}
+
+func TestMatrixRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		q    Quaternion
+	}{
+		{"identity", QuatIdent()},
+		{"small turn", QuatFromAxisAngle(Vector3{1, 2, 3}, 0.4)},
+		{"large turn", QuatFromAxisAngle(Vector3{-3, 1, 2}, 2.9)},
+		// Half turns have a trace of -1, which takes the other branches of the extraction
+		{"half turn about x", QuatFromAxisAngle(Vector3{1, 0, 0}, math.Pi)},
+		{"half turn about y", QuatFromAxisAngle(Vector3{0, 1, 0}, math.Pi)},
+		{"half turn about z", QuatFromAxisAngle(Vector3{0, 0, 1}, math.Pi)},
+		{"half turn about a diagonal", QuatFromAxisAngle(Vector3{1, 1, 0}, math.Pi)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuatFromMat3(tt.q.Mat3()); !sameRotation(got, tt.q) {
+				t.Errorf("Mat3: got %v, want %v", got, tt.q)
+			}
+			if got := QuatFromMat4(tt.q.Mat4()); !sameRotation(got, tt.q) {
+				t.Errorf("Mat4: got %v, want %v", got, tt.q)
+			}
+
+			// The matrix and the quaternion turn points the same way
+			p := Vector3{0.5, -2, 3}
+			if a, b := tt.q.Rotate(p), tt.q.Mat3().MulVector3(p); !vector3Near(a, b) {
+				t.Errorf("Rotate gives %v, the matrix %v", a, b)
+			}
+		})
+	}
+}
+
+func TestQuaternionMatchesRotate(t *testing.T) {
+	axis, angle := Vector3{2, -1, 0.5}, 1.3
+	if got, want := QuatFromAxisAngle(axis, angle).Mat4(), Rotate(axis, angle); !mat4Near(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// q.Mul(r) applies r first, like the matrices
+	q, r := QuatFromAxisAngle(Vector3{1, 0, 0}, 0.7), QuatFromAxisAngle(Vector3{0, 1, 0}, -0.4)
+	if got, want := q.Mul(r).Mat4(), RotateX(0.7).Mul(RotateY(-0.4)); !mat4Near(got, want) {
+		t.Errorf("Mul: got %v, want %v", got, want)
+	}
+}
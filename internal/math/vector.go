@@ -22,6 +22,13 @@ type Vector3 struct {
 	Z float64
 }
 
+type Vector4 struct {
+	X float64
+	Y float64
+	Z float64
+	W float64
+}
+
 type Color3 struct {
 	R float64
 	G float64
@@ -36,16 +43,41 @@ func (v1 Vector3) Cross(v2 Vector3) Vector3 {
 	}
 }
 
+// Magnitude divides through by the largest component before squaring, so vectors with huge or
+// tiny components don't overflow to +Inf or underflow to 0
 func (v Vector3) Magnitude() float64 {
-	return math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+	scale := max(math.Abs(v.X), math.Abs(v.Y), math.Abs(v.Z))
+	if scale == 0 || math.IsInf(scale, 1) {
+		return scale
+	}
+
+	x, y, z := v.X/scale, v.Y/scale, v.Z/scale
+	return scale * math.Sqrt(x*x+y*y+z*z)
 }
 
+// Epsilon is the smallest magnitude a vector can have and still be given a direction
+const Epsilon = 1e-12
+
+// Normalize scales v to unit length. Degenerate vectors (zero, near zero, NaN
+// or infinite length) have no direction and come back as the zero vector, so
+// they contribute nothing to lighting instead of spreading NaNs.
 func (v Vector3) Normalize() Vector3 {
-	return Vector3{
-		v.X / v.Magnitude(),
-		v.Y / v.Magnitude(),
-		v.Z / v.Magnitude(),
+	n, _ := v.NormalizeSafe()
+	return n
+}
+
+// NormalizeSafe is Normalize that also reports whether v had a usable direction
+func (v Vector3) NormalizeSafe() (Vector3, bool) {
+	m := v.Magnitude()
+	if m < Epsilon || math.IsInf(m, 0) || math.IsNaN(m) {
+		return Vector3{}, false
 	}
+
+	return Vector3{
+		v.X / m,
+		v.Y / m,
+		v.Z / m,
+	}, true
 }
 
 func (v1 Vector3) Dot(v2 Vector3) float64 {
@@ -101,6 +133,15 @@ func (c Color3) Multiply(s float64) Color3 {
 	}
 }
 
+// Modulate multiplies two colors channel by channel
+func (c Color3) Modulate(c2 Color3) Color3 {
+	return Color3{
+		c.R * c2.R,
+		c.G * c2.G,
+		c.B * c2.B,
+	}
+}
+
 func (c Color3) Add(c2 Color3) Color3 {
 	return Color3{
 		c.R + c2.R,
@@ -116,3 +157,91 @@ func (p Vector3) Add(v Vector3) Vector3 {
 		p.Z + v.Z,
 	}
 }
+
+func (v1 Vector4) Dot(v2 Vector4) float64 {
+	return v1.X*v2.X + v1.Y*v2.Y + v1.Z*v2.Z + v1.W*v2.W
+}
+
+func (v1 Vector4) Add(v2 Vector4) Vector4 {
+	return Vector4{
+		v1.X + v2.X,
+		v1.Y + v2.Y,
+		v1.Z + v2.Z,
+		v1.W + v2.W,
+	}
+}
+
+func (v1 Vector4) Subtract(v2 Vector4) Vector4 {
+	return Vector4{
+		v1.X - v2.X,
+		v1.Y - v2.Y,
+		v1.Z - v2.Z,
+		v1.W - v2.W,
+	}
+}
+
+func (v Vector4) Multiply(s float64) Vector4 {
+	return Vector4{
+		v.X * s,
+		v.Y * s,
+		v.Z * s,
+		v.W * s,
+	}
+}
+
+func (v Vector3) Vec4(w float64) Vector4 {
+	return Vector4{v.X, v.Y, v.Z, w}
+}
+
+// Vec3 drops w without dividing by it
+func (v Vector4) Vec3() Vector3 {
+	return Vector3{v.X, v.Y, v.Z}
+}
+
+func (v Vector3) Vec2() Vector2 {
+	return Vector2{v.X, v.Y}
+}
+
+func (v Vector4) Vec2() Vector2 {
+	return Vector2{v.X, v.Y}
+}
+
+func (v1 Vector2) Add(v2 Vector2) Vector2 {
+	return Vector2{
+		v1.X + v2.X,
+		v1.Y + v2.Y,
+	}
+}
+
+func (v Vector2) Multiply(s float64) Vector2 {
+	return Vector2{
+		v.X * s,
+		v.Y * s,
+	}
+}
+
+func (v1 Vector2) Dot(v2 Vector2) float64 {
+	return v1.X*v2.X + v1.Y*v2.Y
+}
+
+func (v Vector2) Magnitude() float64 {
+	return math.Hypot(v.X, v.Y)
+}
+
+// Normalize follows the same degenerate input policy as Vector3.Normalize
+func (v Vector2) Normalize() Vector2 {
+	n, _ := v.NormalizeSafe()
+	return n
+}
+
+func (v Vector2) NormalizeSafe() (Vector2, bool) {
+	m := v.Magnitude()
+	if m < Epsilon || math.IsInf(m, 0) || math.IsNaN(m) {
+		return Vector2{}, false
+	}
+
+	return Vector2{
+		v.X / m,
+		v.Y / m,
+	}, true
+}
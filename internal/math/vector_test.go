@@ -0,0 +1,261 @@
+package graphicsmath
+
+import (
+	"math"
+	"testing"
+)
+
+// closeTo allows a relative error of about a thousand ulps, or an absolute one near zero
+func closeTo(a, b, size float64) bool {
+	return math.Abs(a-b) <= 1e-12*math.Max(size, 1)
+}
+
+// samples covers small, large, mixed and negative components
+var samples = []Vector3{
+	{1, 0, 0},
+	{0, -2, 0},
+	{1, 2, 3},
+	{-4.5, 0.25, 7},
+	{1e-6, 3e-6, -2e-6},
+	{1e6, -1, 1e-6},
+	{123.456, -789.012, 345.678},
+	{1e300, 1e300, 0},
+	{-1e300, 5e299, 1e300},
+	{1e-300, 1e-300, 1e-300},
+}
+
+func TestCrossIsOrthogonal(t *testing.T) {
+	for _, a := range samples {
+		for _, b := range samples {
+			if math.Abs(a.X) > 1e100 || math.Abs(b.X) > 1e100 {
+				continue // The products overflow
+			}
+			c := a.Cross(b)
+			size := a.Magnitude() * b.Magnitude() * a.Magnitude()
+			if !closeTo(c.Dot(a), 0, size) || !closeTo(c.Dot(b), 0, a.Magnitude()*b.Magnitude()*b.Magnitude()) {
+				t.Errorf("%v x %v = %v is not perpendicular to both", a, b, c)
+			}
+			if anti := b.Cross(a); anti != c.Multiply(-1) {
+				t.Errorf("%v x %v = %v, but the other way round is %v", a, b, c, anti)
+			}
+		}
+	}
+
+	// The axes follow the right hand rule
+	if got := (Vector3{1, 0, 0}).Cross(Vector3{0, 1, 0}); got != (Vector3{0, 0, 1}) {
+		t.Errorf("x cross y = %v, want z", got)
+	}
+}
+
+func TestNormalizeHasUnitLength(t *testing.T) {
+	for _, v := range samples {
+		n, ok := v.NormalizeSafe()
+		if v.Magnitude() < Epsilon {
+			continue // Covered by TestNormalizeSafePolicy
+		}
+		if !ok {
+			t.Errorf("%v: no direction", v)
+			continue
+		}
+		if !closeTo(n.Magnitude(), 1, 1) {
+			t.Errorf("%v normalized to %v with length %v", v, n, n.Magnitude())
+		}
+		if n.Dot(v) <= 0 {
+			t.Errorf("%v normalized to %v, which points the other way", v, n)
+		}
+	}
+
+	for _, v := range []Vector2{{3, 4}, {-1, 0}, {1e300, 1e300}, {-1e-6, 1e-6}} {
+		n, ok := v.NormalizeSafe()
+		if !ok || !closeTo(n.Magnitude(), 1, 1) {
+			t.Errorf("%v normalized to %v, %v", v, n, ok)
+		}
+	}
+}
+
+func TestMagnitude(t *testing.T) {
+	tests := []struct {
+		v    Vector3
+		want float64
+	}{
+		{Vector3{}, 0},
+		{Vector3{3, 4, 0}, 5},
+		{Vector3{2, -3, 6}, 7},
+		{Vector3{1e300, 1e300, 0}, math.Sqrt2 * 1e300},
+		{Vector3{3e-300, 4e-300, 0}, 5e-300},
+		{Vector3{math.Inf(-1), 1, 0}, math.Inf(1)},
+	}
+
+	for _, tt := range tests {
+		if got := tt.v.Magnitude(); !closeTo(got, tt.want, tt.want) && got != tt.want {
+			t.Errorf("%v: got %v, want %v", tt.v, got, tt.want)
+		}
+	}
+
+	if got := (Vector2{3e200, 4e200}).Magnitude(); !closeTo(got, 5e200, 5e200) {
+		t.Errorf("Vector2: got %v, want 5e200", got)
+	}
+	if got := (Vector3{math.NaN(), 1, 0}).Magnitude(); !math.IsNaN(got) {
+		t.Errorf("NaN: got %v", got)
+	}
+}
+
+func TestDotIsSymmetric(t *testing.T) {
+	for _, a := range samples {
+		for _, b := range samples {
+			if a.Dot(b) != b.Dot(a) && !math.IsNaN(a.Dot(b)) {
+				t.Errorf("%v . %v = %v, but %v the other way round", a, b, a.Dot(b), b.Dot(a))
+			}
+		}
+	}
+}
+
+func TestRoundTrips(t *testing.T) {
+	for _, a := range samples {
+		for _, b := range samples {
+			if got := a.Add(b).Subtract(b); !closeTo(got.X, a.X, math.Abs(b.X)) || !closeTo(got.Y, a.Y, math.Abs(b.Y)) || !closeTo(got.Z, a.Z, math.Abs(b.Z)) {
+				t.Errorf("%v + %v - %v = %v", a, b, b, got)
+			}
+		}
+
+		for _, s := range []float64{2, -0.5, 1e-3, 3} {
+			if got := a.Multiply(s).Multiply(1 / s); !closeTo(got.X, a.X, math.Abs(a.X)) || !closeTo(got.Y, a.Y, math.Abs(a.Y)) || !closeTo(got.Z, a.Z, math.Abs(a.Z)) {
+				t.Errorf("%v * %v / %v = %v", a, s, s, got)
+			}
+		}
+	}
+}
+
+// TestNormalizeSafePolicy pins down what counts as having no direction
+func TestNormalizeSafePolicy(t *testing.T) {
+	inf, nan := math.Inf(1), math.NaN()
+
+	tests := []struct {
+		name string
+		v    Vector3
+		ok   bool
+	}{
+		{"zero", Vector3{}, false},
+		{"shorter than Epsilon", Vector3{Epsilon / 2, 0, 0}, false},
+		{"Epsilon long", Vector3{0, Epsilon, 0}, true},
+		{"NaN component", Vector3{1, nan, 0}, false},
+		{"infinite component", Vector3{inf, 0, 0}, false},
+		{"negative infinite component", Vector3{1, 1, -inf}, false},
+		{"huge", Vector3{1e300, 1e300, 0}, true},
+		{"largest float", Vector3{math.MaxFloat64, 0, 0}, true},
+		{"length too long to represent", Vector3{math.MaxFloat64, math.MaxFloat64, 0}, false},
+		{"tiny", Vector3{1e-300, 1e-300, 1e-300}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, ok := tt.v.NormalizeSafe()
+			if ok != tt.ok {
+				t.Fatalf("got %v, want %v", ok, tt.ok)
+			}
+			if !ok && n != (Vector3{}) {
+				t.Errorf("got %v, want the zero vector", n)
+			}
+			if got := tt.v.Normalize(); got != n {
+				t.Errorf("Normalize gives %v, NormalizeSafe %v", got, n)
+			}
+		})
+	}
+}
+
+func FuzzNormalize(f *testing.F) {
+	f.Add(1.0, 2.0, 3.0)
+	f.Add(0.0, 0.0, 0.0)
+	f.Add(1e300, 1e300, 0.0)
+	f.Add(1e-300, -1e-300, 1e-300)
+	f.Add(math.MaxFloat64, -math.MaxFloat64, math.SmallestNonzeroFloat64)
+	f.Add(math.NaN(), 1.0, 0.0)
+	f.Add(math.Inf(1), 0.0, 0.0)
+
+	f.Fuzz(func(t *testing.T, x, y, z float64) {
+		v := Vector3{x, y, z}
+		n, ok := v.NormalizeSafe()
+		if !ok {
+			if n != (Vector3{}) {
+				t.Fatalf("%v has no direction but normalized to %v", v, n)
+			}
+			return
+		}
+
+		if math.IsNaN(n.X) || math.IsNaN(n.Y) || math.IsNaN(n.Z) {
+			t.Fatalf("%v normalized to %v", v, n)
+		}
+		if length := n.Magnitude(); math.Abs(length-1) > 1e-12 {
+			t.Fatalf("%v normalized to %v with length %v", v, n, length)
+		}
+		if n.X*x < 0 || n.Y*y < 0 || n.Z*z < 0 {
+			t.Fatalf("%v normalized to %v, which points another way", v, n)
+		}
+	})
+}
+
+func FuzzCross(f *testing.F) {
+	f.Add(1.0, 0.0, 0.0, 0.0, 1.0, 0.0)
+	f.Add(1.0, 2.0, 3.0, 1.0, 2.0, 3.0)
+	f.Add(-4.5, 0.25, 7.0, 1e-6, 3e-6, -2e-6)
+	f.Add(1e100, -1e100, 1.0, 1e-100, 2.0, -3e100)
+	f.Add(0.0, 0.0, 0.0, 5.0, 6.0, 7.0)
+
+	f.Fuzz(func(t *testing.T, ax, ay, az, bx, by, bz float64) {
+		a, b := Vector3{ax, ay, az}, Vector3{bx, by, bz}
+		for _, c := range []float64{ax, ay, az, bx, by, bz} {
+			if math.IsNaN(c) || math.Abs(c) > 1e100 {
+				return // The products overflow
+			}
+		}
+
+		c := a.Cross(b)
+		if anti := b.Cross(a); anti != c.Multiply(-1) {
+			t.Fatalf("%v x %v = %v, but the other way round is %v", a, b, c, anti)
+		}
+		if !closeTo(c.Dot(a), 0, a.Magnitude()*a.Magnitude()*b.Magnitude()) {
+			t.Fatalf("%v x %v = %v is not perpendicular to %v", a, b, c, a)
+		}
+		if !closeTo(c.Dot(b), 0, a.Magnitude()*b.Magnitude()*b.Magnitude()) {
+			t.Fatalf("%v x %v = %v is not perpendicular to %v", a, b, c, b)
+		}
+	})
+}
+
+func FuzzMagnitude(f *testing.F) {
+	f.Add(3.0, 4.0, 0.0)
+	f.Add(0.0, 0.0, 0.0)
+	f.Add(1e300, 1e300, 1e300)
+	f.Add(3e-320, 4e-320, 0.0)
+	f.Add(math.MaxFloat64, 0.0, 0.0)
+	f.Add(math.MaxFloat64/2, -math.MaxFloat64/2, math.MaxFloat64/2)
+	f.Add(math.SmallestNonzeroFloat64, 1.0, -math.SmallestNonzeroFloat64)
+
+	f.Fuzz(func(t *testing.T, x, y, z float64) {
+		v := Vector3{x, y, z}
+		largest := max(math.Abs(x), math.Abs(y), math.Abs(z))
+		if math.IsNaN(largest) || math.IsInf(largest, 1) {
+			return
+		}
+
+		got := v.Magnitude()
+		if largest <= math.MaxFloat64/math.Sqrt(3) && math.IsInf(got, 1) {
+			t.Fatalf("%v: length overflowed", v)
+		}
+		if largest > 0 && got == 0 {
+			t.Fatalf("%v: length underflowed to 0", v)
+		}
+
+		// Between the largest component and the diagonal of a cube that size
+		if got < largest || got > largest*math.Sqrt(3)*(1+1e-15) {
+			t.Fatalf("%v: length %v, largest component %v", v, got, largest)
+		}
+
+		// The plain formula, where its squares neither overflow nor lose precision
+		if largest > 1e-150 && largest < 1e150 {
+			if want := math.Sqrt(x*x + y*y + z*z); !closeTo(got, want, want) {
+				t.Fatalf("%v: length %v, want %v", v, got, want)
+			}
+		}
+	})
+}
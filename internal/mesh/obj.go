@@ -0,0 +1,251 @@
+// Package mesh loads polygon meshes from disk into triangle lists.
+package mesh
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+type Vertex struct {
+	Position  mymath.Vector3
+	Normal    mymath.Vector3
+	UV        mymath.Vector2
+	Color     mymath.Color3
+	HasNormal bool
+	HasUV     bool
+	HasColor  bool
+}
+
+type Face [3]Vertex
+
+// Group is a run of faces sharing the same object ("o") and group ("g") name
+type Group struct {
+	Object string
+	Name   string
+	Faces  []Face
+}
+
+type Mesh struct {
+	Groups []*Group
+}
+
+// Faces returns every face of every group, in file order
+func (m *Mesh) Faces() []Face {
+	faces := []Face{}
+	for _, group := range m.Groups {
+		faces = append(faces, group.Faces...)
+	}
+	return faces
+}
+
+// Bounds returns the corners of the axis aligned box around every face
+func (m *Mesh) Bounds() (mymath.Vector3, mymath.Vector3) {
+	lo := mymath.Vector3{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)}
+	hi := mymath.Vector3{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)}
+
+	for _, group := range m.Groups {
+		for _, face := range group.Faces {
+			for _, v := range face {
+				lo = mymath.Vector3{X: math.Min(lo.X, v.Position.X), Y: math.Min(lo.Y, v.Position.Y), Z: math.Min(lo.Z, v.Position.Z)}
+				hi = mymath.Vector3{X: math.Max(hi.X, v.Position.X), Y: math.Max(hi.Y, v.Position.Y), Z: math.Max(hi.Z, v.Position.Z)}
+			}
+		}
+	}
+
+	return lo, hi
+}
+
+func LoadOBJ(path string) (*Mesh, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	m, err := ParseOBJ(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// ParseOBJ reads the geometry statements (v, vn, vt, f, o, g) of a Wavefront OBJ file.
+// Polygons are triangulated as fans and negative indices count back from the most recent
+// vertex. Vertex colors written as "v x y z r g b" are kept. Statements that do not describe geometry, such as materials, are ignored.
+func ParseOBJ(r io.Reader) (*Mesh, error) {
+	positions := []mymath.Vector3{}
+	colors := map[int]mymath.Color3{}
+	normals := []mymath.Vector3{}
+	uvs := []mymath.Vector2{}
+
+	m := &Mesh{}
+	group := &Group{}
+	object := ""
+
+	// Start a new group only when faces have been added to the current one
+	startGroup := func(name string) {
+		if len(group.Faces) > 0 {
+			m.Groups = append(m.Groups, group)
+		}
+		group = &Group{Object: object, Name: name}
+	}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "v":
+			values, err := parseFloats(fields[1:], 3)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: vertex: %w", line, err)
+			}
+			positions = append(positions, mymath.Vector3{X: values[0], Y: values[1], Z: values[2]})
+
+			if len(fields) >= 7 {
+				rgb, err := parseFloats(fields[4:], 3)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: vertex color: %w", line, err)
+				}
+				colors[len(positions)-1] = mymath.Color3{R: rgb[0], G: rgb[1], B: rgb[2]}
+			}
+
+		case "vn":
+			values, err := parseFloats(fields[1:], 3)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: normal: %w", line, err)
+			}
+			normals = append(normals, mymath.Vector3{X: values[0], Y: values[1], Z: values[2]})
+
+		case "vt":
+			values, err := parseFloats(fields[1:], 2)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: texture coordinate: %w", line, err)
+			}
+			uvs = append(uvs, mymath.Vector2{X: values[0], Y: values[1]})
+
+		case "f":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("line %d: face needs at least 3 vertices, got %d", line, len(fields)-1)
+			}
+
+			polygon := make([]Vertex, len(fields)-1)
+			for i, field := range fields[1:] {
+				v, err := parseFaceVertex(field, positions, colors, normals, uvs)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: face vertex %q: %w", line, field, err)
+				}
+				polygon[i] = v
+			}
+
+			for i := 1; i < len(polygon)-1; i++ {
+				group.Faces = append(group.Faces, Face{polygon[0], polygon[i], polygon[i+1]})
+			}
+
+		case "o":
+			object = strings.Join(fields[1:], " ")
+			startGroup("")
+
+		case "g":
+			startGroup(strings.Join(fields[1:], " "))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	startGroup("")
+
+	return m, nil
+}
+
+func parseFloats(fields []string, count int) ([]float64, error) {
+	if len(fields) < count {
+		return nil, fmt.Errorf("expected %d values, got %d", count, len(fields))
+	}
+
+	values := make([]float64, count)
+	for i := range count {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// parseFaceVertex resolves a v, v/vt, v//vn or v/vt/vn reference
+func parseFaceVertex(field string, positions []mymath.Vector3, colors map[int]mymath.Color3, normals []mymath.Vector3, uvs []mymath.Vector2) (Vertex, error) {
+	parts := strings.Split(field, "/")
+	if len(parts) > 3 {
+		return Vertex{}, fmt.Errorf("too many components")
+	}
+
+	v := Vertex{}
+
+	i, err := resolveIndex(parts[0], len(positions))
+	if err != nil {
+		return Vertex{}, fmt.Errorf("position: %w", err)
+	}
+	v.Position = positions[i]
+	v.Color, v.HasColor = colors[i]
+
+	if len(parts) > 1 && parts[1] != "" {
+		i, err := resolveIndex(parts[1], len(uvs))
+		if err != nil {
+			return Vertex{}, fmt.Errorf("texture coordinate: %w", err)
+		}
+		v.UV = uvs[i]
+		v.HasUV = true
+	}
+
+	if len(parts) > 2 && parts[2] != "" {
+		i, err := resolveIndex(parts[2], len(normals))
+		if err != nil {
+			return Vertex{}, fmt.Errorf("normal: %w", err)
+		}
+		v.Normal = normals[i]
+		v.HasNormal = true
+	}
+
+	return v, nil
+}
+
+// resolveIndex turns a 1 based (or negative, relative) OBJ index into a slice index
+func resolveIndex(field string, count int) (int, error) {
+	index, err := strconv.Atoi(field)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case index > 0 && index <= count:
+		return index - 1, nil
+	case index < 0 && -index <= count:
+		return count + index, nil
+	}
+
+	return 0, fmt.Errorf("index %d out of range (%d defined)", index, count)
+}
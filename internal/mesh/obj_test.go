@@ -0,0 +1,232 @@
+package mesh
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// numbered defines vertices 1 to 5, each with its own number as x
+const numbered = `v 1 0 0
+v 2 0 0
+v 3 0 0
+v 4 0 0
+v 5 0 0
+`
+
+// corners lists the vertex numbers of each face, read back from x
+func corners(faces []Face) [][3]int {
+	var got [][3]int
+	for _, f := range faces {
+		got = append(got, [3]int{int(f[0].Position.X), int(f[1].Position.X), int(f[2].Position.X)})
+	}
+	return got
+}
+
+func TestParseOBJFaces(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  string
+		want [][3]int
+	}{
+		{"triangle", "f 1 2 3", [][3]int{{1, 2, 3}}},
+		{"quad", "f 1 2 3 4", [][3]int{{1, 2, 3}, {1, 3, 4}}},
+		{"pentagon", "f 5 4 3 2 1", [][3]int{{5, 4, 3}, {5, 3, 2}, {5, 2, 1}}},
+		{"negative", "f -3 -2 -1", [][3]int{{3, 4, 5}}},
+		{"mixed", "f 1 -1 2", [][3]int{{1, 5, 2}}},
+		{"texture coordinates", "vt 0 0\nvt 1 0\nvt 0 1\nf 1/1 2/2 3/3", [][3]int{{1, 2, 3}}},
+		{"normals", "vn 0 0 1\nf 1//1 2//1 3//1", [][3]int{{1, 2, 3}}},
+		{"everything", "vt 0 0\nvn 0 0 1\nf 1/1/1 2/1/1 3/1/1", [][3]int{{1, 2, 3}}},
+		{"comments and blank lines", "# a triangle\n\nf 1 2 3 # the only one\n# f 1 2 4", [][3]int{{1, 2, 3}}},
+		{"other statements ignored", "mtllib a.mtl\nusemtl red\ns 1\nf 1 2 3", [][3]int{{1, 2, 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseOBJ(strings.NewReader(numbered + tt.obj))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := corners(m.Faces()); !slices.Equal(got, tt.want) {
+				t.Errorf("faces %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOBJNegativeIndicesCountBackFromHere(t *testing.T) {
+	// -1 is the most recent vertex when the face is read, not the last in the file
+	m, err := ParseOBJ(strings.NewReader("v 1 0 0\nv 2 0 0\nv 3 0 0\nf -3 -2 -1\nv 4 0 0\nf -3 -2 -1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := corners(m.Faces()), [][3]int{{1, 2, 3}, {2, 3, 4}}; !slices.Equal(got, want) {
+		t.Errorf("faces %v, want %v", got, want)
+	}
+}
+
+func TestParseOBJAttributes(t *testing.T) {
+	obj := `v 0 0 0 1 0 0
+v 1 0 0
+v 0 1 0
+vt 0.25 0.75
+vt 0.5 0.5
+vn 0 0 1
+vn 0 1 0
+f 1/2/1 2//2 3/1
+`
+	m, err := ParseOBJ(strings.NewReader(obj))
+	if err != nil {
+		t.Fatal(err)
+	}
+	faces := m.Faces()
+	if len(faces) != 1 {
+		t.Fatalf("%d faces, want 1", len(faces))
+	}
+
+	want := Face{
+		{Position: mymath.Vector3{}, UV: mymath.Vector2{X: 0.5, Y: 0.5}, Normal: mymath.Vector3{Z: 1}, Color: mymath.Color3{R: 1}, HasUV: true, HasNormal: true, HasColor: true},
+		{Position: mymath.Vector3{X: 1}, Normal: mymath.Vector3{Y: 1}, HasNormal: true},
+		{Position: mymath.Vector3{Y: 1}, UV: mymath.Vector2{X: 0.25, Y: 0.75}, HasUV: true},
+	}
+	for i := range want {
+		if faces[0][i] != want[i] {
+			t.Errorf("vertex %d is %+v, want %+v", i, faces[0][i], want[i])
+		}
+	}
+}
+
+func TestParseOBJGroups(t *testing.T) {
+	obj := numbered + `f 1 2 3
+o box
+g lid
+f 1 2 3
+f 1 2 4
+g empty
+g base
+f 2 3 4
+o ball
+f 3 4 5
+g
+f 1 3 5
+`
+	m, err := ParseOBJ(strings.NewReader(obj))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		object, name string
+		faces        [][3]int
+	}{
+		{"", "", [][3]int{{1, 2, 3}}},
+		{"box", "lid", [][3]int{{1, 2, 3}, {1, 2, 4}}},
+		{"box", "base", [][3]int{{2, 3, 4}}},
+		{"ball", "", [][3]int{{3, 4, 5}}},
+		{"ball", "", [][3]int{{1, 3, 5}}},
+	}
+	if len(m.Groups) != len(want) {
+		t.Fatalf("%d groups, want %d", len(m.Groups), len(want))
+	}
+	for i, w := range want {
+		g := m.Groups[i]
+		if g.Object != w.object || g.Name != w.name || !slices.Equal(corners(g.Faces), w.faces) {
+			t.Errorf("group %d is %q %q with %v, want %q %q with %v", i, g.Object, g.Name, corners(g.Faces), w.object, w.name, w.faces)
+		}
+	}
+
+	if got := len(m.Faces()); got != 6 {
+		t.Errorf("Faces returned %d, want all 6", got)
+	}
+}
+
+func TestParseOBJErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  string
+		want string
+	}{
+		{"position past the end", "f 1 2 6", `line 6: face vertex "6": position: index 6 out of range (5 defined)`},
+		{"position zero", "f 0 1 2", `line 6: face vertex "0": position: index 0 out of range (5 defined)`},
+		{"negative past the start", "f -6 1 2", `line 6: face vertex "-6": position: index -6 out of range (5 defined)`},
+		{"texture coordinate", "vt 0 0\nf 1/2 2/1 3/1", `line 7: face vertex "1/2": texture coordinate: index 2 out of range (1 defined)`},
+		{"normal", "f 1//1 2 3", `line 6: face vertex "1//1": normal: index 1 out of range (0 defined)`},
+		{"not a number", "f a 2 3", `line 6: face vertex "a": position: strconv.Atoi`},
+		{"too many components", "f 1/1/1/1 2 3", `line 6: face vertex "1/1/1/1": too many components`},
+		{"too few vertices", "f 1 2", "line 6: face needs at least 3 vertices, got 2"},
+		{"short vertex", "v 1 2", "line 6: vertex: expected 3 values, got 2"},
+		{"bad vertex", "v 1 x 2", "line 6: vertex: strconv.ParseFloat"},
+		{"bad color", "v 1 2 3 1 x 0", "line 6: vertex color: strconv.ParseFloat"},
+		{"short normal", "vn 1", "line 6: normal: expected 3 values, got 1"},
+		{"short texture coordinate", "vt 1", "line 6: texture coordinate: expected 2 values, got 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOBJ(strings.NewReader(numbered + tt.obj))
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveIndex(t *testing.T) {
+	tests := []struct {
+		field string
+		want  int
+		ok    bool
+	}{
+		{"1", 0, true},
+		{"3", 2, true},
+		{"4", 0, false},
+		{"0", 0, false},
+		{"-1", 2, true},
+		{"-3", 0, true},
+		{"-4", 0, false},
+		{"", 0, false},
+		{"1.5", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveIndex(tt.field, 3)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("resolveIndex(%q, 3) = %d, %v, want %d", tt.field, got, err, tt.want)
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	m, err := ParseOBJ(strings.NewReader("v -1 2 0\nv 3 -4 5\nv 0 0 -6\nv 100 100 100\nf 1 2 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Vertices no face uses are left out
+	lo, hi := m.Bounds()
+	if want := (mymath.Vector3{X: -1, Y: -4, Z: -6}); lo != want {
+		t.Errorf("low corner %v, want %v", lo, want)
+	}
+	if want := (mymath.Vector3{X: 3, Y: 2, Z: 5}); hi != want {
+		t.Errorf("high corner %v, want %v", hi, want)
+	}
+}
+
+func TestLoadOBJ(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bad.obj")
+	if err := os.WriteFile(path, []byte("f 1 2 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadOBJ(path); err == nil || !strings.HasPrefix(err.Error(), path+": line 1:") {
+		t.Errorf("got %v, want an error naming the file and line", err)
+	}
+	if _, err := LoadOBJ(filepath.Join(dir, "missing.obj")); err == nil {
+		t.Error("loading a missing file succeeded")
+	}
+}
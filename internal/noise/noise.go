@@ -0,0 +1,125 @@
+// Package noise provides seeded gradient, value and cellular noise for procedural textures.
+// The same seed always produces the same noise.
+package noise
+
+import (
+	"math"
+	"math/rand"
+)
+
+type Source2 interface {
+	Noise2(x, y float64) float64
+}
+
+type Source3 interface {
+	Noise3(x, y, z float64) float64
+}
+
+// permutation returns a shuffled 0..255 table repeated twice, so lookups of the form
+// perm[perm[i]+j] never need wrapping
+func permutation(seed int64) [512]int {
+	var perm [512]int
+	shuffled := rand.New(rand.NewSource(seed)).Perm(256)
+
+	for i := range perm {
+		perm[i] = shuffled[i&255]
+	}
+
+	return perm
+}
+
+// hash mixes a seed and lattice coordinates into 64 well distributed bits
+func hash(seed int64, coords ...int) uint64 {
+	h := uint64(seed) ^ 0x9e3779b97f4a7c15
+
+	for _, c := range coords {
+		h ^= uint64(c)
+		h *= 0xbf58476d1ce4e5b9
+		h ^= h >> 31
+		h *= 0x94d049bb133111eb
+		h ^= h >> 29
+	}
+
+	return h
+}
+
+// unit maps a hash to [0, 1)
+func unit(h uint64) float64 {
+	return float64(h>>11) / (1 << 53)
+}
+
+// fade is Perlin's quintic curve, with zero first and second derivatives at 0 and 1
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func lerp(t, a, b float64) float64 {
+	return a + t*(b-a)
+}
+
+func floor(x float64) int {
+	return int(math.Floor(x))
+}
+
+// FBM2 sums octaves of noise, each at lacunarity times the frequency and gain times the
+// amplitude of the previous one. The result is normalized to the range of the source.
+func FBM2(source Source2, x, y float64, octaves int, lacunarity, gain float64) float64 {
+	sum := 0.0
+	amplitude := 1.0
+	total := 0.0
+
+	for range octaves {
+		sum += amplitude * source.Noise2(x, y)
+		total += amplitude
+		amplitude *= gain
+		x *= lacunarity
+		y *= lacunarity
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+func FBM3(source Source3, x, y, z float64, octaves int, lacunarity, gain float64) float64 {
+	sum := 0.0
+	amplitude := 1.0
+	total := 0.0
+
+	for range octaves {
+		sum += amplitude * source.Noise3(x, y, z)
+		total += amplitude
+		amplitude *= gain
+		x *= lacunarity
+		y *= lacunarity
+		z *= lacunarity
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// Turbulence2 is FBM2 of the absolute noise value, giving sharp creases where the noise
+// crosses zero. The result is between 0 and 1 for sources in [-1, 1].
+func Turbulence2(source Source2, x, y float64, octaves int, lacunarity, gain float64) float64 {
+	return FBM2(absolute2{source}, x, y, octaves, lacunarity, gain)
+}
+
+func Turbulence3(source Source3, x, y, z float64, octaves int, lacunarity, gain float64) float64 {
+	return FBM3(absolute3{source}, x, y, z, octaves, lacunarity, gain)
+}
+
+type absolute2 struct{ source Source2 }
+
+func (a absolute2) Noise2(x, y float64) float64 {
+	return math.Abs(a.source.Noise2(x, y))
+}
+
+type absolute3 struct{ source Source3 }
+
+func (a absolute3) Noise3(x, y, z float64) float64 {
+	return math.Abs(a.source.Noise3(x, y, z))
+}
@@ -0,0 +1,157 @@
+package noise
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+type source interface {
+	Source2
+	Source3
+}
+
+func sources(seed int64) map[string]source {
+	return map[string]source{
+		"perlin":  NewPerlin(seed),
+		"simplex": NewSimplex(seed),
+		"value":   NewValue(seed),
+		"worley":  NewWorley(seed),
+	}
+}
+
+// points are scattered either side of the origin, away from the lattice
+func points(n int) [][3]float64 {
+	random := rand.New(rand.NewSource(1))
+	p := make([][3]float64, n)
+	for i := range p {
+		p[i] = [3]float64{random.Float64()*200 - 100, random.Float64()*200 - 100, random.Float64()*200 - 100}
+	}
+	return p
+}
+
+func TestSeed(t *testing.T) {
+	a, b, other := sources(7), sources(7), sources(8)
+
+	for name := range a {
+		differs := false
+		for _, p := range points(100) {
+			x, y, z := p[0], p[1], p[2]
+			if a[name].Noise2(x, y) != b[name].Noise2(x, y) || a[name].Noise3(x, y, z) != b[name].Noise3(x, y, z) {
+				t.Fatalf("%s: the same seed gave different noise at %v", name, p)
+			}
+			if a[name].Noise3(x, y, z) != other[name].Noise3(x, y, z) {
+				differs = true
+			}
+		}
+		if !differs {
+			t.Errorf("%s: seeds 7 and 8 gave the same noise", name)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		low    float64
+		high   float64
+		spread float64 // The values seen should cover at least this much of the range
+		noise2 func(x, y float64) float64
+		noise3 func(x, y, z float64) float64
+	}{
+		{"perlin", -1, 1, 1, NewPerlin(3).Noise2, NewPerlin(3).Noise3},
+		{"simplex", -1, 1, 1, NewSimplex(3).Noise2, NewSimplex(3).Noise3},
+		{"value", -1, 1, 1, NewValue(3).Noise2, NewValue(3).Noise3},
+		{"worley 2D", 0, math.Sqrt2, 0.5, NewWorley(3).Noise2, nil},
+		{"worley 3D", 0, math.Sqrt(3), 0.5, nil, NewWorley(3).Noise3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := func(dimensions string, values []float64) {
+				low, high := math.Inf(1), math.Inf(-1)
+				for _, v := range values {
+					low, high = math.Min(low, v), math.Max(high, v)
+				}
+				if low < tt.low || high > tt.high {
+					t.Errorf("%s: values from %v to %v, outside %v to %v", dimensions, low, high, tt.low, tt.high)
+				}
+				if high-low < tt.spread {
+					t.Errorf("%s: values only from %v to %v", dimensions, low, high)
+				}
+			}
+
+			var values2, values3 []float64
+			for _, p := range points(20000) {
+				if tt.noise2 != nil {
+					values2 = append(values2, tt.noise2(p[0], p[1]))
+				}
+				if tt.noise3 != nil {
+					values3 = append(values3, tt.noise3(p[0], p[1], p[2]))
+				}
+			}
+			if tt.noise2 != nil {
+				check("2D", values2)
+			}
+			if tt.noise3 != nil {
+				check("3D", values3)
+			}
+		})
+	}
+}
+
+// constant is the same everywhere
+type constant float64
+
+func (c constant) Noise2(x, y float64) float64    { return float64(c) }
+func (c constant) Noise3(x, y, z float64) float64 { return float64(c) }
+
+func TestFBM(t *testing.T) {
+	perlin := NewPerlin(1)
+
+	tests := []struct {
+		name    string
+		source  source
+		octaves int
+		want    func(x, y, z float64) (float64, float64)
+	}{
+		{"no octaves", perlin, 0, func(x, y, z float64) (float64, float64) { return 0, 0 }},
+		{"one octave is the source", perlin, 1, func(x, y, z float64) (float64, float64) {
+			return perlin.Noise2(x, y), perlin.Noise3(x, y, z)
+		}},
+		{"normalized", constant(0.75), 6, func(x, y, z float64) (float64, float64) { return 0.75, 0.75 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, p := range points(50) {
+				want2, want3 := tt.want(p[0], p[1], p[2])
+				if got := FBM2(tt.source, p[0], p[1], tt.octaves, 2, 0.5); math.Abs(got-want2) > 1e-12 {
+					t.Errorf("FBM2 at %v is %v, want %v", p, got, want2)
+				}
+				if got := FBM3(tt.source, p[0], p[1], p[2], tt.octaves, 2, 0.5); math.Abs(got-want3) > 1e-12 {
+					t.Errorf("FBM3 at %v is %v, want %v", p, got, want3)
+				}
+			}
+		})
+	}
+}
+
+func TestFBMRange(t *testing.T) {
+	for name, s := range sources(5) {
+		if name == "worley" {
+			continue
+		}
+		for _, p := range points(2000) {
+			if v := FBM3(s, p[0], p[1], p[2], 5, 2, 0.5); v < -1 || v > 1 {
+				t.Fatalf("%s: FBM3 at %v is %v", name, p, v)
+			}
+			if v := Turbulence2(s, p[0], p[1], 5, 2, 0.5); v < 0 || v > 1 {
+				t.Fatalf("%s: Turbulence2 at %v is %v", name, p, v)
+			}
+			if v := Turbulence3(s, p[0], p[1], p[2], 5, 2, 0.5); v < 0 || v > 1 {
+				t.Fatalf("%s: Turbulence3 at %v is %v", name, p, v)
+			}
+		}
+	}
+}
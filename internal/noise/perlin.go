@@ -0,0 +1,115 @@
+package noise
+
+// Perlin is Ken Perlin's improved gradient noise. Values are in [-1, 1] and zero at every
+// integer lattice point.
+type Perlin struct {
+	perm [512]int
+}
+
+func NewPerlin(seed int64) *Perlin {
+	return &Perlin{perm: permutation(seed)}
+}
+
+// grad2 picks one of 8 gradient directions
+func grad2(hash int, x, y float64) float64 {
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
+
+// grad3 picks one of the 12 cube edge directions (with 4 repeated to make 16)
+func grad3(hash int, x, y, z float64) float64 {
+	h := hash & 15
+
+	u := y
+	if h < 8 {
+		u = x
+	}
+
+	v := z
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	}
+
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+
+	return u + v
+}
+
+func (p *Perlin) Noise2(x, y float64) float64 {
+	xi := floor(x)
+	yi := floor(y)
+	x -= float64(xi)
+	y -= float64(yi)
+	xi &= 255
+	yi &= 255
+
+	u := fade(x)
+	v := fade(y)
+
+	aa := p.perm[p.perm[xi]+yi]
+	ab := p.perm[p.perm[xi]+yi+1]
+	ba := p.perm[p.perm[xi+1]+yi]
+	bb := p.perm[p.perm[xi+1]+yi+1]
+
+	return lerp(v,
+		lerp(u, grad2(aa, x, y), grad2(ba, x-1, y)),
+		lerp(u, grad2(ab, x, y-1), grad2(bb, x-1, y-1)),
+	)
+}
+
+func (p *Perlin) Noise3(x, y, z float64) float64 {
+	xi := floor(x)
+	yi := floor(y)
+	zi := floor(z)
+	x -= float64(xi)
+	y -= float64(yi)
+	z -= float64(zi)
+	xi &= 255
+	yi &= 255
+	zi &= 255
+
+	u := fade(x)
+	v := fade(y)
+	w := fade(z)
+
+	a := p.perm[xi] + yi
+	aa := p.perm[a] + zi
+	ab := p.perm[a+1] + zi
+	b := p.perm[xi+1] + yi
+	ba := p.perm[b] + zi
+	bb := p.perm[b+1] + zi
+
+	return lerp(w,
+		lerp(v,
+			lerp(u, grad3(p.perm[aa], x, y, z), grad3(p.perm[ba], x-1, y, z)),
+			lerp(u, grad3(p.perm[ab], x, y-1, z), grad3(p.perm[bb], x-1, y-1, z)),
+		),
+		lerp(v,
+			lerp(u, grad3(p.perm[aa+1], x, y, z-1), grad3(p.perm[ba+1], x-1, y, z-1)),
+			lerp(u, grad3(p.perm[ab+1], x, y-1, z-1), grad3(p.perm[bb+1], x-1, y-1, z-1)),
+		),
+	)
+}
@@ -0,0 +1,25 @@
+package noise
+
+import "testing"
+
+func TestPerlinLattice(t *testing.T) {
+	p := NewPerlin(42)
+
+	for x := -3; x <= 3; x++ {
+		for y := -3; y <= 3; y++ {
+			if v := p.Noise2(float64(x), float64(y)); v != 0 {
+				t.Errorf("Noise2(%d, %d) = %v, want 0", x, y, v)
+			}
+			for z := -3; z <= 3; z++ {
+				if v := p.Noise3(float64(x), float64(y), float64(z)); v != 0 {
+					t.Errorf("Noise3(%d, %d, %d) = %v, want 0", x, y, z, v)
+				}
+			}
+		}
+	}
+
+	// Off the lattice it isn't all zero
+	if p.Noise2(0.5, 0.25) == 0 && p.Noise2(1.3, 2.7) == 0 && p.Noise3(0.5, 0.25, 0.75) == 0 {
+		t.Error("noise is zero between lattice points")
+	}
+}
@@ -0,0 +1,137 @@
+package noise
+
+import "math"
+
+// Simplex is Ken Perlin's simplex noise, which sums gradient contributions from the corners of
+// a triangle (2D) or tetrahedron (3D) instead of a square or cube. It has fewer directional
+// artifacts than Perlin and values in [-1, 1].
+type Simplex struct {
+	perm [512]int
+}
+
+func NewSimplex(seed int64) *Simplex {
+	return &Simplex{perm: permutation(seed)}
+}
+
+var simplexGradients3 = [12][3]float64{
+	{1, 1, 0}, {-1, 1, 0}, {1, -1, 0}, {-1, -1, 0},
+	{1, 0, 1}, {-1, 0, 1}, {1, 0, -1}, {-1, 0, -1},
+	{0, 1, 1}, {0, -1, 1}, {0, 1, -1}, {0, -1, -1},
+}
+
+var (
+	skew2   = 0.5 * (math.Sqrt(3) - 1)
+	unskew2 = (3 - math.Sqrt(3)) / 6
+	skew3   = 1.0 / 3
+	unskew3 = 1.0 / 6
+)
+
+func (s *Simplex) corner2(hash int, x, y float64) float64 {
+	t := 0.5 - x*x - y*y
+	if t < 0 {
+		return 0
+	}
+
+	g := simplexGradients3[hash%12]
+	t *= t
+	return t * t * (g[0]*x + g[1]*y)
+}
+
+func (s *Simplex) Noise2(x, y float64) float64 {
+	// Skew the input space to find which simplex cell we are in
+	skew := (x + y) * skew2
+	i := floor(x + skew)
+	j := floor(y + skew)
+
+	unskew := float64(i+j) * unskew2
+	x0 := x - (float64(i) - unskew)
+	y0 := y - (float64(j) - unskew)
+
+	// Upper or lower triangle of the skewed square
+	i1, j1 := 0, 1
+	if x0 > y0 {
+		i1, j1 = 1, 0
+	}
+
+	x1 := x0 - float64(i1) + unskew2
+	y1 := y0 - float64(j1) + unskew2
+	x2 := x0 - 1 + 2*unskew2
+	y2 := y0 - 1 + 2*unskew2
+
+	ii := i & 255
+	jj := j & 255
+
+	n0 := s.corner2(s.perm[ii+s.perm[jj]], x0, y0)
+	n1 := s.corner2(s.perm[ii+i1+s.perm[jj+j1]], x1, y1)
+	n2 := s.corner2(s.perm[ii+1+s.perm[jj+1]], x2, y2)
+
+	// Scale to [-1, 1]
+	return 70 * (n0 + n1 + n2)
+}
+
+func (s *Simplex) corner3(hash int, x, y, z float64) float64 {
+	t := 0.6 - x*x - y*y - z*z
+	if t < 0 {
+		return 0
+	}
+
+	g := simplexGradients3[hash%12]
+	t *= t
+	return t * t * (g[0]*x + g[1]*y + g[2]*z)
+}
+
+func (s *Simplex) Noise3(x, y, z float64) float64 {
+	skew := (x + y + z) * skew3
+	i := floor(x + skew)
+	j := floor(y + skew)
+	k := floor(z + skew)
+
+	unskew := float64(i+j+k) * unskew3
+	x0 := x - (float64(i) - unskew)
+	y0 := y - (float64(j) - unskew)
+	z0 := z - (float64(k) - unskew)
+
+	// Find which of the six tetrahedra of the skewed cube we are in
+	var i1, j1, k1, i2, j2, k2 int
+	if x0 >= y0 {
+		switch {
+		case y0 >= z0:
+			i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 1, 0
+		case x0 >= z0:
+			i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 0, 1
+		default:
+			i1, j1, k1, i2, j2, k2 = 0, 0, 1, 1, 0, 1
+		}
+	} else {
+		switch {
+		case y0 < z0:
+			i1, j1, k1, i2, j2, k2 = 0, 0, 1, 0, 1, 1
+		case x0 < z0:
+			i1, j1, k1, i2, j2, k2 = 0, 1, 0, 0, 1, 1
+		default:
+			i1, j1, k1, i2, j2, k2 = 0, 1, 0, 1, 1, 0
+		}
+	}
+
+	x1 := x0 - float64(i1) + unskew3
+	y1 := y0 - float64(j1) + unskew3
+	z1 := z0 - float64(k1) + unskew3
+	x2 := x0 - float64(i2) + 2*unskew3
+	y2 := y0 - float64(j2) + 2*unskew3
+	z2 := z0 - float64(k2) + 2*unskew3
+	x3 := x0 - 1 + 3*unskew3
+	y3 := y0 - 1 + 3*unskew3
+	z3 := z0 - 1 + 3*unskew3
+
+	ii := i & 255
+	jj := j & 255
+	kk := k & 255
+
+	n0 := s.corner3(s.perm[ii+s.perm[jj+s.perm[kk]]], x0, y0, z0)
+	n1 := s.corner3(s.perm[ii+i1+s.perm[jj+j1+s.perm[kk+k1]]], x1, y1, z1)
+	n2 := s.corner3(s.perm[ii+i2+s.perm[jj+j2+s.perm[kk+k2]]], x2, y2, z2)
+	n3 := s.corner3(s.perm[ii+1+s.perm[jj+1+s.perm[kk+1]]], x3, y3, z3)
+
+	// Scale to [-1, 1]
+	return 32 * (n0 + n1 + n2 + n3)
+}
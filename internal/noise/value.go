@@ -0,0 +1,47 @@
+package noise
+
+// Value noise smoothly interpolates random values stored at the integer lattice points.
+// It is cheaper than gradient noise but blockier. Values are in [-1, 1].
+type Value struct {
+	seed int64
+}
+
+func NewValue(seed int64) *Value {
+	return &Value{seed: seed}
+}
+
+func (v *Value) lattice(coords ...int) float64 {
+	return unit(hash(v.seed, coords...))*2 - 1
+}
+
+func (v *Value) Noise2(x, y float64) float64 {
+	xi := floor(x)
+	yi := floor(y)
+	u := fade(x - float64(xi))
+	w := fade(y - float64(yi))
+
+	return lerp(w,
+		lerp(u, v.lattice(xi, yi), v.lattice(xi+1, yi)),
+		lerp(u, v.lattice(xi, yi+1), v.lattice(xi+1, yi+1)),
+	)
+}
+
+func (v *Value) Noise3(x, y, z float64) float64 {
+	xi := floor(x)
+	yi := floor(y)
+	zi := floor(z)
+	u := fade(x - float64(xi))
+	w := fade(y - float64(yi))
+	t := fade(z - float64(zi))
+
+	return lerp(t,
+		lerp(w,
+			lerp(u, v.lattice(xi, yi, zi), v.lattice(xi+1, yi, zi)),
+			lerp(u, v.lattice(xi, yi+1, zi), v.lattice(xi+1, yi+1, zi)),
+		),
+		lerp(w,
+			lerp(u, v.lattice(xi, yi, zi+1), v.lattice(xi+1, yi, zi+1)),
+			lerp(u, v.lattice(xi, yi+1, zi+1), v.lattice(xi+1, yi+1, zi+1)),
+		),
+	)
+}
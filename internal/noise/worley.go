@@ -0,0 +1,60 @@
+package noise
+
+import "math"
+
+// Worley (cellular) noise scatters one random feature point in every lattice cell and returns
+// the distance to the nearest one. Values are 0 at the feature points and rarely exceed 1.
+type Worley struct {
+	seed int64
+}
+
+func NewWorley(seed int64) *Worley {
+	return &Worley{seed: seed}
+}
+
+func (w *Worley) Noise2(x, y float64) float64 {
+	xi := floor(x)
+	yi := floor(y)
+	nearest := math.Inf(1)
+
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			cx := xi + dx
+			cy := yi + dy
+			h := hash(w.seed, cx, cy)
+
+			px := float64(cx) + unit(h)
+			py := float64(cy) + unit(hash(int64(h), 1))
+
+			nearest = math.Min(nearest, (px-x)*(px-x)+(py-y)*(py-y))
+		}
+	}
+
+	return math.Sqrt(nearest)
+}
+
+func (w *Worley) Noise3(x, y, z float64) float64 {
+	xi := floor(x)
+	yi := floor(y)
+	zi := floor(z)
+	nearest := math.Inf(1)
+
+	for dz := -1; dz <= 1; dz++ {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				cx := xi + dx
+				cy := yi + dy
+				cz := zi + dz
+				h := hash(w.seed, cx, cy, cz)
+
+				px := float64(cx) + unit(h)
+				py := float64(cy) + unit(hash(int64(h), 1))
+				pz := float64(cz) + unit(hash(int64(h), 2))
+
+				nearest = math.Min(nearest, (px-x)*(px-x)+(py-y)*(py-y)+(pz-z)*(pz-z))
+			}
+		}
+	}
+
+	return math.Sqrt(nearest)
+}
@@ -0,0 +1,23 @@
+package noise
+
+import "testing"
+
+func TestWorleyFeaturePoints(t *testing.T) {
+	w := NewWorley(9)
+
+	for cx := -2; cx <= 2; cx++ {
+		for cy := -2; cy <= 2; cy++ {
+			h := hash(w.seed, cx, cy)
+			x, y := float64(cx)+unit(h), float64(cy)+unit(hash(int64(h), 1))
+			if v := w.Noise2(x, y); v != 0 {
+				t.Errorf("Noise2 at the feature point of cell %d,%d is %v", cx, cy, v)
+			}
+
+			h = hash(w.seed, cx, cy, 1)
+			x, y, z := float64(cx)+unit(h), float64(cy)+unit(hash(int64(h), 1)), 1+unit(hash(int64(h), 2))
+			if v := w.Noise3(x, y, z); v != 0 {
+				t.Errorf("Noise3 at the feature point of cell %d,%d,1 is %v", cx, cy, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// Package path describes 2D outlines made of lines, Bézier curves and arcs, and flattens them
+// into polylines for drawing.
+package path
+
+import (
+	"math"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// Segment kinds
+const (
+	Move  = iota // Starts a new subpath at End
+	Line         // Straight to End
+	Quad         // Quadratic Bézier through Control[0] to End
+	Cubic        // Cubic Bézier through Control[0] and Control[1] to End
+	Close        // Straight back to the start of the subpath
+)
+
+// maxDepth limits how many times a curve is split in half while flattening
+const maxDepth = 16
+
+// MinTolerance is the smallest tolerance Flatten works to
+const MinTolerance = 1e-6
+
+type Segment struct {
+	Kind    int
+	Control [2]mymath.Vector2
+	End     mymath.Vector2
+}
+
+// Path is a sequence of subpaths, each starting with a MoveTo. Arcs are stored as cubic curves,
+// so transforming the points of a path transforms its shape exactly for any affine transform.
+type Path struct {
+	Segments []Segment
+	start    mymath.Vector2 // Of the current subpath
+	current  mymath.Vector2
+}
+
+func NewPath() *Path {
+	return &Path{}
+}
+
+func (p *Path) MoveTo(to mymath.Vector2) *Path {
+	p.Segments = append(p.Segments, Segment{Kind: Move, End: to})
+	p.start, p.current = to, to
+	return p
+}
+
+// begin starts a subpath at the current point if the last one was closed or there is none
+func (p *Path) begin() {
+	if n := len(p.Segments); n == 0 || p.Segments[n-1].Kind == Close {
+		p.MoveTo(p.current)
+	}
+}
+
+func (p *Path) LineTo(to mymath.Vector2) *Path {
+	p.begin()
+	p.Segments = append(p.Segments, Segment{Kind: Line, End: to})
+	p.current = to
+	return p
+}
+
+func (p *Path) QuadTo(control, to mymath.Vector2) *Path {
+	p.begin()
+	p.Segments = append(p.Segments, Segment{Kind: Quad, Control: [2]mymath.Vector2{control}, End: to})
+	p.current = to
+	return p
+}
+
+func (p *Path) CubicTo(control1, control2, to mymath.Vector2) *Path {
+	p.begin()
+	p.Segments = append(p.Segments, Segment{Kind: Cubic, Control: [2]mymath.Vector2{control1, control2}, End: to})
+	p.current = to
+	return p
+}
+
+// ArcTo turns from the current point about centre by sweep radians, anticlockwise if sweep is
+// positive. The arc is made of cubic curves of at most a quarter turn each.
+func (p *Path) ArcTo(centre mymath.Vector2, sweep float64) *Path {
+	from := p.current.Subtract(centre)
+	radius := from.Magnitude()
+	angle := math.Atan2(from.Y, from.X)
+
+	pieces := int(math.Ceil(math.Abs(sweep) / (math.Pi / 2)))
+	step := sweep / float64(max(pieces, 1))
+
+	// Control points this far along the tangents keep the curve within 0.03% of the radius
+	handle := 4.0 / 3 * math.Tan(step/4) * radius
+
+	point := func(a float64) (mymath.Vector2, mymath.Vector2) {
+		sin, cos := math.Sincos(a)
+		return centre.Add(mymath.Vector2{X: radius * cos, Y: radius * sin}), mymath.Vector2{X: -sin, Y: cos}
+	}
+
+	for range pieces {
+		a, tangentA := point(angle)
+		b, tangentB := point(angle + step)
+		p.CubicTo(a.Add(tangentA.Multiply(handle)), b.Subtract(tangentB.Multiply(handle)), b)
+		angle += step
+	}
+	return p
+}
+
+// Close draws a line back to the start of the subpath and ends it
+func (p *Path) Close() *Path {
+	p.Segments = append(p.Segments, Segment{Kind: Close})
+	p.current = p.start
+	return p
+}
+
+// Circle adds a closed subpath around centre, anticlockwise
+func (p *Path) Circle(centre mymath.Vector2, radius float64) *Path {
+	return p.MoveTo(centre.Add(mymath.Vector2{X: radius})).ArcTo(centre, 2*math.Pi).Close()
+}
+
+// Transform returns a copy of the path with every point passed through f
+func (p *Path) Transform(f func(mymath.Vector2) mymath.Vector2) *Path {
+	t := &Path{Segments: make([]Segment, len(p.Segments)), start: f(p.start), current: f(p.current)}
+	for i, s := range p.Segments {
+		t.Segments[i] = s
+		if s.Kind == Close {
+			continue
+		}
+		t.Segments[i].End = f(s.End)
+		for j := range s.Control {
+			t.Segments[i].Control[j] = f(s.Control[j])
+		}
+	}
+	return t
+}
+
+// Contour is a subpath flattened to straight lines
+type Contour struct {
+	Points []mymath.Vector2
+	Closed bool
+}
+
+// Flatten turns each subpath into straight lines that stray no more than tolerance from the
+// curves. Curves are split where they bend, so gentle curves get few points. Tolerances below
+// MinTolerance, or NaN, use MinTolerance. Subpaths with a NaN or infinite point are left out.
+func (p *Path) Flatten(tolerance float64) []Contour {
+	if !(tolerance >= MinTolerance) {
+		tolerance = MinTolerance
+	}
+
+	var contours []Contour
+	var broken []bool // Whether each contour has a point that isn't finite
+	var current *Contour
+	var last mymath.Vector2
+
+	for _, s := range p.Segments {
+		if s.Kind == Move || current == nil {
+			contours = append(contours, Contour{})
+			broken = append(broken, false)
+			current = &contours[len(contours)-1]
+			if s.Kind != Move {
+				current.Points = append(current.Points, last)
+				broken[len(broken)-1] = !finite(last)
+			}
+		}
+		if s.Kind != Close && !finite(s.End, s.Control[0], s.Control[1]) {
+			broken[len(broken)-1] = true
+		}
+
+		switch s.Kind {
+		case Move, Line:
+			current.Points = append(current.Points, s.End)
+		case Quad:
+			current.Points = flattenCubic(current.Points, last, last.Add(s.Control[0].Subtract(last).Multiply(2.0/3)),
+				s.End.Add(s.Control[0].Subtract(s.End).Multiply(2.0/3)), s.End, tolerance, 0)
+		case Cubic:
+			current.Points = flattenCubic(current.Points, last, s.Control[0], s.Control[1], s.End, tolerance, 0)
+		case Close:
+			current.Closed = true
+			last = current.Points[0]
+			current = nil
+			continue
+		}
+		last = s.End
+	}
+
+	kept := contours[:0]
+	for i, c := range contours {
+		if !broken[i] {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// flattenCubic appends points along the cubic Bézier from a to d, not including a. A quadratic
+// curve is the cubic with its control point two thirds of the way along each handle.
+func flattenCubic(points []mymath.Vector2, a, b, c, d mymath.Vector2, tolerance float64, depth int) []mymath.Vector2 {
+	if depth >= maxDepth || !finite(a, b, c, d) || (distanceToLine(b, a, d) <= tolerance && distanceToLine(c, a, d) <= tolerance) {
+		return append(points, d)
+	}
+
+	// Split in half with de Casteljau's construction
+	ab, bc, cd := midpoint(a, b), midpoint(b, c), midpoint(c, d)
+	abc, bcd := midpoint(ab, bc), midpoint(bc, cd)
+	middle := midpoint(abc, bcd)
+
+	points = flattenCubic(points, a, ab, abc, middle, tolerance, depth+1)
+	return flattenCubic(points, middle, bcd, cd, d, tolerance, depth+1)
+}
+
+func finite(points ...mymath.Vector2) bool {
+	for _, p := range points {
+		if math.IsNaN(p.X) || math.IsNaN(p.Y) || math.IsInf(p.X, 0) || math.IsInf(p.Y, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func midpoint(a, b mymath.Vector2) mymath.Vector2 {
+	return a.Add(b).Multiply(0.5)
+}
+
+// distanceToLine is how far p is from the segment ab
+func distanceToLine(p, a, b mymath.Vector2) float64 {
+	ab := b.Subtract(a)
+	length := ab.Dot(ab)
+	if length == 0 {
+		return p.Subtract(a).Magnitude()
+	}
+	t := math.Min(math.Max(p.Subtract(a).Dot(ab)/length, 0), 1)
+	return p.Subtract(a.Add(ab.Multiply(t))).Magnitude()
+}
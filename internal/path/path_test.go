@@ -0,0 +1,122 @@
+package path
+
+import (
+	"math"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+func TestFlattenCircle(t *testing.T) {
+	centre, radius := mymath.Vector2{X: 3, Y: -2}, 10.0
+	circle := NewPath().Circle(centre, radius)
+
+	// The cubic arcs themselves stray this far from a true circle
+	arcError := 0.0003 * radius
+
+	previous := 0
+	for _, tolerance := range []float64{1, 0.25, 0.01} {
+		contours := circle.Flatten(tolerance)
+		if len(contours) != 1 || !contours[0].Closed {
+			t.Fatalf("tolerance %v: got %d contours, want one closed one", tolerance, len(contours))
+		}
+
+		points := contours[0].Points
+		if len(points) <= previous {
+			t.Errorf("tolerance %v: %d points, no more than %d at a looser tolerance", tolerance, len(points), previous)
+		}
+		previous = len(points)
+
+		for i, p := range points {
+			if d := math.Abs(p.Subtract(centre).Magnitude() - radius); d > arcError {
+				t.Errorf("tolerance %v: point %v is %v off the circle", tolerance, p, d)
+			}
+
+			// The sagitta, how far the circle bulges past each chord
+			chord := points[(i+1)%len(points)].Subtract(p).Magnitude()
+			if sagitta := radius - math.Sqrt(radius*radius-chord*chord/4); sagitta > tolerance+arcError {
+				t.Errorf("tolerance %v: chord from %v bulges %v", tolerance, p, sagitta)
+			}
+		}
+	}
+}
+
+func TestFlattenContours(t *testing.T) {
+	v := func(x, y float64) mymath.Vector2 { return mymath.Vector2{X: x, Y: y} }
+
+	p := NewPath().
+		MoveTo(v(0, 0)).LineTo(v(1, 0)).LineTo(v(1, 1)).Close().
+		LineTo(v(-1, 0)). // Carries on from the start of the closed subpath
+		MoveTo(v(5, 5)).LineTo(v(6, 5)).
+		MoveTo(v(7, 7)).Close()
+
+	want := []Contour{
+		{Points: []mymath.Vector2{v(0, 0), v(1, 0), v(1, 1)}, Closed: true},
+		{Points: []mymath.Vector2{v(0, 0), v(-1, 0)}},
+		{Points: []mymath.Vector2{v(5, 5), v(6, 5)}},
+		{Points: []mymath.Vector2{v(7, 7)}, Closed: true},
+	}
+
+	got := p.Flatten(0.1)
+	if len(got) != len(want) {
+		t.Fatalf("got %d contours, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].Closed != want[i].Closed || len(got[i].Points) != len(want[i].Points) {
+			t.Errorf("contour %d: got %v, want %v", i, got[i], want[i])
+			continue
+		}
+		for j := range want[i].Points {
+			if got[i].Points[j] != want[i].Points[j] {
+				t.Errorf("contour %d: got %v, want %v", i, got[i], want[i])
+				break
+			}
+		}
+	}
+}
+
+func TestFlattenLinesStayPut(t *testing.T) {
+	// A straight cubic needs no splitting
+	p := NewPath().MoveTo(mymath.Vector2{}).CubicTo(mymath.Vector2{X: 1}, mymath.Vector2{X: 2}, mymath.Vector2{X: 3})
+	if got := p.Flatten(0.01); len(got) != 1 || len(got[0].Points) != 2 {
+		t.Errorf("got %v, want the two end points", got)
+	}
+}
+
+func TestFlattenTolerance(t *testing.T) {
+	circle := NewPath().Circle(mymath.Vector2{}, 1)
+	smallest := len(circle.Flatten(MinTolerance)[0].Points)
+
+	for _, tolerance := range []float64{0, -1, math.NaN(), math.Inf(-1), MinTolerance / 10} {
+		contours := circle.Flatten(tolerance)
+		if len(contours) != 1 || len(contours[0].Points) != smallest {
+			t.Errorf("tolerance %v: got %d points, want %d as at MinTolerance", tolerance, len(contours[0].Points), smallest)
+		}
+	}
+}
+
+func TestFlattenNonFinite(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	good := mymath.Vector2{X: 1, Y: 1}
+
+	tests := []struct {
+		name string
+		path *Path
+	}{
+		{"NaN control", NewPath().MoveTo(mymath.Vector2{}).CubicTo(mymath.Vector2{X: nan}, good, good)},
+		{"infinite end", NewPath().MoveTo(mymath.Vector2{}).QuadTo(good, mymath.Vector2{Y: inf})},
+		{"NaN start", NewPath().MoveTo(mymath.Vector2{X: nan, Y: nan}).LineTo(good)},
+		{"NaN line", NewPath().MoveTo(mymath.Vector2{}).LineTo(mymath.Vector2{X: -inf})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The broken subpath is dropped and the one after it kept
+			tt.path.MoveTo(good).LineTo(mymath.Vector2{X: 2, Y: 2})
+			got := tt.path.Flatten(0.01)
+			if len(got) != 1 || len(got[0].Points) != 2 || got[0].Points[0] != good {
+				t.Errorf("got %v, want only the finite subpath", got)
+			}
+		})
+	}
+}
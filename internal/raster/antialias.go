@@ -0,0 +1,148 @@
+package raster
+
+import (
+	"image/color"
+	"math"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// coverageRows is how many scanlines FillPolygonSmooth samples in each row of pixels. Across a
+// scanline coverage is measured exactly.
+const coverageRows = 8
+
+// CoverFunc is called with the fraction of the pixel at x,y that a shape covers, from 0 to 1
+type CoverFunc func(x, y int, coverage float64)
+
+// Blend mixes c into the pixel at x,y by coverage, scaled by the alpha of c. Out of bounds
+// writes are ignored.
+func (f *Framebuffer) Blend(x, y int, c color.RGBA, coverage float64) {
+	if x < 0 || x >= f.Width || y < 0 || y >= f.Height {
+		return
+	}
+
+	a := math.Min(math.Max(coverage*float64(c.A)/255, 0), 1)
+	old := f.image.RGBAAt(x, y)
+	mix := func(from, to uint8) uint8 {
+		return uint8(math.Round(float64(from) + (float64(to)-float64(from))*a))
+	}
+
+	f.image.SetRGBA(x, y, color.RGBA{R: mix(old.R, c.R), G: mix(old.G, c.G), B: mix(old.B, c.B), A: old.A})
+}
+
+// DrawLineSmooth draws an anti-aliased line with Xiaolin Wu's algorithm: each step along the
+// major axis shades the two pixels either side of the line by how close it passes to them.
+func (f *Framebuffer) DrawLineSmooth(start, end mymath.Vector2, c color.RGBA) {
+	// A pixel past the edge, since the pixels beside the line are shaded too
+	start, end, visible := ClipLine(start, end, mymath.Vector2{X: -1, Y: -1}, mymath.Vector2{X: float64(f.Width + 1), Y: float64(f.Height + 1)})
+	if !visible {
+		return
+	}
+
+	// Wu's algorithm puts pixel centres on whole coordinates
+	x0, y0 := start.X-0.5, start.Y-0.5
+	x1, y1 := end.X-0.5, end.Y-0.5
+
+	steep := math.Abs(y1-y0) > math.Abs(x1-x0)
+	if steep {
+		x0, y0, x1, y1 = y0, x0, y1, x1
+	}
+	if x0 > x1 {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+
+	plot := func(x, y int, coverage float64) {
+		if steep {
+			f.Blend(y, x, c, coverage)
+		} else {
+			f.Blend(x, y, c, coverage)
+		}
+	}
+	fpart := func(v float64) float64 { return v - math.Floor(v) }
+
+	gradient := 1.0
+	if dx := x1 - x0; dx != 0 {
+		gradient = (y1 - y0) / dx
+	}
+
+	// The end pixels are shaded by how much of them the line reaches into
+	endPoint := func(x, y, gap float64) int {
+		xend := math.Round(x)
+		yend := y + gradient*(xend-x)
+		px, py := int(xend), int(math.Floor(yend))
+		plot(px, py, (1-fpart(yend))*gap)
+		plot(px, py+1, fpart(yend)*gap)
+		return px
+	}
+	first := endPoint(x0, y0, 1-fpart(x0+0.5))
+	last := endPoint(x1, y1, fpart(x1+0.5))
+
+	intery := y0 + gradient*(math.Round(x0)-x0) + gradient
+	for x := first + 1; x < last; x++ {
+		y := int(math.Floor(intery))
+		plot(x, y, 1-fpart(intery))
+		plot(x, y+1, fpart(intery))
+		intery += gradient
+	}
+}
+
+// CoverPolygon finds how much of each pixel a polygon covers, sampling rows scanlines per row
+// of pixels. It takes the same contours and fill rules as ScanPolygon.
+func CoverPolygon(contours [][]mymath.Vector2, rule int, width, height, rows int, cover CoverFunc) {
+	t := newEdgeTable(contours, rule)
+	if t.empty() {
+		return
+	}
+
+	weight := 1 / float64(rows)
+	coverage := make([]float64, width+1) // One spare so spans can end at the right edge
+	left, right := width, 0
+
+	add := func(a, b float64) {
+		a = math.Max(a, 0)
+		b = math.Min(b, float64(width))
+		if a >= b {
+			return
+		}
+
+		ia, ib := int(a), int(b)
+		left, right = min(left, ia), max(right, ib)
+		if ia == ib {
+			coverage[ia] += (b - a) * weight
+			return
+		}
+		coverage[ia] += (float64(ia+1) - a) * weight
+		for x := ia + 1; x < ib; x++ {
+			coverage[x] += weight
+		}
+		coverage[ib] += (b - float64(ib)) * weight
+	}
+
+	for y := max(int(math.Floor(t.top())), 0); y < height; y++ {
+		more := false
+		for i := range rows {
+			more = t.spans(float64(y)+(float64(i)+0.5)*weight, add)
+		}
+
+		for x := left; x <= min(right, width-1); x++ {
+			if coverage[x] > 0 {
+				cover(x, y, math.Min(coverage[x], 1))
+			}
+			coverage[x] = 0
+		}
+		coverage[width] = 0
+		left, right = width, 0
+
+		if !more {
+			return
+		}
+	}
+}
+
+// FillPolygonSmooth fills a polygon like FillPolygon, blending its edges by how much of each
+// pixel they cover
+func (f *Framebuffer) FillPolygonSmooth(contours [][]mymath.Vector2, rule int, c color.RGBA) {
+	CoverPolygon(contours, rule, f.Width, f.Height, coverageRows, func(x, y int, coverage float64) {
+		f.Blend(x, y, c, coverage)
+	})
+}
@@ -0,0 +1,137 @@
+package raster
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+func TestBlend(t *testing.T) {
+	tests := []struct {
+		name     string
+		c        color.RGBA
+		coverage float64
+		want     uint8
+	}{
+		{"full", white, 1, 255},
+		{"half", white, 0.5, 128},
+		{"none", white, 0, 0},
+		{"half alpha", color.RGBA{R: 255, G: 255, B: 255, A: 128}, 1, 128},
+		{"clamped", white, 3, 255},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFramebuffer(4, 4)
+			f.Clear()
+			f.Blend(1, 1, tt.c, tt.coverage)
+			if got := f.At(1, 1); got.R != tt.want || got.A != 255 {
+				t.Errorf("got %v, want R %d", got, tt.want)
+			}
+		})
+	}
+
+	// Out of bounds blends are ignored
+	f := NewFramebuffer(4, 4)
+	f.Clear()
+	f.Blend(-1, 0, white, 1)
+	f.Blend(4, 0, white, 1)
+	if n := lit(f); n != 0 {
+		t.Errorf("%d pixels lit", n)
+	}
+}
+
+// coverage collects what CoverPolygon reports for each pixel
+func coverage(contours [][]mymath.Vector2, rule int) map[mymath.Vector2Int]float64 {
+	covered := map[mymath.Vector2Int]float64{}
+	CoverPolygon(contours, rule, 20, 20, coverageRows, func(x, y int, c float64) {
+		covered[mymath.Vector2Int{X: x, Y: y}] = c
+	})
+	return covered
+}
+
+func TestCoverPolygonPartialPixels(t *testing.T) {
+	tests := []struct {
+		name    string
+		polygon []mymath.Vector2
+		pixel   mymath.Vector2Int
+		want    float64
+	}{
+		{"inside", rectangle(1, 1, 5, 5, false), mymath.Vector2Int{X: 2, Y: 2}, 1},
+		{"half across", rectangle(1, 1, 5.5, 5, false), mymath.Vector2Int{X: 5, Y: 2}, 0.5},
+		{"half down", rectangle(1, 1, 5, 5.5, false), mymath.Vector2Int{X: 2, Y: 5}, 0.5},
+		{"quarter corner", rectangle(1, 1, 5.5, 5.5, false), mymath.Vector2Int{X: 5, Y: 5}, 0.25},
+		{"diagonal", []mymath.Vector2{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 10}}, mymath.Vector2Int{X: 4, Y: 5}, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := coverage([][]mymath.Vector2{tt.polygon}, NonZero)[tt.pixel]
+			if math.Abs(got-tt.want) > 1.0/coverageRows/2 {
+				t.Errorf("pixel %v covered %v, want %v", tt.pixel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFillPolygonSmoothMatchesFillPolygonInside(t *testing.T) {
+	square := [][]mymath.Vector2{rectangle(2, 3, 12, 9, false)}
+	sharp, smooth := NewFramebuffer(20, 20), NewFramebuffer(20, 20)
+	sharp.Clear()
+	smooth.Clear()
+	sharp.FillPolygon(square, NonZero, white)
+	smooth.FillPolygonSmooth(square, NonZero, white)
+
+	for y := range 20 {
+		for x := range 20 {
+			if sharp.At(x, y) != smooth.At(x, y) {
+				t.Errorf("pixel %d,%d: FillPolygon %v, FillPolygonSmooth %v", x, y, sharp.At(x, y), smooth.At(x, y))
+			}
+		}
+	}
+}
+
+func TestDrawLineSmooth(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end mymath.Vector2
+		row        int     // Sampled in the middle of the line
+		want       []uint8 // Red in rows row-1, row and row+1
+	}{
+		{"through pixel centres", mymath.Vector2{X: 2.5, Y: 5.5}, mymath.Vector2{X: 17.5, Y: 5.5}, 5, []uint8{0, 255, 0}},
+		{"between two rows", mymath.Vector2{X: 2.5, Y: 5}, mymath.Vector2{X: 17.5, Y: 5}, 5, []uint8{128, 128, 0}},
+		{"a quarter of the way", mymath.Vector2{X: 2.5, Y: 5.25}, mymath.Vector2{X: 17.5, Y: 5.25}, 5, []uint8{64, 191, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFramebuffer(20, 10)
+			f.Clear()
+			f.DrawLineSmooth(tt.start, tt.end, white)
+			for i, want := range tt.want {
+				if got := f.At(10, tt.row-1+i).R; got != want {
+					t.Errorf("row %d: got %d, want %d", tt.row-1+i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDrawLineSmoothCoverage(t *testing.T) {
+	// A shallow line shades about one pixel's worth in every column it crosses
+	f := NewFramebuffer(40, 20)
+	f.Clear()
+	f.DrawLineSmooth(mymath.Vector2{X: 2.5, Y: 3.2}, mymath.Vector2{X: 37.5, Y: 14.9}, white)
+
+	for x := 4; x < 36; x++ {
+		total := 0
+		for y := range 20 {
+			total += int(f.At(x, y).R)
+		}
+		if total < 253 || total > 257 {
+			t.Errorf("column %d: shaded %d, want about 255", x, total)
+		}
+	}
+}
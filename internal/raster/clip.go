@@ -0,0 +1,189 @@
+package raster
+
+import (
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// Clip planes are homogeneous: a point p is inside the plane when plane.Dot(p) >= 0.
+
+// FrustumPlanes bound the canonical view volume -w <= x,y,z <= w in clip space. Projection
+// matrices that produce a negative w for visible points (such as viewFrustum in
+// cmd/03_starfield_projection) must negate the clip coordinates first, which describes the
+// same point.
+var FrustumPlanes = []mymath.Vector4{
+	{X: 1, Y: 0, Z: 0, W: 1},  // left
+	{X: -1, Y: 0, Z: 0, W: 1}, // right
+	{X: 0, Y: 1, Z: 0, W: 1},  // bottom
+	{X: 0, Y: -1, Z: 0, W: 1}, // top
+	{X: 0, Y: 0, Z: 1, W: 1},  // near
+	{X: 0, Y: 0, Z: -1, W: 1}, // far
+}
+
+// NearPlane keeps points with z <= maxZ, for a camera looking down the negative z axis
+func NearPlane(maxZ float64) mymath.Vector4 {
+	return mymath.Vector4{X: 0, Y: 0, Z: -1, W: maxZ}
+}
+
+type clipVertex struct {
+	position mymath.Vector4
+	weights  Weights
+}
+
+// ClipTriangle clips the triangle abc against every plane using Sutherland-Hodgman and
+// triangulates what remains. Output vertices are returned as barycentric weights of the input
+// triangle so callers can interpolate any per-vertex attribute. Winding order is preserved.
+//
+// The second result is false when the triangle lies entirely inside all planes, in which case
+// the input is returned unchanged as a single triangle. A single plane yields 0-2 triangles.
+// Pieces with no area, such as an edge lying on a plane, are dropped.
+func ClipTriangle(a, b, c mymath.Vector4, planes []mymath.Vector4) ([][3]Weights, bool) {
+	polygon := []clipVertex{
+		{a, Weights{A: 1}},
+		{b, Weights{B: 1}},
+		{c, Weights{C: 1}},
+	}
+
+	clipped := false
+
+	for _, plane := range planes {
+		inside := 0
+		for _, v := range polygon {
+			if plane.Dot(v.position) >= 0 {
+				inside++
+			}
+		}
+
+		if inside == len(polygon) {
+			continue
+		}
+
+		clipped = true
+		if inside == 0 {
+			return nil, true
+		}
+
+		polygon = distinct(clipPolygon(polygon, plane))
+		if len(polygon) < 3 {
+			return nil, true
+		}
+	}
+
+	if !clipped {
+		return [][3]Weights{{polygon[0].weights, polygon[1].weights, polygon[2].weights}}, false
+	}
+
+	triangles := make([][3]Weights, 0, len(polygon)-2)
+	for i := 1; i < len(polygon)-1; i++ {
+		if collinear(polygon[0].position, polygon[i].position, polygon[i+1].position) {
+			continue
+		}
+		triangles = append(triangles, [3]Weights{polygon[0].weights, polygon[i].weights, polygon[i+1].weights})
+	}
+
+	if len(triangles) == 0 {
+		return nil, true
+	}
+	return triangles, true
+}
+
+func clipPolygon(polygon []clipVertex, plane mymath.Vector4) []clipVertex {
+	output := make([]clipVertex, 0, len(polygon)+1)
+	previous := polygon[len(polygon)-1]
+	previousDistance := plane.Dot(previous.position)
+
+	for _, current := range polygon {
+		currentDistance := plane.Dot(current.position)
+
+		// A vertex on the plane is kept as it is, so only a strict crossing adds a new one
+		if (previousDistance > 0 && currentDistance < 0) || (previousDistance < 0 && currentDistance > 0) {
+			t := previousDistance / (previousDistance - currentDistance)
+			output = append(output, clipVertex{
+				position: previous.position.Add(current.position.Subtract(previous.position).Multiply(t)),
+				weights:  previous.weights.lerp(current.weights, t),
+			})
+		}
+
+		if currentDistance >= 0 {
+			output = append(output, current)
+		}
+
+		previous = current
+		previousDistance = currentDistance
+	}
+
+	return output
+}
+
+// distinct removes vertices at the same position as the one before them
+func distinct(polygon []clipVertex) []clipVertex {
+	output := polygon[:0]
+	for i, v := range polygon {
+		if i > 0 && v.position == output[len(output)-1].position {
+			continue
+		}
+		output = append(output, v)
+	}
+	for len(output) > 1 && output[0].position == output[len(output)-1].position {
+		output = output[:len(output)-1]
+	}
+	return output
+}
+
+// collinear reports whether a, b and c lie on one line, so the triangle between them has no
+// area in any projection. The area is compared with the size of the triangle to allow for
+// rounding in the crossing points.
+func collinear(a, b, c mymath.Vector4) bool {
+	u, v := b.Subtract(a), c.Subtract(a)
+	uu, vv, uv := u.Dot(u), v.Dot(v), u.Dot(v)
+	return uu*vv-uv*uv <= mymath.Epsilon*(uu+vv)*(uu+vv)
+}
+
+// ClipLineHomogeneous clips the line from a to b against every plane using Liang-Barsky. The
+// part that remains runs from t0 to t1 along the line, where 0 is a and 1 is b, so callers can
+// interpolate any attribute. It returns false if none of the line is inside.
+func ClipLineHomogeneous(a, b mymath.Vector4, planes []mymath.Vector4) (t0, t1 float64, visible bool) {
+	t0, t1 = 0, 1
+
+	for _, plane := range planes {
+		da := plane.Dot(a)
+		db := plane.Dot(b)
+
+		switch {
+		case da >= 0 && db >= 0:
+			continue
+		case da < 0 && db < 0:
+			return 0, 0, false
+		}
+
+		t := da / (da - db)
+		if da < 0 {
+			t0 = max(t0, t) // Entering
+		} else {
+			t1 = min(t1, t) // Leaving
+		}
+		if t0 > t1 {
+			return 0, 0, false
+		}
+	}
+
+	return t0, t1, true
+}
+
+// ClipLine clips a screen space line to the rectangle from low to high, returning the part
+// inside. It returns false if the line misses the rectangle.
+func ClipLine(start, end, low, high mymath.Vector2) (mymath.Vector2, mymath.Vector2, bool) {
+	edges := []mymath.Vector4{
+		{X: 1, W: -low.X},  // left
+		{X: -1, W: high.X}, // right
+		{Y: 1, W: -low.Y},  // top
+		{Y: -1, W: high.Y}, // bottom
+	}
+
+	t0, t1, visible := ClipLineHomogeneous(mymath.Vector4{X: start.X, Y: start.Y, W: 1}, mymath.Vector4{X: end.X, Y: end.Y, W: 1}, edges)
+	if !visible {
+		return start, end, false
+	}
+
+	direction := end.Subtract(start)
+	return start.Add(direction.Multiply(t0)), start.Add(direction.Multiply(t1)), true
+}
@@ -0,0 +1,120 @@
+package raster
+
+import (
+	"math"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// keepRight keeps points with x >= 0
+var keepRight = []mymath.Vector4{{X: 1}}
+
+func point(x, y float64) mymath.Vector4 {
+	return mymath.Vector4{X: x, Y: y, W: 1}
+}
+
+// at is where weights put a point of the triangle abc
+func at(w Weights, a, b, c mymath.Vector4) mymath.Vector4 {
+	return a.Multiply(w.A).Add(b.Multiply(w.B)).Add(c.Multiply(w.C))
+}
+
+// signedArea of a triangle in the xy plane, positive if anticlockwise
+func signedArea(a, b, c mymath.Vector4) float64 {
+	return ((b.X-a.X)*(c.Y-a.Y) - (c.X-a.X)*(b.Y-a.Y)) / 2
+}
+
+func TestClipTriangle(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b, c   mymath.Vector4
+		clipped   bool
+		triangles int
+		area      float64
+	}{
+		{"all inside", point(1, 0), point(3, 0), point(1, 2), false, 1, 2},
+		{"all outside", point(-1, 0), point(-3, 0), point(-1, 2), true, 0, 0},
+		{"one vertex out", point(-2, 0), point(2, 0), point(2, 4), true, 2, 6},
+		{"two vertices out", point(2, 0), point(-2, 0), point(-2, 4), true, 1, 2},
+		{"one vertex on the plane, the rest inside", point(0, 0), point(2, 0), point(2, 2), false, 1, 2},
+		{"one vertex on the plane, the rest outside", point(0, 0), point(-2, 0), point(-2, 2), true, 0, 0},
+		{"edge on the plane, third vertex inside", point(0, 0), point(2, 1), point(0, 2), false, 1, 2},
+		{"edge on the plane, third vertex outside", point(0, 0), point(0, 2), point(-2, 1), true, 0, 0},
+		{"one vertex on the plane, one each side", point(0, 0), point(2, -2), point(-2, 2), true, 0, 0},
+		{"one vertex on the plane, one in, one out", point(0, 2), point(-2, 0), point(2, 0), true, 1, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			triangles, clipped := ClipTriangle(tt.a, tt.b, tt.c, keepRight)
+			if clipped != tt.clipped {
+				t.Errorf("clipped = %v, want %v", clipped, tt.clipped)
+			}
+			if len(triangles) != tt.triangles {
+				t.Fatalf("%d triangles, want %d", len(triangles), tt.triangles)
+			}
+
+			sign := math.Copysign(1, signedArea(tt.a, tt.b, tt.c))
+			area := 0.0
+			for _, w := range triangles {
+				p := [3]mymath.Vector4{at(w[0], tt.a, tt.b, tt.c), at(w[1], tt.a, tt.b, tt.c), at(w[2], tt.a, tt.b, tt.c)}
+				for _, v := range p {
+					if v.X < -1e-9 {
+						t.Errorf("vertex %v is outside the plane", v)
+					}
+				}
+
+				piece := signedArea(p[0], p[1], p[2]) * sign
+				if piece <= 0 {
+					t.Errorf("piece %v has area %v: empty or wound the wrong way", p, piece)
+				}
+				area += piece
+			}
+			if math.Abs(area-tt.area) > 1e-9 {
+				t.Errorf("area %v, want %v", area, tt.area)
+			}
+		})
+	}
+}
+
+func TestClipTriangleSeveralPlanes(t *testing.T) {
+	// A triangle much larger than the canonical view volume leaves the square -1..1
+	triangles, clipped := ClipTriangle(point(-10, -10), point(10, -10), point(0, 10), FrustumPlanes)
+	if !clipped {
+		t.Fatal("expected the triangle to be clipped")
+	}
+
+	area := 0.0
+	for _, w := range triangles {
+		area += signedArea(at(w[0], point(-10, -10), point(10, -10), point(0, 10)),
+			at(w[1], point(-10, -10), point(10, -10), point(0, 10)),
+			at(w[2], point(-10, -10), point(10, -10), point(0, 10)))
+	}
+	if math.Abs(area-4) > 1e-9 {
+		t.Errorf("area %v, want the whole square of 4", area)
+	}
+}
+
+func TestClipTriangleDropsSlivers(t *testing.T) {
+	// Each of these leaves two crossing points a rounding error apart, which used to give a
+	// piece with no area. In the third that is all there is inside the planes.
+	v := func(x, y, z, w float64) mymath.Vector4 { return mymath.Vector4{X: x, Y: y, Z: z, W: w} }
+	tests := [][3]mymath.Vector4{
+		{v(-1, 1, 3, 1), v(0, 4, -4, 1), v(3, 0, 1, 2)},
+		{v(3, -4, -4, 3), v(-3, 1, -3, 1), v(4, -3, 4, 4)},
+		{v(3, 1, 4, 2), v(4, 3, -3, 1), v(-1, 4, 1, 3)},
+		{v(1, -4, -3, 1), v(0, -3, -3, 1), v(-3, 3, 3, 4)},
+		{v(3, 1, 4, 2), v(-3, 1, 1, 1), v(-1, -2, -3, 1)},
+	}
+
+	for _, tt := range tests {
+		triangles, _ := ClipTriangle(tt[0], tt[1], tt[2], FrustumPlanes)
+		for _, w := range triangles {
+			p := [3]mymath.Vector4{at(w[0], tt[0], tt[1], tt[2]), at(w[1], tt[0], tt[1], tt[2]), at(w[2], tt[0], tt[1], tt[2])}
+			u, v := p[1].Subtract(p[0]), p[2].Subtract(p[0])
+			if u.Dot(u)*v.Dot(v)-u.Dot(v)*u.Dot(v) < 1e-9 {
+				t.Errorf("%v: piece %v has no area", tt, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package raster
+
+import (
+	"image/color"
+	"math"
+)
+
+// DepthBuffer stores one depth value per pixel. Smaller values are closer to the camera,
+// cleared pixels hold +Inf.
+type DepthBuffer struct {
+	Width  int
+	Height int
+	depth  []float64
+}
+
+func NewDepthBuffer(width, height int) *DepthBuffer {
+	d := &DepthBuffer{
+		Width:  width,
+		Height: height,
+		depth:  make([]float64, width*height),
+	}
+	d.Clear()
+	return d
+}
+
+func (d *DepthBuffer) Clear() {
+	for i := range d.depth {
+		d.depth[i] = math.Inf(1)
+	}
+}
+
+func (d *DepthBuffer) At(x, y int) float64 {
+	if x < 0 || x >= d.Width || y < 0 || y >= d.Height {
+		return math.Inf(1)
+	}
+
+	return d.depth[y*d.Width+x]
+}
+
+func (d *DepthBuffer) Set(x, y int, z float64) {
+	if x < 0 || x >= d.Width || y < 0 || y >= d.Height {
+		return
+	}
+
+	d.depth[y*d.Width+x] = z
+}
+
+// Test stores z and returns true if it is closer than the current depth at x,y
+func (d *DepthBuffer) Test(x, y int, z float64) bool {
+	if x < 0 || x >= d.Width || y < 0 || y >= d.Height {
+		return false
+	}
+
+	i := y*d.Width + x
+	if z >= d.depth[i] {
+		return false
+	}
+
+	d.depth[i] = z
+	return true
+}
+
+// Visualize draws the depth buffer into target as grayscale, mapping the closest depth to white
+// and the furthest to dark gray. Empty pixels are black.
+func (d *DepthBuffer) Visualize(target *Framebuffer) {
+	near := math.Inf(1)
+	far := math.Inf(-1)
+
+	for _, z := range d.depth {
+		if math.IsInf(z, 1) {
+			continue
+		}
+		near = math.Min(near, z)
+		far = math.Max(far, z)
+	}
+
+	span := far - near
+	if span <= 0 {
+		span = 1
+	}
+
+	for y := range d.Height {
+		for x := range d.Width {
+			z := d.depth[y*d.Width+x]
+			if math.IsInf(z, 1) {
+				target.Set(x, y, color.RGBA{A: 255})
+				continue
+			}
+
+			gray := uint8(255 - 191*(z-near)/span)
+			target.Set(x, y, color.RGBA{R: gray, G: gray, B: gray, A: 255})
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package raster
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestDepthTest(t *testing.T) {
+	d := NewDepthBuffer(4, 4)
+
+	tests := []struct {
+		name string
+		x, y int
+		z    float64
+		want bool
+	}{
+		{"cleared", 1, 1, 5, true},
+		{"further", 1, 1, 6, false},
+		{"equal", 1, 1, 5, false},
+		{"nearer", 1, 1, 2, true},
+		{"negative", 1, 1, -3, true},
+		{"another pixel", 2, 1, 10, true},
+		{"out of bounds", 4, 1, 0, false},
+		{"negative coordinate", 1, -1, 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := d.Test(tt.x, tt.y, tt.z); got != tt.want {
+			t.Errorf("%s: Test returned %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := d.At(1, 1); got != -3 {
+		t.Errorf("depth %v, want the nearest -3", got)
+	}
+
+	d.Clear()
+	for _, p := range [][2]int{{1, 1}, {2, 1}, {-1, 0}} {
+		if got := d.At(p[0], p[1]); !math.IsInf(got, 1) {
+			t.Errorf("depth at %v is %v after Clear", p, got)
+		}
+	}
+	if !d.Test(1, 1, 100) {
+		t.Error("Test failed on a cleared pixel")
+	}
+}
+
+func TestDepthSet(t *testing.T) {
+	d := NewDepthBuffer(4, 4)
+	d.Set(3, 3, 7)
+	d.Set(4, 0, 1) // Ignored
+
+	if got := d.At(3, 3); got != 7 {
+		t.Errorf("depth %v, want 7", got)
+	}
+
+	// Set overwrites whatever was there, nearer or not
+	d.Set(3, 3, 9)
+	if got := d.At(3, 3); got != 9 {
+		t.Errorf("depth %v, want 9", got)
+	}
+}
+
+func TestVisualize(t *testing.T) {
+	tests := []struct {
+		name   string
+		depths []float64 // Along the top row, the rest left empty
+		want   []uint8
+	}{
+		{"range", []float64{1, 3, 5}, []uint8{255, 159, 64}},
+		{"negative", []float64{-10, 0, 10}, []uint8{255, 159, 64}},
+		{"one depth", []float64{4, 4, 4}, []uint8{255, 255, 255}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDepthBuffer(3, 2)
+			for x, z := range tt.depths {
+				d.Set(x, 0, z)
+			}
+
+			f := NewFramebuffer(3, 2)
+			d.Visualize(f)
+			for x, want := range tt.want {
+				if got := f.At(x, 0); got.R != want || got.G != want || got.B != want || got.A != 255 {
+					t.Errorf("pixel %d is %v, want gray %d", x, got, want)
+				}
+			}
+			for x := range 3 {
+				if got := f.At(x, 1); got != (color.RGBA{A: 255}) {
+					t.Errorf("empty pixel %d is %v, want black", x, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// Framebuffer is a pure-Go render target backed by an image.RGBA, so the
+// examples can draw without a window or a GPU context.
+type Framebuffer struct {
+	Width  int
+	Height int
+	image  *image.RGBA
+}
+
+func NewFramebuffer(width, height int) *Framebuffer {
+	return &Framebuffer{
+		Width:  width,
+		Height: height,
+		image:  image.NewRGBA(image.Rect(0, 0, width, height)),
+	}
+}
+
+// Clear fills the framebuffer with opaque black
+func (f *Framebuffer) Clear() {
+	pix := f.image.Pix
+	for i := 0; i < len(pix); i += 4 {
+		pix[i] = 0
+		pix[i+1] = 0
+		pix[i+2] = 0
+		pix[i+3] = 255
+	}
+}
+
+// Set writes a pixel in screen coordinates (0,0 top left). Out of bounds writes are ignored.
+func (f *Framebuffer) Set(x, y int, c color.RGBA) {
+	if x < 0 || x >= f.Width || y < 0 || y >= f.Height {
+		return
+	}
+
+	f.image.SetRGBA(x, y, c)
+}
+
+func (f *Framebuffer) At(x, y int) color.RGBA {
+	return f.image.RGBAAt(x, y)
+}
+
+func (f *Framebuffer) Image() *image.RGBA {
+	return f.image
+}
+
+// Pixels returns the raw premultiplied RGBA bytes, suitable for ebiten.Image.WritePixels
+func (f *Framebuffer) Pixels() []byte {
+	return f.image.Pix
+}
+
+func (f *Framebuffer) SavePNG(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, f.image); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// DrawLine steps along the major axis from start to end in screen coordinates. The end
+// point itself is not drawn so connected segments do not plot shared corners twice. Lines are
+// clipped to the framebuffer first, so only visible pixels are stepped over.
+func (f *Framebuffer) DrawLine(start, end mymath.Vector2, c color.RGBA) {
+	start, end, visible := ClipLine(start, end, mymath.Vector2{}, mymath.Vector2{X: float64(f.Width), Y: float64(f.Height)})
+	if !visible {
+		return
+	}
+
+	dx := end.X - start.X
+	dy := end.Y - start.Y
+	absdx := math.Abs(dx)
+	absdy := math.Abs(dy)
+
+	stepx := 1
+	if start.X > end.X {
+		stepx = -1
+	}
+
+	stepy := 1
+	if start.Y > end.Y {
+		stepy = -1
+	}
+
+	errY := 0.0
+	errX := 0.0
+	x := int(start.X)
+	y := int(start.Y)
+
+	// Line has more rise than run
+	if absdy > absdx {
+		slope := absdx / absdy
+
+		for ystep := 0; ystep < int(absdy); ystep++ {
+			f.Set(x, y, c)
+			y += stepy
+			errX += slope
+			if errX > 0.5 {
+				errX -= 1
+				x += stepx
+			}
+		}
+	} else {
+		slope := absdy / absdx
+		for xstep := 0; xstep < int(absdx); xstep++ {
+			f.Set(x, y, c)
+			x += stepx
+			errY += slope
+			if errY > 0.5 {
+				errY -= 1
+				y += stepy
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package raster
+
+import (
+	"image/color"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+var white = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+// lit counts pixels that are not black
+func lit(f *Framebuffer) int {
+	n := 0
+	for y := range f.Height {
+		for x := range f.Width {
+			if f.At(x, y) != (color.RGBA{A: 255}) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestSetBounds(t *testing.T) {
+	tests := []struct {
+		x, y int
+		lit  int
+	}{
+		{0, 0, 1},
+		{19, 9, 1},
+		{-1, 0, 0},
+		{0, -1, 0},
+		{20, 0, 0},
+		{0, 10, 0},
+		{1000, -1000, 0},
+	}
+
+	for _, tt := range tests {
+		f := NewFramebuffer(20, 10)
+		f.Clear()
+		f.Set(tt.x, tt.y, white)
+		if got := lit(f); got != tt.lit {
+			t.Errorf("Set(%d, %d) lit %d pixels, want %d", tt.x, tt.y, got, tt.lit)
+		}
+	}
+}
+
+func TestDrawLine(t *testing.T) {
+	v := func(x, y float64) mymath.Vector2 { return mymath.Vector2{X: x, Y: y} }
+
+	tests := []struct {
+		name       string
+		start, end mymath.Vector2
+		lit        int
+	}{
+		{"inside, end excluded", v(2, 2), v(7, 2), 5},
+		{"vertical", v(3, 1), v(3, 8), 7},
+		{"crosses the width", v(-50, 5), v(50, 5), 20},
+		{"crosses the height", v(4, -50), v(4, 50), 10},
+		{"diagonal through a corner", v(-5, -5), v(5, 5), 5},
+		{"entirely left", v(-30, 0), v(-10, 9), 0},
+		{"entirely below", v(0, 12), v(19, 30), 0},
+		{"far off screen", v(-1e12, 5), v(1e12, 5), 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFramebuffer(20, 10)
+			f.Clear()
+			f.DrawLine(tt.start, tt.end, white)
+			if got := lit(f); got != tt.lit {
+				t.Errorf("lit %d pixels, want %d", got, tt.lit)
+			}
+		})
+	}
+}
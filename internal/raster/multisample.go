@@ -0,0 +1,133 @@
+package raster
+
+import (
+	"image/color"
+	"math"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// SamplePattern returns the positions, within a pixel, of the standard Direct3D patterns for 1,
+// 2, 4 and 8 samples. The samples are spread so that no two share a row or a column, which
+// smooths nearly horizontal and nearly vertical edges as much as it can.
+func SamplePattern(samples int) []mymath.Vector2 {
+	var offsets [][2]float64 // In sixteenths of a pixel from the centre
+	switch {
+	case samples >= 8:
+		offsets = [][2]float64{{1, -3}, {-1, 3}, {5, 1}, {-3, -5}, {-5, 5}, {-7, -1}, {3, 7}, {7, -7}}
+	case samples >= 4:
+		offsets = [][2]float64{{-2, -6}, {6, -2}, {-6, 2}, {2, 6}}
+	case samples >= 2:
+		offsets = [][2]float64{{4, 4}, {-4, -4}}
+	default:
+		offsets = [][2]float64{{0, 0}}
+	}
+
+	pattern := make([]mymath.Vector2, len(offsets))
+	for i, o := range offsets {
+		pattern[i] = mymath.Vector2{X: 0.5 + o[0]/16, Y: 0.5 + o[1]/16}
+	}
+	return pattern
+}
+
+// Multisample keeps a color and a depth for each of several sample points in every pixel.
+// Triangles are shaded once per pixel but tested and stored per sample, and averaging the
+// samples gives edges that are partly covered pixels.
+type Multisample struct {
+	Width   int
+	Height  int
+	Pattern []mymath.Vector2
+	color   []color.RGBA
+	depth   []float64
+}
+
+func NewMultisample(width, height int, pattern []mymath.Vector2) *Multisample {
+	m := &Multisample{
+		Width:   width,
+		Height:  height,
+		Pattern: pattern,
+		color:   make([]color.RGBA, width*height*len(pattern)),
+		depth:   make([]float64, width*height*len(pattern)),
+	}
+	m.Clear()
+	return m
+}
+
+// Clear sets every sample to opaque black and +Inf depth, to match a cleared Framebuffer
+func (m *Multisample) Clear() {
+	for i := range m.color {
+		m.color[i] = color.RGBA{A: 255}
+		m.depth[i] = math.Inf(1)
+	}
+}
+
+// Write stores c in one sample of the pixel at x,y. With test set it is only stored if z is
+// closer than the sample's depth, and Write reports whether it was.
+func (m *Multisample) Write(x, y, sample int, z float64, c color.RGBA, test bool) bool {
+	if x < 0 || x >= m.Width || y < 0 || y >= m.Height {
+		return false
+	}
+
+	i := (y*m.Width+x)*len(m.Pattern) + sample
+	if test && z >= m.depth[i] {
+		return false
+	}
+
+	m.color[i] = c
+	m.depth[i] = z
+	return true
+}
+
+// Resolve averages the samples of the pixel at x,y
+func (m *Multisample) Resolve(x, y int) color.RGBA {
+	n := len(m.Pattern)
+	i := (y*m.Width + x) * n
+
+	var r, g, b int
+	for _, c := range m.color[i : i+n] {
+		r += int(c.R)
+		g += int(c.G)
+		b += int(c.B)
+	}
+	return color.RGBA{R: uint8((r + n/2) / n), G: uint8((g + n/2) / n), B: uint8((b + n/2) / n), A: 255}
+}
+
+// Sample is a sample point inside a triangle, as an index into the pattern and its weights
+type Sample struct {
+	Index   int
+	Weights Weights
+}
+
+// SampleFunc is called once for every pixel with at least one sample inside a triangle. Weights
+// are taken at the pixel centre, which may be just outside the triangle. The samples slice is
+// reused between calls.
+type SampleFunc func(x, y int, weights Weights, samples []Sample)
+
+// FillTriangleSamples walks every pixel within the framebuffer that has a sample point of
+// pattern inside the screen-space triangle abc. Samples are tested like FillTriangle tests
+// pixel centres, so with a one sample pattern both cover the same pixels.
+func (r *Rasterizer) FillTriangleSamples(a, b, c mymath.Vector2, pattern []mymath.Vector2, pixel SampleFunc) {
+	t, ok := newTriangle(a, b, c)
+	if !ok {
+		return
+	}
+
+	minx, maxx, miny, maxy := r.bounds(a, b, c)
+	samples := make([]Sample, 0, len(pattern))
+	for y := miny; y <= maxy; y++ {
+		for x := minx; x <= maxx; x++ {
+			samples = samples[:0]
+			for i, s := range pattern {
+				p := mymath.Vector2{X: float64(x) + s.X, Y: float64(y) + s.Y}
+				if t.contains(p) {
+					samples = append(samples, Sample{i, t.weights(p)})
+				}
+			}
+			if len(samples) == 0 {
+				continue
+			}
+
+			pixel(x, y, t.weights(mymath.Vector2{X: float64(x) + 0.5, Y: float64(y) + 0.5}), samples)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package raster
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+func TestSamplePattern(t *testing.T) {
+	tests := []struct {
+		samples int
+		want    int
+	}{
+		{0, 1},
+		{1, 1},
+		{2, 2},
+		{3, 2},
+		{4, 4},
+		{8, 8},
+		{16, 8},
+	}
+
+	for _, tt := range tests {
+		pattern := SamplePattern(tt.samples)
+		if len(pattern) != tt.want {
+			t.Errorf("SamplePattern(%d) has %d samples, want %d", tt.samples, len(pattern), tt.want)
+		}
+
+		rows, columns := map[float64]bool{}, map[float64]bool{}
+		for _, s := range pattern {
+			if s.X <= 0 || s.X >= 1 || s.Y <= 0 || s.Y >= 1 {
+				t.Errorf("SamplePattern(%d): %v is outside the pixel", tt.samples, s)
+			}
+			if rows[s.Y] || columns[s.X] {
+				t.Errorf("SamplePattern(%d): %v shares a row or column", tt.samples, s)
+			}
+			rows[s.Y], columns[s.X] = true, true
+		}
+	}
+}
+
+// samples counts the samples of each pixel that FillTriangleSamples finds inside the triangles
+func samples(width, height int, pattern []mymath.Vector2, triangles [][3]mymath.Vector2) map[mymath.Vector2Int]int {
+	r := NewRasterizer(NewFramebuffer(width, height))
+	covered := map[mymath.Vector2Int]int{}
+	for _, t := range triangles {
+		r.FillTriangleSamples(t[0], t[1], t[2], pattern, func(x, y int, w Weights, s []Sample) {
+			covered[mymath.Vector2Int{X: x, Y: y}] += len(s)
+		})
+	}
+	return covered
+}
+
+func TestFillTriangleSamplesMatchesFillTriangle(t *testing.T) {
+	v := func(x, y float64) mymath.Vector2 { return mymath.Vector2{X: x, Y: y} }
+	tests := []struct {
+		name      string
+		triangles [][3]mymath.Vector2
+	}{
+		{"whole pixels", [][3]mymath.Vector2{{v(1, 1), v(15, 1), v(1, 12)}}},
+		{"pixel centres on the edges", [][3]mymath.Vector2{{v(1.5, 1.5), v(15.5, 1.5), v(1.5, 12.5)}}},
+		{"fractional", [][3]mymath.Vector2{{v(1.3, 0.7), v(17.9, 5.2), v(6.1, 18.4)}}},
+		{"shared edge", [][3]mymath.Vector2{{v(0.2, 0.3), v(19.7, 0.9), v(3.4, 19.2)}, {v(19.7, 0.9), v(18.1, 18.6), v(3.4, 19.2)}}},
+		{"anticlockwise", [][3]mymath.Vector2{{v(6.1, 18.4), v(17.9, 5.2), v(1.3, 0.7)}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := fill(20, 20, tt.triangles)
+			got := samples(20, 20, SamplePattern(1), tt.triangles)
+			if len(got) != len(want) {
+				t.Errorf("covered %d pixels, FillTriangle %d", len(got), len(want))
+			}
+			for p, n := range want {
+				if got[p] != n {
+					t.Errorf("pixel %v: covered %d times, FillTriangle %d", p, got[p], n)
+				}
+			}
+		})
+	}
+}
+
+func TestFillTriangleMatchesScanPolygon(t *testing.T) {
+	v := func(x, y float64) mymath.Vector2 { return mymath.Vector2{X: x, Y: y} }
+	// No pixel centre is exactly on an edge, where rounding would decide
+	triangle := [3]mymath.Vector2{v(1.3, 0.7), v(17.9, 5.2), v(6.2, 18.4)}
+
+	want := scan(20, 20, NonZero, [][]mymath.Vector2{triangle[:]})
+	got := fill(20, 20, [][3]mymath.Vector2{triangle})
+	if len(got) != len(want) {
+		t.Errorf("filled %d pixels, ScanPolygon %d", len(got), len(want))
+	}
+	for p := range want {
+		if got[p] != 1 {
+			t.Errorf("pixel %v filled %d times", p, got[p])
+		}
+	}
+}
+
+func TestMultisampleRectangle(t *testing.T) {
+	// An axis-aligned rectangle on whole pixels covers every sample of the pixels FillTriangle
+	// fills, and no others
+	v := func(x, y float64) mymath.Vector2 { return mymath.Vector2{X: x, Y: y} }
+	rect := [][3]mymath.Vector2{{v(3, 2), v(14, 2), v(14, 9)}, {v(3, 2), v(14, 9), v(3, 9)}}
+
+	for _, n := range []int{2, 4, 8} {
+		want := fill(20, 20, rect)
+		got := samples(20, 20, SamplePattern(n), rect)
+		if len(want) != 11*7 || len(got) != len(want) {
+			t.Errorf("%d samples: covered %d pixels, FillTriangle %d", n, len(got), len(want))
+		}
+		for p := range want {
+			if got[p] != n {
+				t.Errorf("%d samples: pixel %v has %d", n, p, got[p])
+			}
+		}
+	}
+}
+
+func TestMultisampleHalfCoveredPixel(t *testing.T) {
+	// The right edge runs down the middle of column 10, so half of its samples are inside
+	v := func(x, y float64) mymath.Vector2 { return mymath.Vector2{X: x, Y: y} }
+	rect := [][3]mymath.Vector2{{v(2, 2), v(10.5, 2), v(10.5, 8)}, {v(2, 2), v(10.5, 8), v(2, 8)}}
+	pattern := SamplePattern(4)
+
+	m := NewMultisample(20, 20, pattern)
+	r := NewRasterizer(NewFramebuffer(20, 20))
+	for _, t := range rect {
+		r.FillTriangleSamples(t[0], t[1], t[2], pattern, func(x, y int, w Weights, s []Sample) {
+			for _, sample := range s {
+				m.Write(x, y, sample.Index, 0, white, true)
+			}
+		})
+	}
+
+	tests := []struct {
+		x    int
+		want uint8
+	}{
+		{1, 0},
+		{2, 255},
+		{9, 255},
+		{10, 128},
+		{11, 0},
+	}
+
+	for _, tt := range tests {
+		if got := m.Resolve(tt.x, 5); got.R != tt.want || got.A != 255 {
+			t.Errorf("column %d resolved to %v, want R %d", tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestMultisampleWrite(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	m := NewMultisample(4, 4, SamplePattern(2))
+
+	tests := []struct {
+		name   string
+		x, y   int
+		sample int
+		z      float64
+		c      color.RGBA
+		test   bool
+		want   bool
+	}{
+		{"empty sample", 1, 1, 0, 0.5, white, true, true},
+		{"further", 1, 1, 0, 0.7, red, true, false},
+		{"equal depth", 1, 1, 0, 0.5, red, true, false},
+		{"nearer", 1, 1, 0, 0.3, red, true, true},
+		{"untested", 1, 1, 0, 0.9, white, false, true},
+		{"other sample", 1, 1, 1, 0.9, red, true, true},
+		{"out of bounds", 4, 1, 0, 0, white, false, false},
+		{"negative", -1, 1, 0, 0, white, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := m.Write(tt.x, tt.y, tt.sample, tt.z, tt.c, tt.test); got != tt.want {
+			t.Errorf("%s: Write returned %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if got, want := m.Resolve(1, 1), (color.RGBA{R: 255, G: 128, B: 128, A: 255}); got != want {
+		t.Errorf("resolved to %v, want %v", got, want)
+	}
+
+	m.Clear()
+	if got := m.Resolve(1, 1); got != (color.RGBA{A: 255}) {
+		t.Errorf("cleared pixel resolved to %v", got)
+	}
+	if !m.Write(1, 1, 0, math.MaxFloat64, white, true) {
+		t.Error("Write after Clear failed the depth test")
+	}
+}
@@ -0,0 +1,145 @@
+package raster
+
+import (
+	"image/color"
+	"math"
+	"sort"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// Fill rules decide which parts of an overlapping or nested polygon are inside
+const (
+	EvenOdd = iota // Inside where a ray out of the polygon crosses an odd number of edges
+	NonZero        // Inside where the edges wind around the point at all
+)
+
+// SpanFunc is called for each run of pixels on row y from x0 up to but not including x1
+type SpanFunc func(y, x0, x1 int)
+
+type polygonEdge struct {
+	top, bottom float64 // top < bottom in screen coordinates
+	x           float64 // x at top
+	slope       float64 // Change in x for each unit of y
+	winding     int     // +1 if the contour runs down the screen here, -1 if up
+}
+
+type crossing struct {
+	x       float64
+	winding int
+}
+
+// edgeTable walks down a polygon one scanline at a time, keeping the edges that cross it
+type edgeTable struct {
+	rule      int
+	edges     []polygonEdge // Sorted by top
+	next      int
+	active    []polygonEdge
+	crossings []crossing
+}
+
+func newEdgeTable(contours [][]mymath.Vector2, rule int) *edgeTable {
+	t := &edgeTable{rule: rule}
+	for _, contour := range contours {
+		for i := range contour {
+			a, b := contour[i], contour[(i+1)%len(contour)]
+			if a.Y == b.Y {
+				continue
+			}
+
+			winding := 1
+			if a.Y > b.Y {
+				a, b = b, a
+				winding = -1
+			}
+			slope := (b.X - a.X) / (b.Y - a.Y)
+			t.edges = append(t.edges, polygonEdge{top: a.Y, bottom: b.Y, x: a.X, slope: slope, winding: winding})
+		}
+	}
+
+	sort.Slice(t.edges, func(i, j int) bool { return t.edges[i].top < t.edges[j].top })
+	return t
+}
+
+func (t *edgeTable) empty() bool {
+	return len(t.edges) == 0
+}
+
+func (t *edgeTable) top() float64 {
+	return t.edges[0].top
+}
+
+// spans calls span for each stretch of scanline y inside the polygon. Scanlines must be visited
+// from the top down. It returns false once every edge has been passed.
+func (t *edgeTable) spans(y float64, span func(x0, x1 float64)) bool {
+	// Edges start being active at the first scanline they reach, and stop at the first one they
+	// don't, so a vertex shared by two edges is counted once
+	for t.next < len(t.edges) && t.edges[t.next].top <= y {
+		t.active = append(t.active, t.edges[t.next])
+		t.next++
+	}
+	kept := t.active[:0]
+	for _, e := range t.active {
+		if e.bottom > y {
+			kept = append(kept, e)
+		}
+	}
+	t.active = kept
+
+	if len(t.active) == 0 {
+		return t.next < len(t.edges)
+	}
+
+	t.crossings = t.crossings[:0]
+	for _, e := range t.active {
+		t.crossings = append(t.crossings, crossing{e.x + (y-e.top)*e.slope, e.winding})
+	}
+	sort.Slice(t.crossings, func(i, j int) bool { return t.crossings[i].x < t.crossings[j].x })
+
+	winding := 0
+	for i, c := range t.crossings[:len(t.crossings)-1] {
+		if t.rule == EvenOdd {
+			winding ^= 1
+		} else {
+			winding += c.winding
+		}
+		if winding != 0 {
+			span(c.x, t.crossings[i+1].x)
+		}
+	}
+	return true
+}
+
+// ScanPolygon finds the pixels inside a polygon made of one or more closed contours, such as
+// an outline with holes. A pixel is inside if its centre is. Contours may be concave and may
+// cross themselves or each other. Only pixels within width and height are reported.
+func ScanPolygon(contours [][]mymath.Vector2, rule int, width, height int, span SpanFunc) {
+	t := newEdgeTable(contours, rule)
+	if t.empty() {
+		return
+	}
+
+	// Pixel centres are at half coordinates, so row y samples y + 0.5
+	for y := max(int(math.Ceil(t.top()-0.5)), 0); y < height; y++ {
+		more := t.spans(float64(y)+0.5, func(a, b float64) {
+			// Pixels whose centres lie between the two crossings
+			x0 := max(int(math.Ceil(a-0.5)), 0)
+			x1 := min(int(math.Ceil(b-0.5)), width)
+			if x0 < x1 {
+				span(y, x0, x1)
+			}
+		})
+		if !more {
+			return
+		}
+	}
+}
+
+// FillPolygon fills the inside of a polygon, in screen coordinates, with a solid color
+func (f *Framebuffer) FillPolygon(contours [][]mymath.Vector2, rule int, c color.RGBA) {
+	ScanPolygon(contours, rule, f.Width, f.Height, func(y, x0, x1 int) {
+		for x := x0; x < x1; x++ {
+			f.image.SetRGBA(x, y, c)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package raster
+
+import (
+	"math"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// scan fills every polygon and counts how often each pixel was filled
+func scan(width, height int, rule int, polygons ...[][]mymath.Vector2) map[mymath.Vector2Int]int {
+	filled := map[mymath.Vector2Int]int{}
+	for _, contours := range polygons {
+		ScanPolygon(contours, rule, width, height, func(y, x0, x1 int) {
+			for x := x0; x < x1; x++ {
+				filled[mymath.Vector2Int{X: x, Y: y}]++
+			}
+		})
+	}
+	return filled
+}
+
+// rectangle runs clockwise on screen, or anticlockwise if reversed
+func rectangle(x0, y0, x1, y1 float64, reversed bool) []mymath.Vector2 {
+	r := []mymath.Vector2{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}
+	if reversed {
+		r[1], r[3] = r[3], r[1]
+	}
+	return r
+}
+
+// star joins every second point of a regular pentagon, so its edges cross
+func star(centre mymath.Vector2, radius float64) []mymath.Vector2 {
+	var points []mymath.Vector2
+	for i := range 5 {
+		sin, cos := math.Sincos(-math.Pi/2 + 4*math.Pi*float64(i)/5)
+		points = append(points, centre.Add(mymath.Vector2{X: radius * cos, Y: radius * sin}))
+	}
+	return points
+}
+
+func TestScanPolygon(t *testing.T) {
+	outer := rectangle(0, 0, 20, 20, false)
+
+	tests := []struct {
+		name     string
+		contours [][]mymath.Vector2
+		evenOdd  int
+		nonZero  int
+	}{
+		{"square", [][]mymath.Vector2{rectangle(2, 2, 12, 12, false)}, 100, 100},
+		{"reversed square", [][]mymath.Vector2{rectangle(2, 2, 12, 12, true)}, 100, 100},
+		{"pixel centres decide", [][]mymath.Vector2{rectangle(1.5, 1.5, 4.5, 4.4, false)}, 9, 9},
+		{"centres on the edge", [][]mymath.Vector2{rectangle(1.4, 1.4, 4.5, 4.5, false)}, 9, 9},
+		{"hole wound the other way", [][]mymath.Vector2{outer, rectangle(5, 5, 15, 15, true)}, 300, 300},
+		// A hole wound the same way adds to the winding, so NonZero fills it
+		{"hole wound the same way", [][]mymath.Vector2{outer, rectangle(5, 5, 15, 15, false)}, 300, 400},
+		{"overlapping squares", [][]mymath.Vector2{rectangle(0, 0, 10, 10, false), rectangle(5, 5, 15, 15, false)}, 150, 175},
+		{"clipped", [][]mymath.Vector2{rectangle(-5, -5, 10, 30, false)}, 200, 200},
+		{"off screen", [][]mymath.Vector2{rectangle(-20, 5, -10, 10, false)}, 0, 0},
+		{"flat", [][]mymath.Vector2{{{X: 2, Y: 5}, {X: 10, Y: 5}}}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(scan(20, 20, EvenOdd, tt.contours)); got != tt.evenOdd {
+				t.Errorf("EvenOdd filled %d pixels, want %d", got, tt.evenOdd)
+			}
+			if got := len(scan(20, 20, NonZero, tt.contours)); got != tt.nonZero {
+				t.Errorf("NonZero filled %d pixels, want %d", got, tt.nonZero)
+			}
+		})
+	}
+}
+
+func TestScanPolygonPentagram(t *testing.T) {
+	centre, radius := mymath.Vector2{X: 50, Y: 50}, 40.0
+	points := [][]mymath.Vector2{star(centre, radius)}
+	evenOdd, nonZero := scan(100, 100, EvenOdd, points), scan(100, 100, NonZero, points)
+
+	middle := mymath.Vector2Int{X: 50, Y: 50}
+	if evenOdd[middle] != 0 {
+		t.Error("EvenOdd filled the pentagon in the middle")
+	}
+	if nonZero[middle] != 1 {
+		t.Error("NonZero left the pentagon in the middle empty")
+	}
+
+	// The tips are inside once either way
+	for _, tip := range star(centre, radius*0.9) {
+		p := mymath.Vector2Int{X: int(tip.X), Y: int(tip.Y)}
+		if evenOdd[p] != 1 || nonZero[p] != 1 {
+			t.Errorf("tip at %v: EvenOdd %d, NonZero %d", p, evenOdd[p], nonZero[p])
+		}
+	}
+
+	// The difference is the middle pentagon, to within about half its perimeter
+	inner := radius * math.Cos(2*math.Pi/5) / math.Cos(math.Pi/5)
+	area := 2.5 * inner * inner * math.Sin(2*math.Pi/5)
+	perimeter := 10 * inner * math.Sin(math.Pi/5)
+	if diff := float64(len(nonZero) - len(evenOdd)); math.Abs(diff-area) > perimeter/2 {
+		t.Errorf("NonZero filled %v more pixels than EvenOdd, want about %v", diff, area)
+	}
+}
+
+func TestScanPolygonSharedEdges(t *testing.T) {
+	// Triangles fanned around an off-grid point, each filled on its own
+	centre := mymath.Vector2{X: 20.3, Y: 19.7}
+	var fan [][][]mymath.Vector2
+	var outline []mymath.Vector2
+	for i := range 7 {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / 7)
+		outline = append(outline, centre.Add(mymath.Vector2{X: 15.5 * cos, Y: 15.5 * sin}))
+	}
+	for i := range outline {
+		fan = append(fan, [][]mymath.Vector2{{centre, outline[i], outline[(i+1)%len(outline)]}})
+	}
+
+	tests := []struct {
+		name     string
+		polygons [][][]mymath.Vector2
+		want     int
+	}{
+		{"side by side", [][][]mymath.Vector2{{rectangle(0, 0, 10, 10, false)}, {rectangle(10, 0, 20, 10, true)}}, 200},
+		{"one above the other", [][][]mymath.Vector2{{rectangle(0.5, 0.5, 10.5, 10.5, false)}, {rectangle(0.5, 10.5, 10.5, 20.5, false)}}, 200},
+		{"fan", fan, len(scan(40, 40, NonZero, [][]mymath.Vector2{outline}))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filled := scan(40, 40, NonZero, tt.polygons...)
+			for p, n := range filled {
+				if n > 1 {
+					t.Errorf("pixel %v filled %d times", p, n)
+				}
+			}
+			if len(filled) != tt.want {
+				t.Errorf("filled %d pixels, want %d", len(filled), tt.want)
+			}
+		})
+	}
+}
+
+func TestCoverPolygon(t *testing.T) {
+	circle := make([]mymath.Vector2, 256)
+	for i := range circle {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(len(circle)))
+		circle[i] = mymath.Vector2{X: 20 + 12*cos, Y: 20 + 12*sin}
+	}
+	polygonArea := 0.5 * float64(len(circle)) * 144 * math.Sin(2*math.Pi/float64(len(circle)))
+
+	tests := []struct {
+		name      string
+		contours  [][]mymath.Vector2
+		rule      int
+		want      float64
+		tolerance float64
+	}{
+		// Edges on whole eighths of a row are sampled exactly
+		{"rectangle", [][]mymath.Vector2{rectangle(1.25, 2.25, 5.75, 6.125, false)}, EvenOdd, 4.5 * 3.875, 1e-9},
+		{"rectangle with a hole", [][]mymath.Vector2{rectangle(0, 0, 20, 20, false), rectangle(5.5, 5.5, 15.5, 15.5, true)}, NonZero, 300, 1e-9},
+		{"same-wound hole", [][]mymath.Vector2{rectangle(0, 0, 20, 20, false), rectangle(5.5, 5.5, 15.5, 15.5, false)}, EvenOdd, 300, 1e-9},
+		{"same-wound hole filled", [][]mymath.Vector2{rectangle(0, 0, 20, 20, false), rectangle(5.5, 5.5, 15.5, 15.5, false)}, NonZero, 400, 1e-9},
+		{"clipped", [][]mymath.Vector2{rectangle(-5, 35, 10.5, 50, false)}, NonZero, 10.5 * 5, 1e-9},
+		{"circle", [][]mymath.Vector2{circle}, NonZero, polygonArea, 0.01 * polygonArea},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum := 0.0
+			seen := map[mymath.Vector2Int]bool{}
+			CoverPolygon(tt.contours, tt.rule, 40, 40, 8, func(x, y int, coverage float64) {
+				p := mymath.Vector2Int{X: x, Y: y}
+				if seen[p] {
+					t.Errorf("pixel %v covered twice", p)
+				}
+				seen[p] = true
+				if coverage <= 0 || coverage > 1 {
+					t.Errorf("pixel %v has coverage %v", p, coverage)
+				}
+				sum += coverage
+			})
+
+			if math.Abs(sum-tt.want) > tt.tolerance {
+				t.Errorf("coverage adds up to %v, want %v", sum, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package raster
+
+import (
+	"image/color"
+	"math"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// Weights are the barycentric weights of a point relative to the triangle vertices a, b and c.
+// Inside the triangle all weights are >= 0 and they always sum to 1.
+type Weights struct {
+	A float64
+	B float64
+	C float64
+}
+
+func (w Weights) Float(a, b, c float64) float64 {
+	return a*w.A + b*w.B + c*w.C
+}
+
+func (w Weights) Vector2(a, b, c mymath.Vector2) mymath.Vector2 {
+	return mymath.Vector2{
+		X: a.X*w.A + b.X*w.B + c.X*w.C,
+		Y: a.Y*w.A + b.Y*w.B + c.Y*w.C,
+	}
+}
+
+func (w Weights) Vector3(a, b, c mymath.Vector3) mymath.Vector3 {
+	return a.Multiply(w.A).Add(b.Multiply(w.B)).Add(c.Multiply(w.C))
+}
+
+func (w Weights) Color3(a, b, c mymath.Color3) mymath.Color3 {
+	return a.Multiply(w.A).Add(b.Multiply(w.B)).Add(c.Multiply(w.C))
+}
+
+// PerspectiveCorrect turns screen space weights into weights for attributes that vary
+// linearly in 3D, given the view depth (the projection's w) of each vertex
+func (w Weights) PerspectiveCorrect(depthA, depthB, depthC float64) Weights {
+	a := w.A / depthA
+	b := w.B / depthB
+	c := w.C / depthC
+	sum := a + b + c
+
+	return Weights{A: a / sum, B: b / sum, C: c / sum}
+}
+
+func (w Weights) lerp(to Weights, t float64) Weights {
+	return Weights{
+		A: w.A + (to.A-w.A)*t,
+		B: w.B + (to.B-w.B)*t,
+		C: w.C + (to.C-w.C)*t,
+	}
+}
+
+// PixelFunc is called once for every pixel covered by a triangle, in screen coordinates
+type PixelFunc func(x, y int, weights Weights)
+
+type Rasterizer struct {
+	target *Framebuffer
+}
+
+func NewRasterizer(target *Framebuffer) *Rasterizer {
+	return &Rasterizer{target: target}
+}
+
+func (r *Rasterizer) Target() *Framebuffer {
+	return r.target
+}
+
+// FillTriangle walks every pixel inside the screen-space triangle abc that lies within the
+// framebuffer and hands it to the callback along with its barycentric weights. A pixel is
+// inside if its centre is, like ScanPolygon, and a centre exactly on an edge shared by two
+// triangles is only drawn by one of them.
+func (r *Rasterizer) FillTriangle(a, b, c mymath.Vector2, pixel PixelFunc) {
+	t, ok := newTriangle(a, b, c)
+	if !ok {
+		return
+	}
+
+	minx, maxx, miny, maxy := r.bounds(a, b, c)
+	for y := miny; y <= maxy; y++ {
+		for x := minx; x <= maxx; x++ {
+			p := mymath.Vector2{X: float64(x) + 0.5, Y: float64(y) + 0.5}
+			if t.contains(p) {
+				pixel(x, y, t.weights(p))
+			}
+		}
+	}
+}
+
+// bounds are the pixels within the framebuffer that a triangle could cover
+func (r *Rasterizer) bounds(a, b, c mymath.Vector2) (minx, maxx, miny, maxy int) {
+	minx = max(0, int(math.Floor(min(a.X, b.X, c.X))))
+	maxx = min(r.target.Width-1, int(math.Floor(max(a.X, b.X, c.X))))
+	miny = max(0, int(math.Floor(min(a.Y, b.Y, c.Y))))
+	maxy = min(r.target.Height-1, int(math.Floor(max(a.Y, b.Y, c.Y))))
+	return minx, maxx, miny, maxy
+}
+
+// triangle tests points against a screen-space triangle
+type triangle struct {
+	a, b, c mymath.Vector2
+	area    float64 // Twice the signed area
+}
+
+// newTriangle returns false for triangles with no area, which contain no points
+func newTriangle(a, b, c mymath.Vector2) (triangle, bool) {
+	area := b.Subtract(a).Cross(c.Subtract(a))
+	return triangle{a, b, c, area}, area != 0
+}
+
+func (t triangle) contains(p mymath.Vector2) bool {
+	return inside(t.a, t.b, p, t.area) && inside(t.b, t.c, p, t.area) && inside(t.c, t.a, p, t.area)
+}
+
+// weights at p, from the areas of the triangles p makes with each edge
+func (t triangle) weights(p mymath.Vector2) Weights {
+	wb := p.Subtract(t.a).Cross(t.c.Subtract(t.a)) / t.area
+	wc := t.b.Subtract(t.a).Cross(p.Subtract(t.a)) / t.area
+	return Weights{A: 1 - wb - wc, B: wb, C: wc}
+}
+
+// inside reports whether p is on the inner side of the edge from a to b, for a triangle of the
+// given signed area. Two triangles sharing an edge see it running opposite ways, so points on
+// the edge itself go to the triangle that sees it heading down the screen, or left if it is
+// level.
+func inside(a, b, p mymath.Vector2, area float64) bool {
+	edge := b.Subtract(a)
+	side := edge.Cross(p.Subtract(a))
+	if area < 0 {
+		side, edge = -side, edge.Multiply(-1)
+	}
+
+	if side != 0 {
+		return side > 0
+	}
+	return edge.Y > 0 || (edge.Y == 0 && edge.X < 0)
+}
+
+// BarycentricCoordinates reports whether p lies inside the triangle abc and its weights
+// relative to each vertex. Degenerate (zero area) triangles contain no points.
+func BarycentricCoordinates(a, b, c, p mymath.Vector2Int) (bool, Weights) {
+	v0 := c.Subtract(a)
+	v1 := b.Subtract(a)
+	v2 := p.Subtract(a)
+
+	dot00 := v0.Dot(v0)
+	dot01 := v0.Dot(v1)
+	dot02 := v0.Dot(v2)
+	dot11 := v1.Dot(v1)
+	dot12 := v1.Dot(v2)
+
+	denom := dot00*dot11 - dot01*dot01
+	if denom == 0 {
+		return false, Weights{}
+	}
+
+	invDenom := float64(1) / float64(denom)
+	u := float64(dot11*dot02-dot01*dot12) * invDenom
+	v := float64(dot00*dot12-dot01*dot02) * invDenom
+
+	return u >= 0 && v >= 0 && u+v <= 1, Weights{A: 1 - u - v, B: v, C: u}
+}
+
+// ToRGBA converts a floating point color to 8 bit RGBA, clamping each channel to [0, 1]
+func ToRGBA(c mymath.Color3) color.RGBA {
+	return color.RGBA{
+		uint8(math.Min(float64(255), math.Max(float64(0), c.R*255))),
+		uint8(math.Min(float64(255), math.Max(float64(0), c.G*255))),
+		uint8(math.Min(float64(255), math.Max(float64(0), c.B*255))),
+		255,
+	}
+}
@@ -0,0 +1,120 @@
+package raster
+
+import (
+	"math"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+func TestBarycentricCoordinates(t *testing.T) {
+	a := mymath.Vector2Int{X: 0, Y: 0}
+	b := mymath.Vector2Int{X: 30, Y: 0}
+	c := mymath.Vector2Int{X: 0, Y: 30}
+
+	tests := []struct {
+		name   string
+		p      mymath.Vector2Int
+		inside bool
+		want   Weights
+	}{
+		{"vertex a", a, true, Weights{A: 1}},
+		{"vertex b", b, true, Weights{B: 1}},
+		{"vertex c", c, true, Weights{C: 1}},
+		{"centroid", mymath.Vector2Int{X: 10, Y: 10}, true, Weights{A: 1.0 / 3, B: 1.0 / 3, C: 1.0 / 3}},
+		{"edge midpoint", mymath.Vector2Int{X: 15, Y: 15}, true, Weights{B: 0.5, C: 0.5}},
+		{"outside", mymath.Vector2Int{X: 20, Y: 20}, false, Weights{A: -1.0 / 3, B: 2.0 / 3, C: 2.0 / 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inside, w := BarycentricCoordinates(a, b, c, tt.p)
+			if inside != tt.inside {
+				t.Errorf("inside = %v, want %v", inside, tt.inside)
+			}
+			if !weightsNear(w, tt.want) {
+				t.Errorf("weights = %+v, want %+v", w, tt.want)
+			}
+			if sum := w.A + w.B + w.C; math.Abs(sum-1) > 1e-12 {
+				t.Errorf("weights sum to %v", sum)
+			}
+		})
+	}
+}
+
+func TestBarycentricCoordinatesDegenerate(t *testing.T) {
+	a := mymath.Vector2Int{X: 0, Y: 0}
+	b := mymath.Vector2Int{X: 10, Y: 10}
+	c := mymath.Vector2Int{X: 20, Y: 20}
+
+	if inside, _ := BarycentricCoordinates(a, b, c, b); inside {
+		t.Error("a zero area triangle contains no points")
+	}
+}
+
+func weightsNear(a, b Weights) bool {
+	return math.Abs(a.A-b.A) < 1e-12 && math.Abs(a.B-b.B) < 1e-12 && math.Abs(a.C-b.C) < 1e-12
+}
+
+// fill draws every triangle and counts how often each pixel was drawn
+func fill(width, height int, triangles [][3]mymath.Vector2) map[mymath.Vector2Int]int {
+	r := NewRasterizer(NewFramebuffer(width, height))
+	drawn := map[mymath.Vector2Int]int{}
+	for _, t := range triangles {
+		r.FillTriangle(t[0], t[1], t[2], func(x, y int, w Weights) {
+			drawn[mymath.Vector2Int{X: x, Y: y}]++
+		})
+	}
+	return drawn
+}
+
+func TestFillTriangleSharedEdges(t *testing.T) {
+	v := func(x, y float64) mymath.Vector2 { return mymath.Vector2{X: x, Y: y} }
+
+	// Eight triangles fanned around the middle of a square
+	corners := []mymath.Vector2{v(0, 0), v(10, 0), v(20, 0), v(20, 10), v(20, 20), v(10, 20), v(0, 20), v(0, 10)}
+	var fan [][3]mymath.Vector2
+	for i := range corners {
+		fan = append(fan, [3]mymath.Vector2{v(10, 10), corners[i], corners[(i+1)%len(corners)]})
+	}
+
+	tests := []struct {
+		name      string
+		triangles [][3]mymath.Vector2
+		want      int
+	}{
+		{"single", [][3]mymath.Vector2{{v(0, 0), v(10, 0), v(0, 10)}}, 55}, // Centres on the long edge go to this triangle
+		{"square split one way", [][3]mymath.Vector2{{v(0, 0), v(10, 0), v(10, 10)}, {v(0, 0), v(10, 10), v(0, 10)}}, 100},
+		{"square split the other way", [][3]mymath.Vector2{{v(10, 0), v(10, 10), v(0, 10)}, {v(0, 0), v(10, 0), v(0, 10)}}, 100},
+		{"opposite windings", [][3]mymath.Vector2{{v(0, 0), v(10, 10), v(10, 0)}, {v(0, 0), v(10, 10), v(0, 10)}}, 100},
+		{"fan", fan, 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drawn := fill(64, 64, tt.triangles)
+			if len(drawn) != tt.want {
+				t.Errorf("covered %d pixels, want %d", len(drawn), tt.want)
+			}
+			for p, n := range drawn {
+				if n > 1 {
+					t.Errorf("pixel %v drawn %d times", p, n)
+				}
+			}
+		})
+	}
+}
+
+func TestFillTriangleClipsToFramebuffer(t *testing.T) {
+	v := func(x, y float64) mymath.Vector2 { return mymath.Vector2{X: x, Y: y} }
+	drawn := fill(10, 10, [][3]mymath.Vector2{{v(-100, -100), v(200, -100), v(-100, 200)}})
+
+	if len(drawn) != 100 {
+		t.Errorf("covered %d pixels, want the whole 10x10 framebuffer", len(drawn))
+	}
+	for p := range drawn {
+		if p.X < 0 || p.X >= 10 || p.Y < 0 || p.Y >= 10 {
+			t.Errorf("pixel %v is outside the framebuffer", p)
+		}
+	}
+}
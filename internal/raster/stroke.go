@@ -0,0 +1,175 @@
+package raster
+
+import (
+	"math"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// Line caps, the shape of the ends of an open line
+const (
+	ButtCap   = iota // Stops square at the end point
+	RoundCap         // A half circle around the end point
+	SquareCap        // Carries on half the width past the end point
+)
+
+// Line joins, the shape of the outside corner where two segments meet
+const (
+	MiterJoin = iota // Both edges carried on until they meet, unless that is past MiterLimit
+	RoundJoin        // A circle around the corner
+	BevelJoin        // The edges joined straight across
+)
+
+// Stroke describes how wide lines are drawn
+type Stroke struct {
+	Width      float64
+	Cap        int
+	Join       int
+	MiterLimit float64 // Longest miter, tip to inside corner, as a multiple of Width. Longer ones are bevelled.
+}
+
+func NewStroke(width float64) Stroke {
+	return Stroke{Width: width, Cap: ButtCap, Join: MiterJoin, MiterLimit: 4}
+}
+
+// Outline is the area covered by a line through points, as polygons that all wind the same way.
+// Filled with the NonZero rule they give the whole line with no pixel drawn twice.
+func (s Stroke) Outline(points []mymath.Vector2, closed bool) [][]mymath.Vector2 {
+	var unique []mymath.Vector2
+	for _, p := range points {
+		if len(unique) == 0 || p.Subtract(unique[len(unique)-1]).Magnitude() > 1e-9 {
+			unique = append(unique, p)
+		}
+	}
+	if closed && len(unique) > 1 && unique[0].Subtract(unique[len(unique)-1]).Magnitude() <= 1e-9 {
+		unique = unique[:len(unique)-1]
+	}
+
+	half := s.Width / 2
+	var polygons [][]mymath.Vector2
+	add := func(polygon ...mymath.Vector2) {
+		polygons = append(polygons, polygon)
+	}
+
+	switch {
+	case len(unique) == 0 || half <= 0:
+		return nil
+	case len(unique) == 1:
+		// A dot, which only has ends
+		p := unique[0]
+		switch s.Cap {
+		case RoundCap:
+			add(disc(p, half)...)
+		case SquareCap:
+			add(p.Add(mymath.Vector2{X: -half, Y: -half}), p.Add(mymath.Vector2{X: half, Y: -half}),
+				p.Add(mymath.Vector2{X: half, Y: half}), p.Add(mymath.Vector2{X: -half, Y: half}))
+		}
+		return orient(polygons)
+	case len(unique) == 2:
+		closed = false
+	}
+
+	segments := len(unique) - 1
+	if closed {
+		segments = len(unique)
+	}
+
+	direction := func(i int) mymath.Vector2 {
+		return unique[(i+1)%len(unique)].Subtract(unique[i]).Normalize()
+	}
+
+	for i := range segments {
+		a, b := unique[i], unique[(i+1)%len(unique)]
+		d := direction(i)
+		if !closed && s.Cap == SquareCap {
+			if i == 0 {
+				a = a.Subtract(d.Multiply(half))
+			}
+			if i == segments-1 {
+				b = b.Add(d.Multiply(half))
+			}
+		}
+		n := normal(d).Multiply(half)
+		add(a.Add(n), b.Add(n), b.Subtract(n), a.Subtract(n))
+	}
+
+	for i := range unique {
+		if !closed && (i == 0 || i == len(unique)-1) {
+			continue
+		}
+		s.join(unique[i], direction((i+len(unique)-1)%len(unique)), direction(i), add)
+	}
+
+	if !closed && s.Cap == RoundCap {
+		add(disc(unique[0], half)...)
+		add(disc(unique[len(unique)-1], half)...)
+	}
+
+	return orient(polygons)
+}
+
+// join fills the outside of the corner at p between a segment heading in and one heading out
+func (s Stroke) join(p, in, out mymath.Vector2, add func(...mymath.Vector2)) {
+	turn := in.Cross(out)
+	if math.Abs(turn) < 1e-9 && in.Dot(out) > 0 {
+		return // Straight on
+	}
+
+	half := s.Width / 2
+	if s.Join == RoundJoin {
+		add(disc(p, half)...)
+		return
+	}
+
+	// The outside of the corner is on the other side from the way the line turns
+	side := half
+	if turn > 0 {
+		side = -half
+	}
+	a, b := p.Add(normal(in).Multiply(side)), p.Add(normal(out).Multiply(side))
+
+	if s.Join == MiterJoin {
+		bisector, ok := a.Add(b).Subtract(p.Multiply(2)).NormalizeSafe()
+		if ok {
+			length := half / bisector.Dot(normal(in).Multiply(math.Copysign(1, side)))
+			if 2*length <= s.MiterLimit*s.Width {
+				add(p, a, p.Add(bisector.Multiply(length)), b)
+				return
+			}
+		}
+	}
+
+	add(p, a, b)
+}
+
+// normal is d turned a quarter turn
+func normal(d mymath.Vector2) mymath.Vector2 {
+	return mymath.Vector2{X: -d.Y, Y: d.X}
+}
+
+// disc is a circle of points about every 2 units apart
+func disc(centre mymath.Vector2, radius float64) []mymath.Vector2 {
+	n := max(8, int(math.Ceil(radius*math.Pi)))
+	points := make([]mymath.Vector2, n)
+	for i := range points {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		points[i] = centre.Add(mymath.Vector2{X: radius * cos, Y: radius * sin})
+	}
+	return points
+}
+
+// orient reverses polygons as needed so they all wind the same way
+func orient(polygons [][]mymath.Vector2) [][]mymath.Vector2 {
+	for _, polygon := range polygons {
+		area := 0.0
+		for i, p := range polygon {
+			area += p.Cross(polygon[(i+1)%len(polygon)])
+		}
+		if area < 0 {
+			for i, j := 0, len(polygon)-1; i < j; i, j = i+1, j-1 {
+				polygon[i], polygon[j] = polygon[j], polygon[i]
+			}
+		}
+	}
+	return polygons
+}
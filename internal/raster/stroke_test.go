@@ -0,0 +1,142 @@
+package raster
+
+import (
+	"math"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// area is how many pixels of a 60x60 framebuffer the polygons cover with the NonZero rule,
+// after moving them 10 pixels right and down so nothing is clipped
+func area(polygons [][]mymath.Vector2) float64 {
+	var contours [][]mymath.Vector2
+	for _, polygon := range polygons {
+		var moved []mymath.Vector2
+		for _, p := range polygon {
+			moved = append(moved, p.Add(mymath.Vector2{X: 10, Y: 10}))
+		}
+		contours = append(contours, moved)
+	}
+
+	total := 0.0
+	CoverPolygon(contours, NonZero, 60, 60, coverageRows, func(x, y int, c float64) {
+		total += c
+	})
+	return total
+}
+
+func TestStrokeOutlineArea(t *testing.T) {
+	line := []mymath.Vector2{{X: 0, Y: 0}, {X: 20, Y: 0}}
+	corner := []mymath.Vector2{{X: 0, Y: 0}, {X: 20, Y: 0}, {X: 20, Y: 20}}
+	square := []mymath.Vector2{{X: 0, Y: 0}, {X: 20, Y: 0}, {X: 20, Y: 20}, {X: 0, Y: 20}}
+	octagon := 2 * math.Sqrt2 * 2 * 2 // The disc drawn for a radius of 2
+
+	tests := []struct {
+		name   string
+		points []mymath.Vector2
+		closed bool
+		width  float64
+		cap    int
+		join   int
+		want   float64
+	}{
+		{"butt cap", line, false, 4, ButtCap, MiterJoin, 80},
+		{"square cap", line, false, 4, SquareCap, MiterJoin, 96},
+		{"round cap", line, false, 4, RoundCap, MiterJoin, 80 + octagon},
+		{"miter join", corner, false, 4, ButtCap, MiterJoin, 160},
+		{"bevel join", corner, false, 4, ButtCap, BevelJoin, 158},
+		{"round join", corner, false, 4, ButtCap, RoundJoin, 156 + octagon/4},
+		{"closed square", square, true, 2, ButtCap, MiterJoin, 22*22 - 18*18},
+		{"closing point repeated", append(square, square[0]), true, 2, ButtCap, MiterJoin, 22*22 - 18*18},
+		{"repeated points", []mymath.Vector2{{X: 0, Y: 0}, {X: 0, Y: 0}, {X: 20, Y: 0}, {X: 20, Y: 0}}, false, 4, ButtCap, MiterJoin, 80},
+		{"square dot", line[:1], false, 4, SquareCap, MiterJoin, 16},
+		{"round dot", line[:1], false, 4, RoundCap, MiterJoin, octagon},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStroke(tt.width)
+			s.Cap, s.Join = tt.cap, tt.join
+			if got := area(s.Outline(tt.points, tt.closed)); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("covered %v pixels, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrokeOutlineEmpty(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []mymath.Vector2
+		stroke Stroke
+	}{
+		{"no points", nil, NewStroke(4)},
+		{"butt dot", []mymath.Vector2{{X: 5, Y: 5}}, NewStroke(4)},
+		{"zero width", []mymath.Vector2{{X: 0, Y: 0}, {X: 20, Y: 0}}, NewStroke(0)},
+		{"negative width", []mymath.Vector2{{X: 0, Y: 0}, {X: 20, Y: 0}}, NewStroke(-1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stroke.Outline(tt.points, false); got != nil {
+				t.Errorf("got %v, want nil", got)
+			}
+		})
+	}
+}
+
+func TestStrokeOutlineOrientation(t *testing.T) {
+	// Both ways round, so some segments and joins start out wound the other way
+	zigzag := []mymath.Vector2{{X: 0, Y: 0}, {X: 10, Y: 10}, {X: 20, Y: 0}, {X: 30, Y: 10}, {X: 25, Y: 30}}
+	reversed := make([]mymath.Vector2, len(zigzag))
+	for i, p := range zigzag {
+		reversed[len(zigzag)-1-i] = p
+	}
+
+	for _, points := range [][]mymath.Vector2{zigzag, reversed} {
+		for _, join := range []int{MiterJoin, RoundJoin, BevelJoin} {
+			s := NewStroke(3)
+			s.Cap, s.Join = RoundCap, join
+			for i, polygon := range s.Outline(points, true) {
+				a := 0.0
+				for j, p := range polygon {
+					a += p.Cross(polygon[(j+1)%len(polygon)])
+				}
+				if a <= 0 {
+					t.Errorf("join %d: polygon %d has area %v", join, i, a/2)
+				}
+			}
+		}
+	}
+}
+
+func TestStrokeMiterLimit(t *testing.T) {
+	// Turning back on itself, so the miter would reach far past the corner
+	sharp := []mymath.Vector2{{X: 0, Y: 0}, {X: 30, Y: 0}, {X: 0, Y: 3}}
+	gentle := []mymath.Vector2{{X: 0, Y: 0}, {X: 30, Y: 0}, {X: 30, Y: 30}}
+
+	tests := []struct {
+		name     string
+		points   []mymath.Vector2
+		limit    float64
+		bevelled bool
+	}{
+		{"sharp corner", sharp, 4, true},
+		{"sharp corner under a long limit", sharp, 100, false},
+		{"right angle", gentle, 4, false},
+		{"right angle under a short limit", gentle, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			miter, bevel := NewStroke(4), NewStroke(4)
+			miter.MiterLimit = tt.limit
+			bevel.Join = BevelJoin
+			m, b := area(miter.Outline(tt.points, false)), area(bevel.Outline(tt.points, false))
+			if got := math.Abs(m-b) < 0.01; got != tt.bevelled {
+				t.Errorf("miter covered %v, bevel %v", m, b)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package raster
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg" // register JPEG decoding for LoadTexture
+	_ "image/png"  // register PNG decoding for LoadTexture
+	"math"
+	"os"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// Texture filtering
+const (
+	Nearest = iota
+	Bilinear
+)
+
+// Texture addressing, for coordinates outside [0, 1]
+const (
+	Wrap = iota
+	Clamp
+)
+
+// Texture is an image sampled with UV coordinates: u runs left to right and v bottom to top
+type Texture struct {
+	Width   int
+	Height  int
+	Filter  int
+	Address int
+	texels  []mymath.Color3
+}
+
+// NewTexture makes a black texture. An empty texture is an error, since there would be nothing
+// to sample.
+func NewTexture(width, height int) (*Texture, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("texture must be at least 1x1, not %dx%d", width, height)
+	}
+	return &Texture{
+		Width:  width,
+		Height: height,
+		texels: make([]mymath.Color3, width*height),
+	}, nil
+}
+
+// NewTextureFromImage copies an image into a texture. An empty image is an error.
+func NewTextureFromImage(img image.Image) (*Texture, error) {
+	bounds := img.Bounds()
+	t, err := NewTexture(bounds.Dx(), bounds.Dy())
+	if err != nil {
+		return nil, err
+	}
+
+	for y := range t.Height {
+		for x := range t.Width {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			t.Set(x, y, mymath.Color3{R: float64(r) / 0xffff, G: float64(g) / 0xffff, B: float64(b) / 0xffff})
+		}
+	}
+
+	return t, nil
+}
+
+// LoadTexture decodes a PNG or JPEG file
+func LoadTexture(path string) (*Texture, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTextureFromImage(img)
+}
+
+// Set writes a texel, 0,0 is the top left of the image
+func (t *Texture) Set(x, y int, c mymath.Color3) {
+	t.texels[y*t.Width+x] = c
+}
+
+func (t *Texture) texel(x, y int) mymath.Color3 {
+	return t.texels[t.address(y, t.Height)*t.Width+t.address(x, t.Width)]
+}
+
+func (t *Texture) address(i, size int) int {
+	if t.Address == Clamp {
+		return min(size-1, max(0, i))
+	}
+
+	i %= size
+	if i < 0 {
+		i += size
+	}
+	return i
+}
+
+// Sample looks up the color at uv using the texture's filter and address modes. Coordinates
+// that are NaN or infinite sample at 0.
+func (t *Texture) Sample(uv mymath.Vector2) mymath.Color3 {
+	uv = mymath.Vector2{X: t.limit(uv.X), Y: t.limit(uv.Y)}
+
+	// Texel centers sit at half integer positions
+	x := uv.X*float64(t.Width) - 0.5
+	y := (1-uv.Y)*float64(t.Height) - 0.5
+
+	if t.Filter == Nearest {
+		return t.texel(int(math.Round(x)), int(math.Round(y)))
+	}
+
+	x0 := math.Floor(x)
+	y0 := math.Floor(y)
+	fx := x - x0
+	fy := y - y0
+
+	top := t.texel(int(x0), int(y0)).Multiply(1 - fx).Add(t.texel(int(x0)+1, int(y0)).Multiply(fx))
+	bottom := t.texel(int(x0), int(y0)+1).Multiply(1 - fx).Add(t.texel(int(x0)+1, int(y0)+1).Multiply(fx))
+
+	return top.Multiply(1 - fy).Add(bottom.Multiply(fy))
+}
+
+// limit replaces NaN and infinite coordinates with 0, and brings huge ones back to where they
+// land on the texture so they still convert to an int
+func (t *Texture) limit(v float64) float64 {
+	switch {
+	case math.IsNaN(v) || math.IsInf(v, 0):
+		return 0
+	case math.Abs(v) < 1<<20:
+		return v
+	case t.Address == Clamp:
+		return math.Copysign(1<<20, v)
+	default:
+		return math.Mod(v, 1)
+	}
+}
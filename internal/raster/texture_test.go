@@ -0,0 +1,82 @@
+package raster
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+func TestNewTexture(t *testing.T) {
+	tests := []struct {
+		width, height int
+		ok            bool
+	}{
+		{1, 1, true},
+		{4, 2, true},
+		{0, 0, false},
+		{-1, 1, false},
+		{4, 0, false},
+	}
+
+	for _, tt := range tests {
+		tex, err := NewTexture(tt.width, tt.height)
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("%dx%d: got error %v", tt.width, tt.height, err)
+		}
+		if tt.ok && (tex.Width != tt.width || tex.Height != tt.height) {
+			t.Errorf("%dx%d: got %dx%d", tt.width, tt.height, tex.Width, tex.Height)
+		}
+	}
+}
+
+func TestNewTextureFromImageRejectsEmpty(t *testing.T) {
+	for _, r := range []image.Rectangle{image.Rect(0, 0, 0, 4), image.Rect(0, 0, 4, 0), image.Rect(2, 2, 2, 2)} {
+		if _, err := NewTextureFromImage(image.NewRGBA(r)); err == nil {
+			t.Errorf("%v: no error", r)
+		}
+	}
+
+	if _, err := NewTextureFromImage(image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Errorf("1x1: %v", err)
+	}
+}
+
+// checker is a 2x2 texture, white in the top left and bottom right
+func checker(filter, address int) *Texture {
+	tex, _ := NewTexture(2, 2)
+	tex.Filter, tex.Address = filter, address
+	tex.Set(0, 0, mymath.Color3{R: 1, G: 1, B: 1})
+	tex.Set(1, 1, mymath.Color3{R: 1, G: 1, B: 1})
+	return tex
+}
+
+func TestSample(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  int
+		address int
+		uv      mymath.Vector2
+		want    float64
+	}{
+		{"top left", Nearest, Wrap, mymath.Vector2{X: 0.25, Y: 0.75}, 1},
+		{"top right", Nearest, Wrap, mymath.Vector2{X: 0.75, Y: 0.75}, 0},
+		{"wrapped", Nearest, Wrap, mymath.Vector2{X: 1.25, Y: -0.25}, 1},
+		{"clamped", Nearest, Clamp, mymath.Vector2{X: 5, Y: -5}, 1},
+		{"between texels", Bilinear, Clamp, mymath.Vector2{X: 0.5, Y: 0.75}, 0.5},
+		{"NaN", Bilinear, Wrap, mymath.Vector2{X: math.NaN(), Y: math.NaN()}, 0.5},
+		{"infinite", Bilinear, Clamp, mymath.Vector2{X: math.Inf(1), Y: math.Inf(-1)}, 0},
+		{"huge wrapped", Nearest, Wrap, mymath.Vector2{X: 1e300, Y: -1e300}, 0},
+		{"huge clamped", Nearest, Clamp, mymath.Vector2{X: 1e300, Y: -1e300}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checker(tt.filter, tt.address).Sample(tt.uv)
+			if got.R != tt.want {
+				t.Errorf("got %v, want %v", got.R, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+// Package scenefile reads and writes scene descriptions as JSON, so that gears, stars, meshes,
+// lights, cameras and materials can be changed without recompiling. Each example reads the
+// parts of a scene it knows how to draw and ignores the rest.
+//
+// Only JSON is supported. YAML would need a third party parser, so it was left out.
+package scenefile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/insood/graphics/internal/lighting"
+	mymath "github.com/insood/graphics/internal/math"
+	"github.com/insood/graphics/internal/raster"
+)
+
+// Vector3 is written as [x, y, z]
+type Vector3 [3]float64
+
+func (v Vector3) Vec() mymath.Vector3 {
+	return mymath.Vector3{X: v[0], Y: v[1], Z: v[2]}
+}
+
+func FromVector3(v mymath.Vector3) Vector3 {
+	return Vector3{v.X, v.Y, v.Z}
+}
+
+// Color is written as [r, g, b] with each channel from 0 to 1. Light colors may go above 1.
+type Color [3]float64
+
+func (c Color) Color3() mymath.Color3 {
+	return mymath.Color3{R: c[0], G: c[1], B: c[2]}
+}
+
+func (c Color) RGBA() color.RGBA {
+	return raster.ToRGBA(c.Color3())
+}
+
+func FromColor3(c mymath.Color3) Color {
+	return Color{c.R, c.G, c.B}
+}
+
+func FromRGBA(c color.RGBA) Color {
+	return Color{float64(c.R) / 255, float64(c.G) / 255, float64(c.B) / 255}
+}
+
+type Scene struct {
+	Camera    *Camera             `json:"camera,omitempty"`
+	Materials map[string]Material `json:"materials,omitempty"`
+	LightRigs [][]Light           `json:"lightRigs,omitempty"`
+	Meshes    []Mesh              `json:"meshes,omitempty"`
+	Carriers  []Carrier           `json:"carriers,omitempty"`
+	Gears     []Gear              `json:"gears,omitempty"`
+	RingGears []RingGear          `json:"ringGears,omitempty"`
+	Train     *Train              `json:"train,omitempty"`
+	Teeth     *Teeth              `json:"teeth,omitempty"`
+	Starfield *Starfield          `json:"starfield,omitempty"`
+
+	dir string // Directory of the file the scene was loaded from
+}
+
+// Camera settings are optional. Zero values mean the example's own default.
+type Camera struct {
+	Position *Vector3 `json:"position,omitempty"` // Eye position for 3D examples
+	Target   *Vector3 `json:"target,omitempty"`   // Point the 2D camera is centered on
+	Zoom     float64  `json:"zoom,omitempty"`
+	Rotation float64  `json:"rotation,omitempty"` // Radians, clockwise
+	FOV      float64  `json:"fov,omitempty"`      // Horizontal field of view in radians
+	Near     float64  `json:"near,omitempty"`     // Distances in front of the camera
+	Far      float64  `json:"far,omitempty"`
+}
+
+type Material struct {
+	Ambient   Color   `json:"ambient"`
+	Diffuse   Color   `json:"diffuse"`
+	Specular  Color   `json:"specular"`
+	Shininess float64 `json:"shininess"`
+	Metallic  float64 `json:"metallic"`
+	Roughness float64 `json:"roughness"`
+}
+
+func (m Material) Lighting() lighting.Material {
+	return lighting.Material{
+		Ambient:   m.Ambient.Color3(),
+		Diffuse:   m.Diffuse.Color3(),
+		Specular:  m.Specular.Color3(),
+		Shininess: m.Shininess,
+		Metallic:  m.Metallic,
+		Roughness: m.Roughness,
+	}
+}
+
+func FromMaterial(m lighting.Material) Material {
+	return Material{
+		Ambient:   FromColor3(m.Ambient),
+		Diffuse:   FromColor3(m.Diffuse),
+		Specular:  FromColor3(m.Specular),
+		Shininess: m.Shininess,
+		Metallic:  m.Metallic,
+		Roughness: m.Roughness,
+	}
+}
+
+// Light types as written in the file
+var lightTypes = map[string]int{
+	"directional": lighting.Directional,
+	"point":       lighting.Point,
+	"spot":        lighting.Spot,
+}
+
+// Light mirrors lighting.Light. Cone angles are half angles in radians.
+type Light struct {
+	Type      string   `json:"type"`
+	Color     Color    `json:"color"`
+	Position  *Vector3 `json:"position,omitempty"`
+	Direction *Vector3 `json:"direction,omitempty"`
+	Constant  float64  `json:"constant,omitempty"`
+	Linear    float64  `json:"linear,omitempty"`
+	Quadratic float64  `json:"quadratic,omitempty"`
+	InnerCone float64  `json:"innerCone,omitempty"`
+	OuterCone float64  `json:"outerCone,omitempty"`
+}
+
+func (l Light) Lighting() lighting.Light {
+	light := lighting.Light{
+		Type:      lightTypes[l.Type],
+		Color:     l.Color.Color3(),
+		Constant:  l.Constant,
+		Linear:    l.Linear,
+		Quadratic: l.Quadratic,
+		InnerCone: l.InnerCone,
+		OuterCone: l.OuterCone,
+	}
+
+	if l.Position != nil {
+		light.Position = l.Position.Vec()
+	}
+	if l.Direction != nil {
+		light.Direction = l.Direction.Vec().Normalize()
+	}
+
+	return light
+}
+
+func FromLight(l lighting.Light) Light {
+	light := Light{
+		Color:     FromColor3(l.Color),
+		Constant:  l.Constant,
+		Linear:    l.Linear,
+		Quadratic: l.Quadratic,
+		InnerCone: l.InnerCone,
+		OuterCone: l.OuterCone,
+	}
+
+	for name, t := range lightTypes {
+		if t == l.Type {
+			light.Type = name
+		}
+	}
+
+	position, direction := FromVector3(l.Position), FromVector3(l.Direction)
+	if l.Type != lighting.Directional {
+		light.Position = &position
+	}
+	if l.Type != lighting.Point {
+		light.Direction = &direction
+	}
+
+	return light
+}
+
+// Mesh is either a Wavefront OBJ model or a built in shape, scaled to fit Radius
+type Mesh struct {
+	Name      string  `json:"name,omitempty"`
+	Model     string  `json:"model,omitempty"` // Relative to the scene file
+	Shape     string  `json:"shape,omitempty"` // "sphere" or "triangle"
+	Radius    float64 `json:"radius"`
+	Divisions int     `json:"divisions,omitempty"` // Sphere only
+	Material  string  `json:"material,omitempty"`  // Key into Materials, or the example's default
+}
+
+// Carrier is an arm that turns about its origin carrying gears with it
+type Carrier struct {
+	Name     string  `json:"name"`
+	Speed    float64 `json:"speed,omitempty"` // Radians per frame
+	Rotation float64 `json:"rotation,omitempty"`
+}
+
+type Gear struct {
+	Name     string  `json:"name"`
+	Teeth    int     `json:"teeth"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Radius   float64 `json:"radius"`          // Pitch radius
+	Speed    float64 `json:"speed,omitempty"` // Radians per frame
+	Rotation float64 `json:"rotation,omitempty"`
+	Color    Color   `json:"color"`
+	Carrier  string  `json:"carrier,omitempty"` // Name of the carrier the gear rides on
+}
+
+type RingGear struct {
+	Name      string  `json:"name"`
+	Teeth     int     `json:"teeth"`
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	Radius    float64 `json:"radius"`
+	Thickness float64 `json:"thickness"` // Inner edge of the race, and the pitch circle, as a fraction of Radius
+	Speed     float64 `json:"speed,omitempty"`
+	Rotation  float64 `json:"rotation,omitempty"`
+	Color     Color   `json:"color"`
+}
+
+// Teeth shapes the involute teeth of every gear. Zero values mean a standard 20 degree tooth.
+type Teeth struct {
+	PressureAngle float64 `json:"pressureAngle,omitempty"` // Radians
+	Addendum      float64 `json:"addendum,omitempty"`      // Tooth height above the pitch circle, in modules
+	Dedendum      float64 `json:"dedendum,omitempty"`      // Gap depth below the pitch circle, in modules
+	Backlash      float64 `json:"backlash,omitempty"`      // In modules
+}
+
+// Train links gears and carriers so their speeds come from their teeth. Members of the train
+// ignore their own speed, and gears meshing with the input are turned so their teeth fit.
+type Train struct {
+	Input  string      `json:"input"`            // Name of the gear or carrier that is driven
+	Speed  float64     `json:"speed"`            // Radians per frame of the input
+	Fixed  string      `json:"fixed,omitempty"`  // Name of the member held still
+	Meshes [][2]string `json:"meshes"`           // Pairs of gears whose teeth touch
+	Shafts [][2]string `json:"shafts,omitempty"` // Pairs of members that turn together
+}
+
+type Starfield struct {
+	Count        int     `json:"count"`
+	Speed        float64 `json:"speed"`        // Distance moved per frame
+	Acceleration float64 `json:"acceleration"` // Added to Speed every frame
+	Depth        float64 `json:"depth"`        // Stars appear this far away
+	Seed         int64   `json:"seed,omitempty"`
+}
+
+// Resolve makes a path from the scene file relative to the scene file's directory
+func (s *Scene) Resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.dir, path)
+}
+
+// Load reads and validates a scene file. Every problem found is reported, each naming the
+// file and the offending field.
+func Load(path string) (*Scene, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, errs := parse(data)
+	if len(errs) > 0 {
+		for _, e := range errs {
+			e.File = path
+		}
+		return nil, join(errs)
+	}
+
+	s.dir = filepath.Dir(path)
+	return s, nil
+}
+
+// Parse reads and validates a scene from JSON
+func Parse(data []byte) (*Scene, error) {
+	s, errs := parse(data)
+	if len(errs) > 0 {
+		return nil, join(errs)
+	}
+	return s, nil
+}
+
+func join(errs []*FieldError) error {
+	joined := make([]error, len(errs))
+	for i, e := range errs {
+		joined[i] = e
+	}
+	return errors.Join(joined...)
+}
+
+func parse(data []byte) (*Scene, []*FieldError) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var s Scene
+	if err := decoder.Decode(&s); err != nil {
+		return nil, []*FieldError{decodeError(data, decoder.InputOffset(), err)}
+	}
+
+	// A file holds one scene, with nothing after it but white space
+	end := decoder.InputOffset()
+	if extra := bytes.TrimLeft(data[end:], " \t\r\n"); len(extra) > 0 {
+		offset := int64(len(data) - len(extra))
+		return nil, []*FieldError{{Line: lineOf(data, offset), Message: "unexpected data after the scene"}}
+	}
+
+	if errs := s.Validate(); len(errs) > 0 {
+		return nil, errs
+	}
+	return &s, nil
+}
+
+var arrayIndex = regexp.MustCompile(`\.(\d+)`)
+
+// decodeError turns a JSON error into a FieldError with a line number. Errors without an
+// offset of their own, such as unknown fields, use where the decoder stopped.
+func decodeError(data []byte, offset int64, err error) *FieldError {
+	var syntax *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntax):
+		return &FieldError{Line: lineOf(data, syntax.Offset), Message: syntax.Error()}
+	case errors.As(err, &typeError):
+		field := arrayIndex.ReplaceAllString(typeError.Field, "[$1]") // gears.0.teeth -> gears[0].teeth
+		message := fmt.Sprintf("expected %s, got %s", typeError.Type, typeError.Value)
+		return &FieldError{Line: lineOf(data, typeError.Offset), Field: field, Message: message}
+	}
+
+	message := strings.TrimPrefix(err.Error(), "json: ")
+
+	// The decoder only notices an unknown field once it has read the whole object, so look back
+	// for the last place the key was written
+	if name, ok := strings.CutPrefix(message, "unknown field "); ok {
+		key := regexp.MustCompile(regexp.QuoteMeta(name) + `\s*:`)
+		if found := key.FindAllIndex(data[:min(int(offset), len(data))], -1); len(found) > 0 {
+			offset = int64(found[len(found)-1][0])
+		}
+	}
+
+	return &FieldError{Line: lineOf(data, offset), Message: message}
+}
+
+func lineOf(data []byte, offset int64) int {
+	return bytes.Count(data[:min(int(offset), len(data))], []byte("\n")) + 1
+}
+
+var scalarList = regexp.MustCompile(`\[\s*(?:[-+0-9.eE]+|"[^"\\\[\]]*")(?:,\s*(?:[-+0-9.eE]+|"[^"\\\[\]]*"))*\s*\]`)
+
+// Save writes s as indented JSON
+func Save(path string, s *Scene) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Keep vectors, colors and pairs of names on one line
+	data = scalarList.ReplaceAllFunc(data, func(list []byte) []byte {
+		var items []json.RawMessage
+		if err := json.Unmarshal(list, &items); err != nil {
+			return list
+		}
+		joined := make([][]byte, len(items))
+		for i, item := range items {
+			joined[i] = item
+		}
+		return append(append([]byte("["), bytes.Join(joined, []byte(", "))...), ']')
+	})
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,94 @@
+package scenefile
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	position, direction, target := Vector3{1, 2, 3}, Vector3{0, -1, 0.5}, Vector3{0.25, -0.5, 0}
+
+	scene := &Scene{
+		Camera: &Camera{Position: &position, Target: &target, Zoom: 2, Rotation: 0.1, FOV: 1.2, Near: 0.5, Far: 100},
+		Materials: map[string]Material{
+			"red": {Ambient: Color{0.1, 0, 0}, Diffuse: Color{0.8, 0, 0}, Specular: Color{1, 1, 1}, Shininess: 32, Metallic: 0.5, Roughness: 0.25},
+		},
+		LightRigs: [][]Light{{
+			{Type: "directional", Color: Color{1, 1, 1}, Direction: &direction},
+			{Type: "point", Color: Color{2, 2, 2}, Position: &position, Constant: 1, Linear: 0.1, Quadratic: 0.01},
+			{Type: "spot", Color: Color{1, 0.5, 0}, Position: &position, Direction: &direction, InnerCone: 0.2, OuterCone: 0.4},
+		}},
+		Meshes:    []Mesh{{Name: "ball", Shape: "sphere", Radius: 1, Divisions: 12, Material: "red"}, {Model: "teapot.obj", Radius: 2}},
+		Carriers:  []Carrier{{Name: "arm", Speed: 0.01, Rotation: 1}},
+		Gears:     []Gear{{Name: "sun", Teeth: 20, Radius: 0.5, Color: Color{1, 1, 0}}, {Name: "planet", Teeth: 10, X: 0.75, Radius: 0.25, Color: Color{0, 1, 0}, Carrier: "arm"}},
+		RingGears: []RingGear{{Name: "ring", Teeth: 40, Radius: 1.2, Thickness: 0.8, Color: Color{0.5, 0.5, 0.5}}},
+		Train:     &Train{Input: "sun", Speed: 0.02, Fixed: "ring", Meshes: [][2]string{{"sun", "planet"}, {"planet", "ring"}}},
+		Teeth:     &Teeth{PressureAngle: 0.4, Addendum: 1, Dedendum: 1.25, Backlash: 0.05},
+		Starfield: &Starfield{Count: 100, Speed: 1, Acceleration: 0.01, Depth: 500, Seed: 7},
+	}
+
+	path := filepath.Join(t.TempDir(), "scene.json")
+	if err := Save(path, scene); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Resolve("teapot.obj") != filepath.Join(filepath.Dir(path), "teapot.obj") {
+		t.Errorf("models resolve to %q", loaded.Resolve("teapot.obj"))
+	}
+	loaded.dir = ""
+	if !reflect.DeepEqual(loaded, scene) {
+		t.Errorf("got %+v, want %+v", loaded, scene)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"syntax", "{\n  \"gears\": [\n    {\"name\": \"a\",}\n  ]\n}", "line 3: "},
+		{"wrong type", "{\n  \"gears\": [\n    {\"name\": \"a\", \"teeth\": \"many\"}\n  ]\n}", "line 3: gears[0].teeth: expected int, got string"},
+		{"unknown field", "{\n  \"camera\": {},\n  \"gearz\": []\n}", `line 3: unknown field "gearz"`},
+		{"unknown nested field", "{\n  \"camera\": {\n    \"zoom\": 1,\n    \"zom\": 2\n  }\n}", `line 4: unknown field "zom"`},
+		{"trailing object", "{\"starfield\": {\"count\": 1, \"depth\": 1}}\n\n{}\n", "line 3: unexpected data after the scene"},
+		{"trailing junk", "{}\n  junk", "line 2: unexpected data after the scene"},
+		{"empty", "", "EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.json))
+			if err == nil {
+				t.Fatalf("no error, want %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAllowsTrailingSpace(t *testing.T) {
+	if _, err := Parse([]byte("{}\n \t\r\n")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoadNamesTheFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.json")
+	if err := Save(path, &Scene{Starfield: &Starfield{Depth: -1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if want := path + ": starfield.depth: must be positive"; err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,211 @@
+package scenefile
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/insood/graphics/internal/gearprofile"
+	"github.com/insood/graphics/internal/lighting"
+)
+
+// FieldError is a problem with one field of a scene, such as "gears[2].teeth"
+type FieldError struct {
+	File    string
+	Line    int // Only known for errors found while decoding the JSON
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	s := e.Message
+	if e.Field != "" {
+		s = e.Field + ": " + s
+	}
+	if e.Line > 0 {
+		s = fmt.Sprintf("line %d: %s", e.Line, s)
+	}
+	if e.File != "" {
+		s = e.File + ": " + s
+	}
+	return s
+}
+
+// validator collects every problem rather than stopping at the first
+type validator struct {
+	errs []*FieldError
+}
+
+func (v *validator) check(ok bool, field, format string, args ...any) {
+	if !ok {
+		v.errs = append(v.errs, &FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+func (v *validator) color(c Color, field string, maximum float64) {
+	for i, channel := range c {
+		v.check(channel >= 0 && channel <= maximum, fmt.Sprintf("%s[%d]", field, i), "must be between 0 and %g", maximum)
+	}
+}
+
+// Validate returns every field that is out of range or refers to something missing
+func (s *Scene) Validate() []*FieldError {
+	v := &validator{}
+
+	if c := s.Camera; c != nil {
+		v.check(c.Zoom >= 0, "camera.zoom", "must not be negative")
+		v.check(c.FOV >= 0 && c.FOV < math.Pi, "camera.fov", "must be between 0 and pi radians")
+		v.check(c.Near >= 0, "camera.near", "must not be negative")
+		v.check(c.Far >= 0, "camera.far", "must not be negative")
+		if c.Near != 0 && c.Far != 0 {
+			v.check(c.Far > c.Near, "camera.far", "must be greater than camera.near")
+		}
+	}
+
+	materials := make([]string, 0, len(s.Materials))
+	for name := range s.Materials {
+		materials = append(materials, name)
+	}
+	sort.Strings(materials) // Report problems in a stable order
+
+	for _, name := range materials {
+		m := s.Materials[name]
+		field := fmt.Sprintf("materials[%q]", name)
+		v.color(m.Ambient, field+".ambient", 1)
+		v.color(m.Diffuse, field+".diffuse", 1)
+		v.color(m.Specular, field+".specular", 1)
+		v.check(m.Shininess >= 0, field+".shininess", "must not be negative")
+		v.check(m.Metallic >= 0 && m.Metallic <= 1, field+".metallic", "must be between 0 and 1")
+		v.check(m.Roughness >= 0 && m.Roughness <= 1, field+".roughness", "must be between 0 and 1")
+	}
+
+	for i, rig := range s.LightRigs {
+		for j, l := range rig {
+			v.light(l, fmt.Sprintf("lightRigs[%d][%d]", i, j))
+		}
+	}
+
+	for i, m := range s.Meshes {
+		field := fmt.Sprintf("meshes[%d]", i)
+		v.check((m.Model == "") != (m.Shape == ""), field, "needs exactly one of model or shape")
+		v.check(m.Shape == "" || m.Shape == "sphere" || m.Shape == "triangle", field+".shape", "must be sphere or triangle, not %q", m.Shape)
+		v.check(m.Radius > 0, field+".radius", "must be positive")
+		if m.Shape == "sphere" {
+			v.check(m.Divisions >= 2, field+".divisions", "must be at least 2")
+		}
+		if m.Material != "" {
+			_, ok := s.Materials[m.Material]
+			v.check(ok, field+".material", "no material named %q", m.Material)
+		}
+	}
+
+	names := map[string]bool{}
+	unique := func(name, field string) {
+		v.check(name != "", field, "must not be empty")
+		v.check(name == "" || !names[name], field, "%q is used more than once", name)
+		names[name] = true
+	}
+
+	carriers := map[string]bool{}
+	for i, c := range s.Carriers {
+		unique(c.Name, fmt.Sprintf("carriers[%d].name", i))
+		carriers[c.Name] = true
+	}
+
+	for i, g := range s.Gears {
+		field := fmt.Sprintf("gears[%d]", i)
+		unique(g.Name, field+".name")
+		v.check(g.Teeth >= 3, field+".teeth", "must be at least 3")
+		v.check(g.Radius > 0, field+".radius", "must be positive")
+		v.color(g.Color, field+".color", 1)
+		v.check(g.Carrier == "" || carriers[g.Carrier], field+".carrier", "no carrier named %q", g.Carrier)
+	}
+
+	for i, g := range s.RingGears {
+		field := fmt.Sprintf("ringGears[%d]", i)
+		unique(g.Name, field+".name")
+		v.check(g.Teeth >= 3, field+".teeth", "must be at least 3")
+		v.check(g.Radius > 0, field+".radius", "must be positive")
+		v.check(g.Thickness > 0 && g.Thickness < 1, field+".thickness", "must be between 0 and 1")
+		if g.Teeth >= 3 && g.Thickness > 0 && g.Thickness < 1 {
+			limit := s.ringThickness(g.Teeth)
+			v.check(g.Thickness < limit, field+".thickness", "must be less than %.4g so the gaps between teeth don't cut through the rim", limit)
+		}
+		v.color(g.Color, field+".color", 1)
+	}
+
+	if t := s.Train; t != nil {
+		member := func(name, field string) {
+			v.check(names[name], field, "no gear, ring gear or carrier named %q", name)
+		}
+
+		member(t.Input, "train.input")
+		if t.Fixed != "" {
+			member(t.Fixed, "train.fixed")
+			v.check(t.Fixed != t.Input, "train.fixed", "must not be the input")
+		}
+		for i, pair := range t.Meshes {
+			for j, name := range pair {
+				member(name, fmt.Sprintf("train.meshes[%d][%d]", i, j))
+				v.check(!carriers[name], fmt.Sprintf("train.meshes[%d][%d]", i, j), "%q is a carrier, which has no teeth", name)
+			}
+		}
+		for i, pair := range t.Shafts {
+			for j, name := range pair {
+				member(name, fmt.Sprintf("train.shafts[%d][%d]", i, j))
+			}
+		}
+	}
+
+	if t := s.Teeth; t != nil {
+		v.check(t.PressureAngle >= 0 && t.PressureAngle < math.Pi/4, "teeth.pressureAngle", "must be between 0 and pi/4 radians")
+		v.check(t.Addendum >= 0, "teeth.addendum", "must not be negative")
+		v.check(t.Dedendum >= 0, "teeth.dedendum", "must not be negative")
+		v.check(t.Backlash >= 0 && t.Backlash < math.Pi/2, "teeth.backlash", "must be between 0 and pi/2 modules")
+	}
+
+	if f := s.Starfield; f != nil {
+		v.check(f.Count >= 0, "starfield.count", "must not be negative")
+		v.check(f.Depth > 0, "starfield.depth", "must be positive")
+	}
+
+	return v.errs
+}
+
+// ringThickness is the largest thickness a ring gear can have before the roots of its teeth
+// reach its outer edge. The pitch circle is at thickness, so the teeth scale with it.
+func (s *Scene) ringThickness(teeth int) float64 {
+	p := gearprofile.StandardRing(1, teeth)
+	if s.Teeth != nil && s.Teeth.Dedendum != 0 {
+		p.Dedendum = s.Teeth.Dedendum
+	}
+	return p.PitchRadius() / p.RootRadius()
+}
+
+func (v *validator) light(l Light, field string) {
+	t, ok := lightTypes[l.Type]
+	v.check(ok, field+".type", "must be directional, point or spot, not %q", l.Type)
+	for i, channel := range l.Color {
+		v.check(channel >= 0, fmt.Sprintf("%s.color[%d]", field, i), "must not be negative")
+	}
+	v.check(l.Constant >= 0, field+".constant", "must not be negative")
+	v.check(l.Linear >= 0, field+".linear", "must not be negative")
+	v.check(l.Quadratic >= 0, field+".quadratic", "must not be negative")
+
+	if !ok {
+		return
+	}
+
+	if t != lighting.Directional {
+		v.check(l.Position != nil, field+".position", "is required for %s lights", l.Type)
+	}
+
+	if t != lighting.Point {
+		v.check(l.Direction != nil && l.Direction.Vec().Magnitude() > 0, field+".direction", "is required for %s lights and must not be zero", l.Type)
+	}
+
+	if t == lighting.Spot {
+		v.check(l.InnerCone >= 0 && l.InnerCone <= l.OuterCone, field+".innerCone", "must be between 0 and outerCone")
+		v.check(l.OuterCone > 0 && l.OuterCone < math.Pi/2, field+".outerCone", "must be between 0 and pi/2 radians")
+	}
+}
@@ -0,0 +1,108 @@
+package scenefile
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string // One of the errors, as "field: message"
+	}{
+		{"zoom", `{"camera": {"zoom": -1}}`, "camera.zoom: must not be negative"},
+		{"fov", `{"camera": {"fov": 4}}`, "camera.fov: must be between 0 and pi radians"},
+		{"near", `{"camera": {"near": -1}}`, "camera.near: must not be negative"},
+		{"far", `{"camera": {"far": -1}}`, "camera.far: must not be negative"},
+		{"far before near", `{"camera": {"near": 10, "far": 5}}`, "camera.far: must be greater than camera.near"},
+
+		{"material color", `{"materials": {"m": {"diffuse": [0, 2, 0]}}}`, `materials["m"].diffuse[1]: must be between 0 and 1`},
+		{"shininess", `{"materials": {"m": {"shininess": -1}}}`, `materials["m"].shininess: must not be negative`},
+		{"metallic", `{"materials": {"m": {"metallic": 2}}}`, `materials["m"].metallic: must be between 0 and 1`},
+		{"roughness", `{"materials": {"m": {"roughness": -0.5}}}`, `materials["m"].roughness: must be between 0 and 1`},
+
+		{"light type", `{"lightRigs": [[{"type": "sun"}]]}`, `lightRigs[0][0].type: must be directional, point or spot, not "sun"`},
+		{"light color", `{"lightRigs": [[{"type": "point", "position": [0, 0, 0], "color": [-1, 0, 0]}]]}`, "lightRigs[0][0].color[0]: must not be negative"},
+		{"constant", `{"lightRigs": [[{"type": "point", "position": [0, 0, 0], "constant": -1}]]}`, "lightRigs[0][0].constant: must not be negative"},
+		{"linear", `{"lightRigs": [[{"type": "point", "position": [0, 0, 0], "linear": -1}]]}`, "lightRigs[0][0].linear: must not be negative"},
+		{"quadratic", `{"lightRigs": [[{"type": "point", "position": [0, 0, 0], "quadratic": -1}]]}`, "lightRigs[0][0].quadratic: must not be negative"},
+		{"position", `{"lightRigs": [[], [{"type": "point"}]]}`, "lightRigs[1][0].position: is required for point lights"},
+		{"direction", `{"lightRigs": [[{"type": "directional", "direction": [0, 0, 0]}]]}`, "lightRigs[0][0].direction: is required for directional lights and must not be zero"},
+		{"inner cone", `{"lightRigs": [[{"type": "spot", "position": [0, 0, 0], "direction": [0, 0, -1], "innerCone": 0.5, "outerCone": 0.4}]]}`, "lightRigs[0][0].innerCone: must be between 0 and outerCone"},
+		{"outer cone", `{"lightRigs": [[{"type": "spot", "position": [0, 0, 0], "direction": [0, 0, -1], "outerCone": 2}]]}`, "lightRigs[0][0].outerCone: must be between 0 and pi/2 radians"},
+
+		{"model and shape", `{"meshes": [{"model": "a.obj", "shape": "sphere", "radius": 1, "divisions": 4}]}`, "meshes[0]: needs exactly one of model or shape"},
+		{"shape", `{"meshes": [{"shape": "cube", "radius": 1}]}`, `meshes[0].shape: must be sphere or triangle, not "cube"`},
+		{"mesh radius", `{"meshes": [{"shape": "triangle", "radius": 0}]}`, "meshes[0].radius: must be positive"},
+		{"divisions", `{"meshes": [{"shape": "sphere", "radius": 1, "divisions": 1}]}`, "meshes[0].divisions: must be at least 2"},
+		{"mesh material", `{"meshes": [{"shape": "triangle", "radius": 1, "material": "gold"}]}`, `meshes[0].material: no material named "gold"`},
+
+		{"carrier name", `{"carriers": [{"name": ""}]}`, "carriers[0].name: must not be empty"},
+		{"duplicate name", `{"carriers": [{"name": "a"}], "gears": [{"name": "a", "teeth": 10, "radius": 1}]}`, `gears[0].name: "a" is used more than once`},
+		{"gear teeth", `{"gears": [{"name": "a", "teeth": 2, "radius": 1}]}`, "gears[0].teeth: must be at least 3"},
+		{"gear radius", `{"gears": [{"name": "a", "teeth": 10, "radius": 0}]}`, "gears[0].radius: must be positive"},
+		{"gear color", `{"gears": [{"name": "a", "teeth": 10, "radius": 1, "color": [0, 0, 1.5]}]}`, "gears[0].color[2]: must be between 0 and 1"},
+		{"gear carrier", `{"gears": [{"name": "a", "teeth": 10, "radius": 1, "carrier": "arm"}]}`, `gears[0].carrier: no carrier named "arm"`},
+
+		{"ring teeth", `{"ringGears": [{"name": "r", "teeth": 2, "radius": 1, "thickness": 0.5}]}`, "ringGears[0].teeth: must be at least"},
+		{"ring radius", `{"ringGears": [{"name": "r", "teeth": 40, "radius": -1, "thickness": 0.5}]}`, "ringGears[0].radius: must be positive"},
+		{"thickness", `{"ringGears": [{"name": "r", "teeth": 40, "radius": 1, "thickness": 1}]}`, "ringGears[0].thickness: must be between 0 and 1"},
+		{"thick ring", `{"ringGears": [{"name": "r", "teeth": 80, "radius": 1, "thickness": 0.98}]}`, "ringGears[0].thickness: must be less than 0.9697 so the gaps between teeth don't cut through the rim"},
+		{"thick ring with deep teeth", `{"teeth": {"dedendum": 3}, "ringGears": [{"name": "r", "teeth": 80, "radius": 1, "thickness": 0.95}]}`, "ringGears[0].thickness: must be less than 0.9302"},
+
+		{"input", `{"train": {"input": "motor"}}`, `train.input: no gear, ring gear or carrier named "motor"`},
+		{"fixed", `{"carriers": [{"name": "a"}], "train": {"input": "a", "fixed": "b"}}`, `train.fixed: no gear, ring gear or carrier named "b"`},
+		{"fixed input", `{"carriers": [{"name": "a"}], "train": {"input": "a", "fixed": "a"}}`, "train.fixed: must not be the input"},
+		{"mesh member", `{"carriers": [{"name": "a"}], "train": {"input": "a", "meshes": [["a", "b"]]}}`, `train.meshes[0][1]: no gear, ring gear or carrier named "b"`},
+		{"mesh carrier", `{"carriers": [{"name": "a"}], "train": {"input": "a", "meshes": [["a", "a"]]}}`, `train.meshes[0][0]: "a" is a carrier, which has no teeth`},
+		{"shaft member", `{"carriers": [{"name": "a"}], "train": {"input": "a", "shafts": [["c", "a"]]}}`, `train.shafts[0][0]: no gear, ring gear or carrier named "c"`},
+
+		{"pressure angle", `{"teeth": {"pressureAngle": 1}}`, "teeth.pressureAngle: must be between 0 and pi/4 radians"},
+		{"addendum", `{"teeth": {"addendum": -1}}`, "teeth.addendum: must not be negative"},
+		{"dedendum", `{"teeth": {"dedendum": -1}}`, "teeth.dedendum: must not be negative"},
+		{"backlash", `{"teeth": {"backlash": 2}}`, "teeth.backlash: must be between 0 and pi/2 modules"},
+
+		{"star count", `{"starfield": {"count": -1, "depth": 1}}`, "starfield.count: must not be negative"},
+		{"star depth", `{"starfield": {"count": 1, "depth": 0}}`, "starfield.depth: must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.json))
+			if err == nil {
+				t.Fatalf("no error, want %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	_, err := Parse([]byte(`{"camera": {"zoom": -1, "near": -1}, "starfield": {"count": -1, "depth": 0}}`))
+	if err == nil {
+		t.Fatal("no error")
+	}
+	if lines := strings.Split(err.Error(), "\n"); len(lines) != 4 {
+		t.Errorf("got %d errors, want 4: %v", len(lines), err)
+	}
+}
+
+func TestValidScene(t *testing.T) {
+	_, err := Parse([]byte(`{
+		"camera": {"position": [0, 0, 5], "fov": 1, "near": 1, "far": 100},
+		"materials": {"m": {"ambient": [0.1, 0.1, 0.1], "diffuse": [0.5, 0.5, 0.5], "specular": [1, 1, 1], "shininess": 8}},
+		"lightRigs": [[{"type": "directional", "color": [1, 1, 1], "direction": [0, -1, 0]}]],
+		"meshes": [{"shape": "sphere", "radius": 1, "divisions": 8, "material": "m"}],
+		"carriers": [{"name": "arm"}],
+		"gears": [{"name": "sun", "teeth": 20, "radius": 1, "color": [1, 1, 1]}, {"name": "planet", "teeth": 10, "radius": 0.5, "color": [1, 1, 1], "carrier": "arm"}],
+		"ringGears": [{"name": "ring", "teeth": 40, "radius": 2, "thickness": 0.9, "color": [1, 1, 1]}],
+		"train": {"input": "sun", "speed": 0.01, "fixed": "ring", "meshes": [["sun", "planet"], ["planet", "ring"]]},
+		"starfield": {"count": 10, "speed": 1, "depth": 100}
+	}`))
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,180 @@
+// Package scenegraph arranges named nodes in a tree where each node's transform is relative
+// to its parent.
+package scenegraph
+
+import (
+	"errors"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+var ErrCycle = errors.New("scenegraph: a node can't be its own ancestor")
+
+// Drawable is anything a node can render, given the node's world transform
+type Drawable interface {
+	Draw(world mymath.Mat4)
+}
+
+// Node has a transform relative to its parent. World transforms are cached and only
+// recomputed after the node or one of its ancestors changes.
+type Node struct {
+	Name     string
+	Drawable Drawable // Optional
+
+	local    mymath.Mat4
+	world    mymath.Mat4
+	dirty    bool
+	parent   *Node
+	children []*Node
+}
+
+func NewNode(name string) *Node {
+	return &Node{Name: name, local: mymath.Ident4(), world: mymath.Ident4()}
+}
+
+func (n *Node) Local() mymath.Mat4 {
+	return n.local
+}
+
+func (n *Node) SetLocal(m mymath.Mat4) {
+	n.local = m
+	n.markDirty()
+}
+
+// markDirty flags n and everything below it. A dirty node's descendants are always dirty too,
+// so there is no need to go further down from one.
+func (n *Node) markDirty() {
+	if n.dirty {
+		return
+	}
+
+	n.dirty = true
+	for _, c := range n.children {
+		c.markDirty()
+	}
+}
+
+// World is the transform from this node's space to the space of the root's parent
+func (n *Node) World() mymath.Mat4 {
+	if !n.dirty {
+		return n.world
+	}
+
+	if n.parent == nil {
+		n.world = n.local
+	} else {
+		n.world = n.parent.World().Mul(n.local)
+	}
+	n.dirty = false
+	return n.world
+}
+
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+func (n *Node) Children() []*Node {
+	return n.children
+}
+
+// AddChild moves c under n, keeping its local transform. It fails if c is n or one of its
+// ancestors.
+func (n *Node) AddChild(c *Node) error {
+	for a := n; a != nil; a = a.parent {
+		if a == c {
+			return ErrCycle
+		}
+	}
+
+	c.Detach()
+	c.parent = n
+	n.children = append(n.children, c)
+	c.markDirty()
+	return nil
+}
+
+// Reparent moves n under parent, adjusting its local transform so it stays where it is in the
+// world. A nil parent makes n a root. It fails if parent is n or below it, or if the new
+// parent's world transform can't be inverted.
+func (n *Node) Reparent(parent *Node) error {
+	world := n.World()
+
+	if parent == nil {
+		n.Detach()
+		n.SetLocal(world)
+		return nil
+	}
+
+	inverse, ok := parent.World().Inverse()
+	if !ok {
+		return errors.New("scenegraph: new parent transform is not invertible")
+	}
+
+	if err := parent.AddChild(n); err != nil {
+		return err
+	}
+	n.SetLocal(inverse.Mul(world))
+	return nil
+}
+
+// Detach removes n from its parent, making it the root of its own tree
+func (n *Node) Detach() {
+	if n.parent == nil {
+		return
+	}
+
+	siblings := n.parent.children
+	for i, c := range siblings {
+		if c == n {
+			n.parent.children = append(siblings[:i:i], siblings[i+1:]...)
+			break
+		}
+	}
+
+	n.parent = nil
+	n.markDirty()
+}
+
+// Walk visits n and its descendants depth first, parents before children and children in the
+// order they were added. Returning false from visit skips that node's children.
+func (n *Node) Walk(visit func(n *Node) bool) {
+	if !visit(n) {
+		return
+	}
+
+	for _, c := range n.children {
+		c.Walk(visit)
+	}
+}
+
+// Find returns the first node named name at or below n, or nil
+func (n *Node) Find(name string) *Node {
+	var found *Node
+
+	n.Walk(func(c *Node) bool {
+		if found == nil && c.Name == name {
+			found = c
+		}
+		return found == nil
+	})
+
+	return found
+}
+
+// Path is the names from the root down to n, separated by slashes
+func (n *Node) Path() string {
+	if n.parent == nil {
+		return n.Name
+	}
+	return n.parent.Path() + "/" + n.Name
+}
+
+// Draw draws every drawable node at or below n in Walk order
+func (n *Node) Draw() {
+	n.Walk(func(c *Node) bool {
+		if c.Drawable != nil {
+			c.Drawable.Draw(c.World())
+		}
+		return true
+	})
+}
@@ -0,0 +1,259 @@
+package scenegraph
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// tree builds root with children a and b, and c under a
+func tree() (root, a, b, c *Node) {
+	root, a, b, c = NewNode("root"), NewNode("a"), NewNode("b"), NewNode("c")
+	root.AddChild(a)
+	root.AddChild(b)
+	a.AddChild(c)
+	return
+}
+
+func mat4Near(m, n mymath.Mat4) bool {
+	for row := range 4 {
+		for col := range 4 {
+			if d := m[row][col] - n[row][col]; d < -1e-9 || d > 1e-9 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestWorld(t *testing.T) {
+	root, a, b, c := tree()
+	root.SetLocal(mymath.Translate(1, 0, 0))
+	a.SetLocal(mymath.Scale(2, 2, 2))
+	c.SetLocal(mymath.Translate(0, 1, 0))
+
+	tests := []struct {
+		node *Node
+		want mymath.Vector3
+	}{
+		{root, mymath.Vector3{X: 1}},
+		{a, mymath.Vector3{X: 1}},
+		{b, mymath.Vector3{X: 1}},
+		{c, mymath.Vector3{X: 1, Y: 2}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.node.World().TransformPoint(mymath.Vector3{}); got != tt.want {
+			t.Errorf("%s: origin is at %v in the world, want %v", tt.node.Name, got, tt.want)
+		}
+	}
+}
+
+func TestWorldCached(t *testing.T) {
+	root, a, b, c := tree()
+	c.World()
+	b.World()
+	for _, n := range []*Node{root, a, b, c} {
+		if n.dirty {
+			t.Errorf("%s is dirty after World", n.Name)
+		}
+	}
+
+	// Changing the matrix behind SetLocal's back shows whether World recomputes
+	a.local = mymath.Translate(5, 0, 0)
+	if got := c.World(); got != mymath.Ident4() {
+		t.Errorf("World was recomputed without a change: %v", got)
+	}
+
+	a.SetLocal(mymath.Translate(5, 0, 0))
+	for _, tt := range []struct {
+		node  *Node
+		dirty bool
+	}{{root, false}, {a, true}, {b, false}, {c, true}} {
+		if tt.node.dirty != tt.dirty {
+			t.Errorf("%s: dirty is %v after changing a, want %v", tt.node.Name, tt.node.dirty, tt.dirty)
+		}
+	}
+	if got := c.World(); got != mymath.Translate(5, 0, 0) {
+		t.Errorf("c is at %v after moving a", got)
+	}
+}
+
+func TestWorldAfterMoving(t *testing.T) {
+	root, a, b, c := tree()
+	a.SetLocal(mymath.Translate(1, 0, 0))
+	b.SetLocal(mymath.Translate(0, 1, 0))
+	c.SetLocal(mymath.Translate(0, 0, 1))
+	c.World()
+
+	// AddChild keeps the local transform, so c moves with its new parent
+	if err := b.AddChild(c); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.World(); got != mymath.Translate(0, 1, 1) {
+		t.Errorf("c is at %v under b", got)
+	}
+
+	// Reparent keeps the world transform instead
+	if err := c.Reparent(a); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.World(); !mat4Near(got, mymath.Translate(0, 1, 1)) {
+		t.Errorf("c moved to %v when reparented", got)
+	}
+	if got := c.Local(); !mat4Near(got, mymath.Translate(-1, 1, 1)) {
+		t.Errorf("c has local transform %v under a", got)
+	}
+
+	// Moving the new parent moves c
+	a.SetLocal(mymath.Ident4())
+	if got := c.World(); !mat4Near(got, mymath.Translate(-1, 1, 1)) {
+		t.Errorf("c is at %v after moving a", got)
+	}
+
+	if err := c.Reparent(nil); err != nil {
+		t.Fatal(err)
+	}
+	if c.Parent() != nil || slices.Contains(a.Children(), c) {
+		t.Error("c still has a parent")
+	}
+	if got := c.World(); !mat4Near(got, mymath.Translate(-1, 1, 1)) {
+		t.Errorf("c moved to %v as a root", got)
+	}
+
+	root.SetLocal(mymath.Translate(9, 9, 9))
+	if got := c.World(); !mat4Near(got, mymath.Translate(-1, 1, 1)) {
+		t.Errorf("c followed its old root to %v", got)
+	}
+}
+
+func TestCycles(t *testing.T) {
+	root, a, _, c := tree()
+
+	tests := []struct {
+		name   string
+		parent *Node
+		child  *Node
+	}{
+		{"itself", a, a},
+		{"its parent", c, a},
+		{"the root", c, root},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.parent.AddChild(tt.child); !errors.Is(err, ErrCycle) {
+				t.Errorf("AddChild returned %v", err)
+			}
+			if err := tt.child.Reparent(tt.parent); !errors.Is(err, ErrCycle) {
+				t.Errorf("Reparent returned %v", err)
+			}
+			if got := c.Path(); got != "root/a/c" {
+				t.Errorf("the tree changed: c is at %s", got)
+			}
+		})
+	}
+}
+
+func TestReparentSingular(t *testing.T) {
+	_, a, b, c := tree()
+	b.SetLocal(mymath.Scale(1, 0, 1))
+
+	if err := c.Reparent(b); err == nil {
+		t.Error("Reparent under a flattened parent succeeded")
+	}
+	if c.Parent() != a {
+		t.Errorf("c moved to %v", c.Parent().Name)
+	}
+}
+
+func TestDetach(t *testing.T) {
+	root, a, b, _ := tree()
+	a.Detach()
+
+	if a.Parent() != nil {
+		t.Error("a still has a parent")
+	}
+	if got := root.Children(); len(got) != 1 || got[0] != b {
+		t.Errorf("root has children %v", got)
+	}
+
+	// Detaching a root does nothing
+	root.Detach()
+	if len(root.Children()) != 1 {
+		t.Error("detaching the root changed its children")
+	}
+}
+
+// recorder remembers the order nodes are drawn in
+type recorder struct {
+	name  string
+	drawn *[]string
+}
+
+func (r recorder) Draw(world mymath.Mat4) {
+	*r.drawn = append(*r.drawn, r.name)
+}
+
+func TestWalk(t *testing.T) {
+	root, a, b, c := tree()
+	d := NewNode("d")
+	b.AddChild(d)
+
+	var visited []string
+	root.Walk(func(n *Node) bool {
+		visited = append(visited, n.Name)
+		return true
+	})
+	if want := []string{"root", "a", "c", "b", "d"}; !slices.Equal(visited, want) {
+		t.Errorf("visited %v, want %v", visited, want)
+	}
+
+	visited = nil
+	root.Walk(func(n *Node) bool {
+		visited = append(visited, n.Name)
+		return n != a
+	})
+	if want := []string{"root", "a", "b", "d"}; !slices.Equal(visited, want) {
+		t.Errorf("skipping a's children visited %v, want %v", visited, want)
+	}
+
+	var drawn []string
+	for _, n := range []*Node{root, c, d} {
+		n.Drawable = recorder{n.Name, &drawn}
+	}
+	root.Draw()
+	if want := []string{"root", "c", "d"}; !slices.Equal(drawn, want) {
+		t.Errorf("drew %v, want %v", drawn, want)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root, a, _, c := tree()
+	twin := NewNode("c")
+	root.AddChild(twin)
+
+	tests := []struct {
+		from *Node
+		name string
+		want *Node
+	}{
+		{root, "root", root},
+		{root, "c", c},
+		{a, "c", c},
+		{c, "a", nil},
+		{root, "missing", nil},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.Find(tt.name); got != tt.want {
+			t.Errorf("%s.Find(%q) = %v, want %v", tt.from.Name, tt.name, got, tt.want)
+		}
+	}
+
+	if got := c.Path(); got != "root/a/c" {
+		t.Errorf("path %q", got)
+	}
+}
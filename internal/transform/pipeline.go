@@ -0,0 +1,81 @@
+package transform
+
+import (
+	"math"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+// Pipeline carries points from model space to the screen: model stack, then View, Projection
+// and finally Viewport
+type Pipeline struct {
+	*Stack
+	View       mymath.Mat4
+	Projection mymath.Mat4
+	Viewport   mymath.Mat4
+}
+
+func NewPipeline(width, height float64) *Pipeline {
+	return &Pipeline{
+		Stack:      NewStack(),
+		View:       mymath.Ident4(),
+		Projection: mymath.Ident4(),
+		Viewport:   Viewport(width, height),
+	}
+}
+
+// Viewport maps NDC, -1 to 1 with y going up, to a width x height screen with 0,0 at the top
+// left and y going down
+func Viewport(width, height float64) mymath.Mat4 {
+	return mymath.Mat4{
+		{width / 2, 0, 0, width / 2},
+		{0, -height / 2, 0, height / 2},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Projected is a point at each stage after the projection matrix
+type Projected struct {
+	Clip   mymath.Vector4
+	NDC    mymath.Vector3
+	Screen mymath.Vector2
+
+	// Visible is true inside the clip volume -|w| <= x,y,z <= |w|. Using |w| accepts
+	// projections that make w negative in front of the camera as well as positive ones.
+	Visible bool
+}
+
+func (p *Pipeline) Project(point mymath.Vector3) Projected {
+	clip := p.Projection.Mul(p.View).Mul(p.Top()).MulVector4(point.Vec4(1))
+
+	w := math.Abs(clip.W)
+	visible := w > 0 && math.Abs(clip.X) <= w && math.Abs(clip.Y) <= w && math.Abs(clip.Z) <= w
+
+	ndc := clip.Vec3()
+	if clip.W != 0 {
+		ndc = ndc.Multiply(1 / clip.W)
+	}
+
+	return Projected{
+		Clip:    clip,
+		NDC:     ndc,
+		Screen:  p.Viewport.TransformPoint(ndc).Vec2(),
+		Visible: visible,
+	}
+}
+
+// Unproject maps a screen position and NDC depth back to world space, ignoring the model
+// stack. It fails when the view and projection can't be inverted.
+func (p *Pipeline) Unproject(screen mymath.Vector2, depth float64) (mymath.Vector3, bool) {
+	inverse, ok := p.Viewport.Mul(p.Projection).Mul(p.View).Inverse()
+	if !ok {
+		return mymath.Vector3{}, false
+	}
+
+	world := inverse.MulVector4(mymath.Vector4{X: screen.X, Y: screen.Y, Z: depth, W: 1})
+	if world.W == 0 {
+		return mymath.Vector3{}, false
+	}
+	return world.Vec3().Multiply(1 / world.W), true
+}
@@ -0,0 +1,133 @@
+package transform
+
+import (
+	"math"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+func vector3Near(a, b mymath.Vector3) bool {
+	return a.Subtract(b).Magnitude() < 1e-9
+}
+
+// camera looks at the origin from 10 units along z, through a 90 degree perspective
+func camera() *Pipeline {
+	p := NewPipeline(640, 480)
+	p.View = mymath.LookAt(mymath.Vector3{X: 1, Y: 2, Z: 10}, mymath.Vector3{}, mymath.Vector3{Y: 1})
+	p.Projection = mymath.Perspective(math.Pi/2, 640.0/480, 1, 100)
+	return p
+}
+
+func TestViewport(t *testing.T) {
+	p := NewPipeline(640, 480)
+
+	tests := []struct {
+		ndc  mymath.Vector3
+		want mymath.Vector2
+	}{
+		{mymath.Vector3{X: -1, Y: 1}, mymath.Vector2{X: 0, Y: 0}},
+		{mymath.Vector3{X: 1, Y: -1}, mymath.Vector2{X: 640, Y: 480}},
+		{mymath.Vector3{}, mymath.Vector2{X: 320, Y: 240}},
+	}
+
+	for _, tt := range tests {
+		if got := p.Project(tt.ndc).Screen; got != tt.want {
+			t.Errorf("NDC %v is at %v on screen, want %v", tt.ndc, got, tt.want)
+		}
+	}
+}
+
+func TestProjectUnproject(t *testing.T) {
+	points := []mymath.Vector3{
+		{},
+		{X: 1, Y: 2, Z: 3},
+		{X: -4, Y: 1, Z: -20},
+		{X: 0.5, Y: -3, Z: 8.5},
+	}
+
+	for _, pipeline := range []*Pipeline{camera(), NewPipeline(640, 480)} {
+		for _, p := range points {
+			projected := pipeline.Project(p)
+			got, ok := pipeline.Unproject(projected.Screen, projected.NDC.Z)
+			if !ok {
+				t.Fatalf("Unproject of %v failed", p)
+			}
+			if !vector3Near(got, p) {
+				t.Errorf("%v came back as %v", p, got)
+			}
+		}
+	}
+}
+
+func TestUnprojectIgnoresModelStack(t *testing.T) {
+	p := camera()
+	p.Translate(5, 0, 0)
+
+	projected := p.Project(mymath.Vector3{})
+	got, _ := p.Unproject(projected.Screen, projected.NDC.Z)
+	if want := (mymath.Vector3{X: 5}); !vector3Near(got, want) {
+		t.Errorf("got %v, want the world position %v", got, want)
+	}
+}
+
+func TestUnprojectSingular(t *testing.T) {
+	p := NewPipeline(640, 480)
+	p.Projection = mymath.Scale(1, 1, 0)
+	if _, ok := p.Unproject(mymath.Vector2{X: 10, Y: 10}, 0); ok {
+		t.Error("Unproject succeeded through a flat projection")
+	}
+}
+
+func TestVisible(t *testing.T) {
+	eye := mymath.Vector3{X: 1, Y: 2, Z: 10}
+
+	tests := []struct {
+		name  string
+		point mymath.Vector3
+		want  bool
+	}{
+		{"looked at", mymath.Vector3{}, true},
+		{"inside near plane", eye.Add(mymath.Vector3{Z: -0.5}), false},
+		{"past far plane", mymath.Vector3{Z: -200}, false},
+		{"off to the side", mymath.Vector3{X: 100}, false},
+		{"behind the eye", eye.Add(mymath.Vector3{Z: 5}), false},
+		{"behind the eye off centre", eye.Add(mymath.Vector3{X: 1, Y: 1, Z: 20}), false},
+		{"at the eye", eye, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := camera().Project(tt.point)
+			if got.Visible != tt.want {
+				t.Errorf("Visible is %v with clip %v", got.Visible, got.Clip)
+			}
+		})
+	}
+}
+
+func TestVisibleNegativeW(t *testing.T) {
+	// A projection that makes w negative in front of the camera still shows what it faces
+	negate := mymath.Scale(-1, -1, -1)
+	negate[3][3] = -1
+	p := NewPipeline(640, 480)
+	p.Projection = negate.Mul(mymath.Perspective(math.Pi/2, 1, 1, 100))
+
+	tests := []struct {
+		point mymath.Vector3
+		want  bool
+	}{
+		{mymath.Vector3{Z: -10}, true},
+		{mymath.Vector3{Z: 10}, false},
+	}
+
+	for _, tt := range tests {
+		got := p.Project(tt.point)
+		if got.Clip.W >= 0 && tt.want {
+			t.Fatalf("w is %v, want it negative", got.Clip.W)
+		}
+		if got.Visible != tt.want {
+			t.Errorf("%v: Visible is %v with clip %v", tt.point, got.Visible, got.Clip)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package transform keeps an OpenGL style model matrix stack and carries points through the
+// model, view, projection and viewport transforms to the screen.
+package transform
+
+import (
+	"errors"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+var ErrStackUnderflow = errors.New("transform: pop without a matching push")
+
+// Stack is a stack of model matrices. Transforms multiply onto the top matrix from the right,
+// so the last transform applied is the first one a point goes through.
+type Stack struct {
+	matrices []mymath.Mat4
+	err      error
+}
+
+// NewStack starts with a single identity matrix, which can never be popped
+func NewStack() *Stack {
+	return &Stack{matrices: []mymath.Mat4{mymath.Ident4()}}
+}
+
+func (s *Stack) Top() mymath.Mat4 {
+	return s.matrices[len(s.matrices)-1]
+}
+
+// Depth is the number of matrices on the stack, 1 when nothing has been pushed
+func (s *Stack) Depth() int {
+	return len(s.matrices)
+}
+
+// Push saves a copy of the top matrix
+func (s *Stack) Push() {
+	s.matrices = append(s.matrices, s.Top())
+}
+
+// Pop restores the matrix saved by the matching Push. Popping the base matrix leaves the stack
+// unchanged and returns ErrStackUnderflow, which is also kept for Err.
+func (s *Stack) Pop() error {
+	if len(s.matrices) == 1 {
+		s.err = ErrStackUnderflow
+		return s.err
+	}
+
+	s.matrices = s.matrices[:len(s.matrices)-1]
+	return nil
+}
+
+// Err returns the first underflow since the stack was last reset, for callers that check once
+// per frame rather than after every Pop
+func (s *Stack) Err() error {
+	return s.err
+}
+
+// Reset empties the stack back to a single identity matrix and clears Err
+func (s *Stack) Reset() {
+	s.matrices = s.matrices[:1]
+	s.matrices[0] = mymath.Ident4()
+	s.err = nil
+}
+
+func (s *Stack) LoadIdentity() {
+	s.LoadMatrix(mymath.Ident4())
+}
+
+// LoadMatrix replaces the top matrix
+func (s *Stack) LoadMatrix(m mymath.Mat4) {
+	s.matrices[len(s.matrices)-1] = m
+}
+
+// MultMatrix applies m before the transforms already on top of the stack
+func (s *Stack) MultMatrix(m mymath.Mat4) {
+	s.matrices[len(s.matrices)-1] = s.Top().Mul(m)
+}
+
+func (s *Stack) Translate(x, y, z float64) {
+	s.MultMatrix(mymath.Translate(x, y, z))
+}
+
+func (s *Stack) Scale(x, y, z float64) {
+	s.MultMatrix(mymath.Scale(x, y, z))
+}
+
+// Shear takes the same factors as mymath.Shear
+func (s *Stack) Shear(xy, xz, yx, yz, zx, zy float64) {
+	s.MultMatrix(mymath.Shear(xy, xz, yx, yz, zx, zy))
+}
+
+func (s *Stack) RotateX(angle float64) {
+	s.MultMatrix(mymath.RotateX(angle))
+}
+
+func (s *Stack) RotateY(angle float64) {
+	s.MultMatrix(mymath.RotateY(angle))
+}
+
+func (s *Stack) RotateZ(angle float64) {
+	s.MultMatrix(mymath.RotateZ(angle))
+}
+
+func (s *Stack) Rotate(axis mymath.Vector3, angle float64) {
+	s.MultMatrix(mymath.Rotate(axis, angle))
+}
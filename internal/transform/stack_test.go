@@ -0,0 +1,94 @@
+package transform
+
+import (
+	"errors"
+	"testing"
+
+	mymath "github.com/insood/graphics/internal/math"
+)
+
+func TestPop(t *testing.T) {
+	s := NewStack()
+	s.Translate(1, 2, 3)
+	base := s.Top()
+
+	s.Push()
+	s.Scale(2, 2, 2)
+	if s.Depth() != 2 {
+		t.Fatalf("depth %d after Push", s.Depth())
+	}
+	if err := s.Pop(); err != nil {
+		t.Fatalf("Pop: %v", err)
+	}
+	if s.Top() != base {
+		t.Errorf("Pop left %v, want %v", s.Top(), base)
+	}
+	if s.Err() != nil {
+		t.Errorf("Err is %v after a matched Pop", s.Err())
+	}
+
+	// Popping the base matrix fails and leaves it alone
+	if err := s.Pop(); !errors.Is(err, ErrStackUnderflow) {
+		t.Errorf("Pop of the base matrix returned %v", err)
+	}
+	if s.Depth() != 1 || s.Top() != base {
+		t.Errorf("underflow changed the stack: depth %d, top %v", s.Depth(), s.Top())
+	}
+
+	// The underflow is kept through later pushes and pops
+	s.Push()
+	if err := s.Pop(); err != nil {
+		t.Errorf("Pop after an underflow: %v", err)
+	}
+	if !errors.Is(s.Err(), ErrStackUnderflow) {
+		t.Errorf("Err is %v, want ErrStackUnderflow", s.Err())
+	}
+}
+
+func TestReset(t *testing.T) {
+	s := NewStack()
+	s.Translate(1, 2, 3)
+	s.Push()
+	s.Push()
+	s.RotateZ(1)
+	s.Pop()
+	s.Pop()
+	s.Pop()
+
+	s.Reset()
+	if s.Depth() != 1 {
+		t.Errorf("depth %d after Reset", s.Depth())
+	}
+	if s.Top() != mymath.Ident4() {
+		t.Errorf("top is %v after Reset", s.Top())
+	}
+	if s.Err() != nil {
+		t.Errorf("Err is %v after Reset", s.Err())
+	}
+}
+
+func TestTransformOrder(t *testing.T) {
+	// The last transform applied is the first one a point goes through
+	tests := []struct {
+		name  string
+		apply func(s *Stack)
+		want  mymath.Vector3
+	}{
+		{"translate then scale", func(s *Stack) { s.Translate(1, 0, 0); s.Scale(2, 2, 2) }, mymath.Vector3{X: 3, Y: 2, Z: 2}},
+		{"scale then translate", func(s *Stack) { s.Scale(2, 2, 2); s.Translate(1, 0, 0) }, mymath.Vector3{X: 4, Y: 2, Z: 2}},
+		{"load replaces", func(s *Stack) { s.Scale(2, 2, 2); s.LoadMatrix(mymath.Translate(0, 1, 0)) }, mymath.Vector3{X: 1, Y: 2, Z: 1}},
+		{"load identity", func(s *Stack) { s.Scale(2, 2, 2); s.LoadIdentity() }, mymath.Vector3{X: 1, Y: 1, Z: 1}},
+		{"mult matrix", func(s *Stack) { s.MultMatrix(mymath.Scale(1, 3, 1)) }, mymath.Vector3{X: 1, Y: 3, Z: 1}},
+		{"shear", func(s *Stack) { s.Shear(1, 0, 0, 0, 0, 0) }, mymath.Vector3{X: 2, Y: 1, Z: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStack()
+			tt.apply(s)
+			if got := s.Top().TransformPoint(mymath.Vector3{X: 1, Y: 1, Z: 1}); !vector3Near(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}